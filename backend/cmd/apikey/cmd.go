@@ -0,0 +1,173 @@
+package apikey
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database/postgres"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/auth"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/config"
+)
+
+// listPageSize is the quantity of keys loaded from the database at once by "apikey list"
+const listPageSize = 100
+
+// APIKeyCMD groups the commands to manage the API keys used by non-interactive clients
+var APIKeyCMD = &cobra.Command{
+	Use:   "apikey",
+	Short: "Manages API keys",
+}
+
+// CreateCMD creates an API key and prints it. The key is shown only once
+var CreateCMD = &cobra.Command{
+	Use:   "create",
+	Short: "Creates an API key and prints it",
+	Run: func(cmd *cobra.Command, args []string) {
+		name, err := cmd.Flags().GetString("name")
+		if err != nil {
+			panic(err)
+		}
+		permissions, err := cmd.Flags().GetStringSlice("permissions")
+		if err != nil {
+			panic(err)
+		}
+		expiresIn, err := cmd.Flags().GetDuration("expires-in")
+		if err != nil {
+			panic(err)
+		}
+		authorFlag, err := cmd.Flags().GetString("author-id")
+		if err != nil {
+			panic(err)
+		}
+
+		newKey := auth.NewAPIKey{Name: name}
+		for _, permission := range permissions {
+			newKey.Permissions = append(newKey.Permissions, auth.Permission(strings.TrimSpace(permission)))
+		}
+		if expiresIn > 0 {
+			expiresAt := time.Now().UTC().Add(expiresIn)
+			newKey.ExpiresAt = &expiresAt
+		}
+		if authorFlag != "" {
+			authorID, err := uuid.Parse(authorFlag)
+			if err != nil {
+				panic(fmt.Errorf("invalid author id: %w", err))
+			}
+			newKey.AuthorID = &authorID
+		}
+
+		withService(cmd, func(svc auth.APIKeyService) error {
+			created, err := svc.Create(cmd.Context(), newKey, nil)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("API key %s created. Store it now, it won't be shown again:\n%s\n", created.ID, created.Key)
+			return nil
+		})
+	},
+}
+
+// RevokeCMD disables an API key immediately
+var RevokeCMD = &cobra.Command{
+	Use:   "revoke <id>",
+	Short: "Revokes an API key",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := uuid.Parse(args[0])
+		if err != nil {
+			panic(fmt.Errorf("invalid API key id: %w", err))
+		}
+
+		withService(cmd, func(svc auth.APIKeyService) error {
+			if err := svc.Revoke(cmd.Context(), id); err != nil {
+				return err
+			}
+
+			fmt.Printf("API key %s revoked\n", id)
+			return nil
+		})
+	},
+}
+
+// ListCMD prints all API keys, without their secret values
+var ListCMD = &cobra.Command{
+	Use:   "list",
+	Short: "Lists API keys",
+	Run: func(cmd *cobra.Command, args []string) {
+		withService(cmd, func(svc auth.APIKeyService) error {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tNAME\tPREFIX\tPERMISSIONS\tEXPIRES\tLAST USED\tREVOKED")
+
+			page := database.Page{Limit: listPageSize}
+			for {
+				keys, err := svc.GetAllPaginated(cmd.Context(), page)
+				if err != nil {
+					return err
+				}
+				for _, key := range *keys {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", key.ID, *key.Name, *key.Prefix,
+						strings.Join(*key.Permissions, ","), formatTime(key.ExpiresAt),
+						formatTime(key.LastUsedAt), formatTime(key.RevokedAt))
+				}
+				if len(*keys) < listPageSize {
+					break
+				}
+				page.Offset += listPageSize
+			}
+
+			return w.Flush()
+		})
+	},
+}
+
+// withService loads the environment, connects to the database and runs fn with the API key service
+func withService(cmd *cobra.Command, fn func(svc auth.APIKeyService) error) {
+	ctx := cmd.Context()
+
+	// get configuration flag params - panic if any error
+	source, err := config.SourceFromFlags(cmd.Flags())
+	if err != nil {
+		panic(err)
+	}
+
+	// get environment configuration - panic if any error
+	envconfig, err := config.Load(source)
+	if err != nil {
+		panic(err)
+	}
+
+	// configure logger (Zap Logger) - panic if any error
+	zaplog, err := zaplog.NewCustomZap(envconfig.LoggerConfig())
+	if err != nil {
+		panic(fmt.Errorf("failed to configure zaplog logger: %s", err))
+	}
+
+	// connect to postgreSQL - panic if any error
+	db, err := postgres.Connect(ctx, envconfig.Database)
+	if err != nil {
+		panic(fmt.Errorf("failed to connect to database: %s", err))
+	}
+	defer zaplog.SafeClose(db, "Unable to close database connection")
+
+	if err := fn(auth.NewAPIKeyService(zaplog, auth.NewAPIKeyRepository(db))); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		// deferred calls don't run on os.Exit
+		zaplog.SafeClose(db, "Unable to close database connection")
+		os.Exit(1)
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/environment"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/config"
+)
+
+// ConfigCMD groups the commands inspecting the configuration
+var ConfigCMD = &cobra.Command{
+	Use:   "config",
+	Short: "Inspects the environment configuration",
+}
+
+// PrintCMD prints the effective configuration after merging every layer, with secrets redacted
+var PrintCMD = &cobra.Command{
+	Use:   "print",
+	Short: "Prints the effective configuration with secrets redacted",
+	Run: func(cmd *cobra.Command, args []string) {
+		// get flag params - panic if any error
+		source, err := config.SourceFromFlags(cmd.Flags())
+		if err != nil {
+			panic(err)
+		}
+		if source.Environment == "" {
+			exitWithError(errors.New(`required flag(s) "environment" not set`))
+		}
+
+		// the configuration is printed even when invalid, so the problem can be found
+		envconfig, err := config.Read(source)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := environment.WriteYAML(cmd.OutOrStdout(), envconfig); err != nil {
+			exitWithError(err)
+		}
+		if err := environment.Validate(envconfig); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+// ValidateCMD validates the configuration of one environment, or of all of them with --all. With --structure,
+// the references to missing environment variables and files are tolerated, so the files can be checked
+// where their secrets aren't available, e.g. in CI
+var ValidateCMD = &cobra.Command{
+	Use:   "validate",
+	Short: "Validates the configuration of the environments",
+	Run: func(cmd *cobra.Command, args []string) {
+		// get flag params - panic if any error
+		source, err := config.SourceFromFlags(cmd.Flags())
+		if err != nil {
+			panic(err)
+		}
+		allFlag, err := cmd.Flags().GetBool("all")
+		if err != nil {
+			panic(err)
+		}
+		structureFlag, err := cmd.Flags().GetBool("structure")
+		if err != nil {
+			panic(err)
+		}
+
+		environments := []string{source.Environment}
+		if allFlag {
+			if environments, err = environment.Names(config.Path(source)); err != nil {
+				exitWithError(err)
+			}
+		} else if source.Environment == "" {
+			exitWithError(errors.New(`required flag(s) "environment" or "all" not set`))
+		}
+
+		failed := false
+		for _, name := range environments {
+			source.Environment = name
+
+			var unresolved []string
+			if structureFlag {
+				unresolved, err = config.CheckStructure(source)
+			} else {
+				_, err = config.Load(source)
+			}
+			if err != nil {
+				failed = true
+				fmt.Fprintf(cmd.ErrOrStderr(), "%s: %s\n", name, describe(err))
+				continue
+			}
+
+			if len(unresolved) > 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s: valid, not checked without their references: %s\n", name,
+					strings.Join(unresolved, ", "))
+				continue
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s: valid\n", name)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// describe lists one validation error per line
+func describe(err error) string {
+	var errs environment.ValidationErrors
+	if !errors.As(err, &errs) {
+		return err.Error()
+	}
+
+	description := "invalid configuration"
+	for _, fieldErr := range errs {
+		description += "\n  - " + fieldErr.Error()
+	}
+	return description
+}
+
+func exitWithError(err error) {
+	fmt.Fprintln(os.Stderr, "Error:", describe(err))
+	os.Exit(1)
+}
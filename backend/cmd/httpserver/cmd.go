@@ -1,32 +1,16 @@
 package httpserver
 
 import (
+	"context"
 	"fmt"
-	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/environment"
-	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database/postgres"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/lifecycle"
 	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/config"
 
 	"github.com/spf13/cobra"
 )
 
-// Configuration contains the data structure for the environment configuration.
-type Configuration struct {
-	AppName string
-
-	EnvironmentName string
-
-	LogLevel string
-
-	HealthCheckEndpoint string
-
-	Server struct {
-		HTTP struct {
-			Network    string
-			ListenAddr string
-		}
-	}
-}
-
 // HTTPServerCMD configures an HTTP Server with all dependencies necessary (connections, cache, ...)
 var HTTPServerCMD = &cobra.Command{
 	Use:   "httpserver",
@@ -34,33 +18,44 @@ var HTTPServerCMD = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := cmd.Context()
 
-		// get environment flag param - panic if any error
-		envFlag, err := cmd.Flags().GetString("environment")
+		// get configuration flag params - panic if any error
+		source, err := config.SourceFromFlags(cmd.Flags())
 		if err != nil {
 			panic(err)
 		}
 
 		// get environment configuration - panic if any error
-		var envconfig = &Configuration{}
-		err = environment.NewFromYAML("configs/environment", envFlag, envconfig)
+		envconfig, err := config.Load(source)
 		if err != nil {
-			panic(fmt.Errorf("failed to load environment config: %s", err))
+			panic(err)
 		}
 
-		// forces EnvironmentName to be always equal to flag received
-		envconfig.EnvironmentName = envFlag
-
-		// configure logger (Zap Logger) - panic if any error
-		zaplog, err := zaplog.NewCustomZap(logger.Config{
-			LogLevel:   envconfig.LogLevel,
-			AppName:    envconfig.AppName,
-			Production: envFlag == "production",
-		})
+		// configure logger (Zap Logger) with a level that can be changed at runtime - panic if any error
+		level, err := zaplog.NewLevel(envconfig.LogLevel)
+		if err != nil {
+			panic(err)
+		}
+		zaplog, err := zaplog.NewCustomZapWithLevel(envconfig.LoggerConfig(), level)
 		if err != nil {
 			panic(fmt.Errorf("failed to configure zaplog logger: %s", err))
 		}
 
+		// components are started in the order they are registered and stopped in the reverse order
+		lc := newLifecycle(zaplog, envconfig.Lifecycle)
+
+		// connect to postgreSQL - panic if any error
+		db, err := postgres.Connect(ctx, envconfig.Database)
+		if err != nil {
+			panic(fmt.Errorf("failed to connect to database: %s", err))
+		}
+		lc.Append(lifecycle.Hook{
+			Name: "database",
+			Stop: func(context.Context) error {
+				return db.Close()
+			},
+		})
+
 		// execute HTTP Server
-		RunHTTPServer(ctx, zaplog, envconfig)
+		RunHTTPServer(ctx, lc, zaplog, level, source, envconfig, db)
 	},
 }
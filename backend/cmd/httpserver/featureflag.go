@@ -0,0 +1,21 @@
+package httpserver
+
+import (
+	"net/http"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/auth"
+)
+
+// featureFlagSubject identifies the caller for percentage rollouts and allowlists by its user id, or by
+// its API key. Anonymous requests have no subject
+func featureFlagSubject(r *http.Request) string {
+	principal := auth.PrincipalFromContext(r.Context())
+	switch {
+	case principal == nil:
+		return ""
+	case principal.APIKeyID != nil:
+		return "apikey:" + principal.APIKeyID.String()
+	default:
+		return principal.UserID.String()
+	}
+}
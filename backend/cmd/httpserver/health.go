@@ -0,0 +1,19 @@
+package httpserver
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database/postgres"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/featureflag"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/health"
+	"github.com/thiagoretondar/golang-blog-example/backend/migrations"
+)
+
+// registerHealthChecks registers the readiness checks of the dependencies of the server
+func registerHealthChecks(registry *health.Registry, db *sqlx.DB, flags *featureflag.Flags) {
+	registry.AddReadiness(health.Check{Name: "database", Check: postgres.PingCheck(db)})
+	// the server isn't ready until the schema has every migration it was built with
+	registry.AddReadiness(health.Check{Name: "migrations", Check: postgres.SchemaVersionCheck(db, migrations.Version())})
+
+	// the last overrides stay active when the refresh fails, so the server can still handle requests
+	registry.AddReadiness(health.Check{Name: "featureflags", Check: flags.Check, Optional: true})
+}
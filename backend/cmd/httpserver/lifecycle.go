@@ -0,0 +1,81 @@
+package httpserver
+
+import (
+	"context"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/lifecycle"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/config"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go.uber.org/zap"
+)
+
+// newLifecycle creates the manager of the components of the server. The logger is registered first, so it's
+// flushed once every other component stopped
+func newLifecycle(logger zaplog.Logger, config lifecycle.Config) *lifecycle.Manager {
+	lc := lifecycle.New(zaplog.Adapt(logger), config)
+	lc.Append(lifecycle.Hook{
+		Name: "logger",
+		Stop: func(context.Context) error {
+			// stdout and stderr can't be synced on every platform, so the error is ignored
+			_ = logger.Sync()
+			return nil
+		},
+	})
+
+	return lc
+}
+
+// serverHook listens when started and serves until stopped. The manager is notified when serving fails, so
+// the whole application stops
+func serverHook(lc *lifecycle.Manager, name string, server *http.Server, network string, logger zaplog.Logger) lifecycle.Hook {
+	return lifecycle.Hook{
+		Name: name,
+		Start: func(ctx context.Context) error {
+			listener, err := net.Listen(network, server.Addr)
+			if err != nil {
+				return err
+			}
+
+			logger.Info(name+" is ready to handle request",
+				zap.String("network", network),
+				zap.String("listenAddr", server.Addr))
+			go func() {
+				if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+					lc.Fail(name, err)
+				}
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			server.SetKeepAlivesEnabled(false)
+			if err := server.Shutdown(ctx); err != nil {
+				return err
+			}
+
+			logger.Warn(name + " stopped")
+			return nil
+		},
+	}
+}
+
+// reloadConfig reloads the configuration on every SIGHUP until the context is done. Invalid configurations
+// are logged by the watcher and ignored
+func reloadConfig(ctx context.Context, watcher *config.Watcher) {
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-reload:
+			_ = watcher.Reload()
+		}
+	}
+}
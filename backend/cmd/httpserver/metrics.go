@@ -0,0 +1,39 @@
+package httpserver
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database/postgres"
+)
+
+// newMetricsRegistry creates the registry served on /metrics, with the collectors of the Go runtime, the process
+// and the database. The HTTP collectors are registered by the router
+func newMetricsRegistry(appName string, db *sqlx.DB) (*prometheus.Registry, error) {
+	metrics := prometheus.NewRegistry()
+	if err := metrics.Register(collectors.NewGoCollector()); err != nil {
+		return nil, err
+	}
+	if err := metrics.Register(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{})); err != nil {
+		return nil, err
+	}
+	if err := postgres.RegisterMetrics(metrics, db, appName); err != nil {
+		return nil, err
+	}
+
+	return metrics, nil
+}
+
+// newAdminHandler creates the handler of the admin listener, which isn't exposed with the public API
+func newAdminHandler(metrics *prometheus.Registry) http.Handler {
+	r := chi.NewRouter()
+	r.Use(middleware.Recoverer)
+	r.Method(http.MethodGet, "/metrics", promhttp.HandlerFor(metrics, promhttp.HandlerOpts{Registry: metrics}))
+
+	return r
+}
@@ -0,0 +1,25 @@
+package httpserver
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/httphandler/request"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/ratelimit"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/auth"
+)
+
+// rateLimitKey identifies the client of a request by its API key, its user or, for anonymous
+// requests, its IP address
+func rateLimitKey(trustedProxies []*net.IPNet) ratelimit.KeyFunc {
+	return func(r *http.Request) string {
+		if principal := auth.PrincipalFromContext(r.Context()); principal != nil {
+			if principal.APIKeyID != nil {
+				return "apikey:" + principal.APIKeyID.String()
+			}
+			return "user:" + principal.UserID.String()
+		}
+
+		return "ip:" + request.ClientIP(r, trustedProxies)
+	}
+}
@@ -1,34 +1,128 @@
 package httpserver
 
 import (
+	"context"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database/postgres"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/featureflag"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/health"
+	legomiddleware "github.com/thiagoretondar/golang-blog-example/backend/go-lego/httphandler/middleware"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/httphandler/request"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/lifecycle"
 	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/ratelimit"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/tracing"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/admin"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/auth"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/author"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/comment"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/config"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/feed"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/post"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/sitemap"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/tag"
 	"net/http"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // newRouter creates the main HTTP router for this application and some middlewares
-func newRouterHandler(envconfig *Configuration, logger zaplog.Logger) http.Handler {
-	r := chi.NewRouter()
+func newRouterHandler(lc *lifecycle.Manager, watcher *config.Watcher, logger zaplog.Logger, level *zaplog.Level, db *sqlx.DB,
+	registry *health.Registry, metrics prometheus.Registerer) (http.Handler, error) {
+	envconfig := watcher.Current()
+	httpMetrics, err := legomiddleware.Metrics(metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	// probes are answered before any middleware, so they are never authenticated, logged, traced, measured
+	// or limited, and stay cheap when polled every few seconds
+	root := chi.NewRouter()
+	root.Get("/livez", registry.LivenessHandler())
+	root.Get("/readyz", registry.ReadinessHandler())
+	root.Get(envconfig.HealthCheckEndpoint, registry.LivenessHandler())
 
-	// configure middlewares
+	// configure middlewares, the metrics are observed before Recoverer so panics are counted as 500
+	r := chi.NewRouter()
 	r.Use(middleware.RequestID)
+	r.Use(legomiddleware.Tracing)
+	r.Use(legomiddleware.AccessLog(zaplog.Adapt(logger), envconfig.Server.HTTP.AccessLog, tracing.LogFields))
+	r.Use(httpMetrics)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Heartbeat(envconfig.HealthCheckEndpoint))
 	r.Use(middleware.StripSlashes)
 
 	// configure routes
-	configureChiRoutes(r, logger)
+	if err := configureChiRoutes(lc, r, watcher, logger, level, db, registry); err != nil {
+		return nil, err
+	}
+	root.Mount("/", r)
 
-	return r
+	return root, nil
 }
 
-func configureChiRoutes(handler *chi.Mux, logger zaplog.Logger) {
-	//pageService := page.NewService(logger)
-	//webhookSvc := webhook.NewService(logger)
+func configureChiRoutes(lc *lifecycle.Manager, handler *chi.Mux, watcher *config.Watcher, logger zaplog.Logger, level *zaplog.Level,
+	db *sqlx.DB, registry *health.Registry) error {
+	envconfig := watcher.Current()
+	authService, err := auth.NewService(logger, envconfig.Auth, auth.NewRepository(db))
+	if err != nil {
+		return err
+	}
+	apiKeyService := auth.NewAPIKeyService(logger, auth.NewAPIKeyRepository(db))
+	trustedProxies, err := request.ParseTrustedProxies(envconfig.RateLimit.TrustedProxies)
+	if err != nil {
+		return err
+	}
+	limiter := ratelimit.NewLimiter(zaplog.Adapt(logger), ratelimit.NewMemoryStore(), envconfig.RateLimit.Groups,
+		rateLimitKey(trustedProxies))
+	watcher.Subscribe(func(envconfig *config.Configuration) {
+		limiter.SetGroups(envconfig.RateLimit.Groups)
+	})
+	var flagStore featureflag.Store
+	if envconfig.FeatureFlags.DatabaseOverrides {
+		flagStore = featureflag.NewRepositoryStore(postgres.NewRepository("feature_flag", db))
+	}
+	flags := featureflag.New(zaplog.Adapt(logger), envconfig.FeatureFlags.Flags, flagStore, featureFlagSubject)
+	watcher.Subscribe(func(envconfig *config.Configuration) {
+		flags.SetFlags(envconfig.FeatureFlags.Flags)
+	})
+	lc.Go("feature flags", func(ctx context.Context) {
+		flags.Run(ctx, envconfig.FeatureFlags.RefreshInterval)
+	})
+	registerHealthChecks(registry, db, flags)
+	postService := post.NewService(logger, post.NewRepository(db), post.NewRevisionRepository(db),
+		post.NewSlugHistoryRepository(db), post.NewTagRepository(db), post.NewRenderer())
+	authorService := author.NewService(logger, author.NewRepository(db))
+	tagService := tag.NewService(logger, tag.NewRepository(db), tag.NewSlugHistoryRepository(db))
+	commentService := comment.NewService(logger, comment.NewRepository(db), postService)
+	feedService := feed.NewService(logger, envconfig.Feed, envconfig.Site, postService, authorService, tagService)
+	sitemapService := sitemap.NewService(envconfig.Site.BaseURL,
+		post.NewRepository(db), author.NewRepository(db), tag.NewRepository(db))
+
+	// failed authentications are limited by IP address before the credentials are checked, so guessing
+	// tokens, API keys and passwords can't be repeated at the cost of a lookup each
+	handler.Use(limiter.Failures("auth_failures", http.StatusUnauthorized))
+
+	// the principal is available to every route, the ones changing data require it
+	handler.Use(auth.Middleware(logger, authService, apiKeyService))
+	handler.Use(legomiddleware.RequestLogger(zaplog.Adapt(logger), auth.LogFields, tracing.LogFields))
+
+	// routes, limited by group after the principal is known
+	handler.With(limiter.Middleware("auth")).Mount("/auth", auth.NewHandler(authService))
+
+	api := handler.With(limiter.Middleware("api"))
+	api.Mount("/auth/api-keys", auth.NewAPIKeyHandler(apiKeyService))
+	api.Mount("/posts", post.NewHandler(postService))
+	api.With(flags.Require("comments")).Mount("/posts/{postID}/comments", comment.NewHandler(commentService))
+	api.Mount("/authors", author.NewHandler(authorService))
+	api.Mount("/tags", tag.NewHandler(tagService))
+	api.Mount("/moderation/comments", comment.NewModerationHandler(commentService))
+	api.Mount("/admin", admin.NewHandler(level, flags))
+
+	feeds := handler.With(limiter.Middleware("feeds"))
+	feed.RegisterRoutes(feeds, feedService)
+	sitemap.RegisterRoutes(feeds, sitemapService)
 
-	// routes
-	//handler.Mount("/pages", page.NewHandler(pageService))
-	//handler.Mount("/webhook", webhook.NewHandler(webhookSvc))
+	return nil
 }
@@ -0,0 +1,156 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/environment"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/health"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/ratelimit"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/config"
+	"go.uber.org/zap"
+)
+
+// testConfiguration returns the defaults of the configuration with valid token secrets
+func testConfiguration(t *testing.T) *config.Configuration {
+	t.Helper()
+
+	envconfig := &config.Configuration{}
+	if err := environment.ApplyDefaults(envconfig); err != nil {
+		t.Fatal(err)
+	}
+	envconfig.Auth.Issuer = "test"
+	envconfig.Auth.AccessTokenSecret = strings.Repeat("a", 32)
+	envconfig.Auth.RefreshTokenSecret = strings.Repeat("r", 32)
+	return envconfig
+}
+
+// newTestRouter creates the router of the configuration, backed by a mocked database whose expectations
+// must be met in order
+func newTestRouter(t *testing.T, envconfig *config.Configuration) (http.Handler, sqlmock.Sqlmock,
+	*prometheus.Registry) {
+	t.Helper()
+
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unable to create mocked database: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		conn.Close()
+	})
+
+	logger := zaplog.New(zap.NewNop())
+	level, err := zaplog.NewLevel("info")
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics := prometheus.NewRegistry()
+	handler, err := newRouterHandler(newLifecycle(logger, envconfig.Lifecycle),
+		config.NewWatcher(logger, config.Source{Environment: "test", Path: t.TempDir()}, envconfig),
+		logger, level, sqlx.NewDb(conn, "postgres"), health.NewRegistry(envconfig.Health), metrics)
+	if err != nil {
+		t.Fatalf("newRouterHandler() error = %v", err)
+	}
+	return handler, mock, metrics
+}
+
+func TestProbesSkipMiddlewares(t *testing.T) {
+	envconfig := testConfiguration(t)
+	handler, _, metrics := newTestRouter(t, envconfig)
+
+	for _, path := range []string{"/livez", envconfig.HealthCheckEndpoint} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("GET %s status = %d, want %d", path, w.Code, http.StatusOK)
+		}
+	}
+
+	// the probes never reach the metrics middleware, unlike the other routes
+	if measured := measuredRequests(t, metrics); measured != 0 {
+		t.Errorf("%d probe requests were measured, want none", measured)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("GET /unknown status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if measured := measuredRequests(t, metrics); measured != 1 {
+		t.Errorf("%d requests were measured, want 1", measured)
+	}
+}
+
+func TestFailedAuthenticationsAreLimited(t *testing.T) {
+	envconfig := testConfiguration(t)
+	envconfig.RateLimit.Groups = map[string]ratelimit.Limit{
+		"auth_failures": {Requests: 1, Period: time.Hour, Burst: 2},
+	}
+	handler, mock, _ := newTestRouter(t, envconfig)
+
+	// only the first two guesses reach the database, the next ones are rejected before the lookup
+	for i := 0; i < 2; i++ {
+		mock.ExpectPrepare(`SELECT .+ FROM api_key WHERE key_hash = \$1`)
+		mock.ExpectQuery(`SELECT .+ FROM api_key WHERE key_hash = \$1`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		header     string
+		value      string
+		wantStatus int
+	}{
+		{name: "first guess", remoteAddr: "192.0.2.1:1234", header: "X-API-Key", value: "blog_guess1",
+			wantStatus: http.StatusUnauthorized},
+		{name: "second guess", remoteAddr: "192.0.2.1:1234", header: "X-API-Key", value: "blog_guess2",
+			wantStatus: http.StatusUnauthorized},
+		{name: "third guess", remoteAddr: "192.0.2.1:1234", header: "X-API-Key", value: "blog_guess3",
+			wantStatus: http.StatusTooManyRequests},
+		{name: "bearer token of the same address", remoteAddr: "192.0.2.1:1234", header: "Authorization",
+			value: "Bearer invalid", wantStatus: http.StatusTooManyRequests},
+		{name: "another address", remoteAddr: "192.0.2.2:1234", header: "Authorization", value: "Bearer invalid",
+			wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/posts", nil)
+		r.RemoteAddr = tt.remoteAddr
+		r.Header.Set(tt.header, tt.value)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.wantStatus)
+		}
+	}
+}
+
+// measuredRequests returns the quantity of requests counted by the metrics middleware
+func measuredRequests(t *testing.T, metrics *prometheus.Registry) int {
+	t.Helper()
+
+	families, err := metrics.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	measured := 0
+	for _, family := range families {
+		if family.GetName() == "http_requests_total" {
+			for _, metric := range family.GetMetric() {
+				measured += int(metric.GetCounter().GetValue())
+			}
+		}
+	}
+	return measured
+}
@@ -2,57 +2,80 @@ package httpserver
 
 import (
 	"context"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/health"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/lifecycle"
 	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/tracing"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/config"
 	"net/http"
 	"os"
-	"os/signal"
 	"syscall"
-	"time"
 
+	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
 )
 
-func RunHTTPServer(ctx context.Context, zaplog zaplog.Logger, envconfig *Configuration) {
-	// configure HTTP Routes
-	routesHandler := newRouterHandler(envconfig, zaplog)
+// RunHTTPServer registers the components of the server into the lifecycle and runs them until the context
+// is done. They stop in the reverse order: the readiness probe fails first, then the servers drain their
+// requests, the background jobs stop, the pending spans are flushed and the components registered by the
+// caller, such as the database pool and the logger, are closed
+func RunHTTPServer(ctx context.Context, lc *lifecycle.Manager, zaplog zaplog.Logger, level *zaplog.Level,
+	source config.Source, envconfig *config.Configuration, db *sqlx.DB) {
+	// spans are exported until the other components stop, the pending ones are flushed on the way out
+	shutdownTracing, err := tracing.Setup(ctx, envconfig.Tracing, envconfig.AppName)
+	if err != nil {
+		zaplog.Fatal("Couldn't configure tracing", zap.Error(err))
+	}
+	lc.Append(lifecycle.Hook{Name: "tracing", Stop: shutdownTracing})
+
+	// the configuration is reloaded when its files change, or on SIGHUP
+	watcher := config.NewWatcher(zaplog, source, envconfig)
+	watcher.Subscribe(func(envconfig *config.Configuration) {
+		if err := level.SetDefault(envconfig.LogLevel); err != nil {
+			zaplog.Error("Unable to reload log level", zap.Error(err))
+		}
+	})
+	lc.Go("config watcher", watcher.Run)
+	lc.Go("config reload", func(ctx context.Context) {
+		reloadConfig(ctx, watcher)
+	})
+
+	// metrics are served by the admin listener, apart from the public API
+	metrics, err := newMetricsRegistry(envconfig.AppName, db)
+	if err != nil {
+		zaplog.Fatal("Couldn't register metrics", zap.Error(err))
+	}
+
+	// configure HTTP Routes, with the probes of the health checks
+	registry := health.NewRegistry(envconfig.Health)
+	routesHandler, err := newRouterHandler(lc, watcher, zaplog, level, db, registry, metrics)
+	if err != nil {
+		zaplog.Fatal("Couldn't configure HTTP routes", zap.Error(err))
+	}
+
+	if !envconfig.Server.Admin.Disabled {
+		adminServer := &http.Server{
+			Addr:    envconfig.Server.Admin.ListenAddr,
+			Handler: newAdminHandler(metrics),
+		}
+		lc.Append(serverHook(lc, "Admin Server", adminServer, envconfig.Server.Admin.Network, zaplog))
+	}
 
 	// create HTTP Server with handler from router
 	httpServer := &http.Server{
 		Addr:    envconfig.Server.HTTP.ListenAddr,
 		Handler: routesHandler,
 	}
+	lc.Append(serverHook(lc, "HTTP Server", httpServer, envconfig.Server.HTTP.Network, zaplog))
 
-	// signal sent from terminal (Ctrl+C = os.Interrupt) or Kubernetes Signal (SIGTERM)
-	interruptServerSignal := make(chan os.Signal, 1)
-	signal.Notify(interruptServerSignal, os.Interrupt, syscall.SIGTERM)
-
-	// create channel to do graceful shutdown
-	done := make(chan bool, 1)
-	go gracefulShutdown(httpServer, zaplog, interruptServerSignal, done)
-
-	zaplog.Info("HTTP Server is ready to handle request",
-		zap.String("listenAddr", httpServer.Addr),
-		zap.String("environment", envconfig.EnvironmentName))
-	err := httpServer.ListenAndServe()
-	if err != nil && err != http.ErrServerClosed {
-		zaplog.Fatal("Couldn't listen on selected port",
-			zap.String("listenAddr", envconfig.Server.HTTP.ListenAddr), zap.Error(err),
-		)
-	}
-
-	<-done
-	zaplog.Warn("HTTP Server stopped")
-}
+	// registered last so it stops first: the readiness probe fails while the servers still handle requests,
+	// so no new traffic is routed to this instance before the listeners close
+	lc.Append(lifecycle.Hook{Name: "readiness", Stop: registry.Drain})
 
-func gracefulShutdown(server *http.Server, zaplog zaplog.Logger, quit <-chan os.Signal, done chan<- bool) {
-	<-quit
-	//logger.Info("HTTP Server is shutting down")
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-	server.SetKeepAlivesEnabled(false)
-	if err := server.Shutdown(ctx); err != nil {
-		zaplog.Fatal("Could not gracefully shutdown the HTTP server", zap.Error(err))
+	// the context is canceled by the first signal sent from terminal (Ctrl+C = os.Interrupt) or Kubernetes
+	// (SIGTERM), a second one exits without waiting for the components
+	zaplog.Info("HTTP Server is starting", zap.String("environment", envconfig.EnvironmentName))
+	if err := lc.Run(ctx, os.Interrupt, syscall.SIGTERM); err != nil {
+		zaplog.Fatal("HTTP Server stopped with errors", zap.Error(err))
 	}
-
-	close(done)
 }
@@ -0,0 +1,117 @@
+package sitemap
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database/postgres"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/author"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/config"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/post"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/sitemap"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/tag"
+	"go.uber.org/zap"
+)
+
+// SitemapCMD writes the sitemap index and files to a directory, for static hosting
+var SitemapCMD = &cobra.Command{
+	Use:   "sitemap",
+	Short: "Writes the sitemap files of all published content to disk",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+
+		// get flag params - panic if any error
+		source, err := config.SourceFromFlags(cmd.Flags())
+		if err != nil {
+			panic(err)
+		}
+		outputFlag, err := cmd.Flags().GetString("output")
+		if err != nil {
+			panic(err)
+		}
+
+		// get environment configuration - panic if any error
+		envconfig, err := config.Load(source)
+		if err != nil {
+			panic(err)
+		}
+
+		// configure logger (Zap Logger) - panic if any error
+		zaplog, err := zaplog.NewCustomZap(envconfig.LoggerConfig())
+		if err != nil {
+			panic(fmt.Errorf("failed to configure zaplog logger: %s", err))
+		}
+
+		// connect to postgreSQL - panic if any error
+		db, err := postgres.Connect(ctx, envconfig.Database)
+		if err != nil {
+			panic(fmt.Errorf("failed to connect to database: %s", err))
+		}
+		defer zaplog.SafeClose(db, "Unable to close database connection")
+
+		svc := sitemap.NewService(envconfig.Site.BaseURL,
+			post.NewRepository(db), author.NewRepository(db), tag.NewRepository(db))
+
+		files, err := writeFiles(ctx, svc, outputFlag)
+		if err != nil {
+			zaplog.Fatal("Unable to write sitemap", zap.String("output", outputFlag), zap.Error(err))
+		}
+
+		zaplog.Info("Sitemap written", zap.String("output", outputFlag), zap.Int("files", files))
+	},
+}
+
+// writeFiles writes sitemap.xml and every sitemap-<n>.xml into the output directory
+func writeFiles(ctx context.Context, svc sitemap.Service, output string) (int, error) {
+	if err := os.MkdirAll(output, 0o755); err != nil {
+		return 0, err
+	}
+
+	files, err := svc.Files(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	err = writeFile(filepath.Join(output, "sitemap.xml"), func(w *bufio.Writer) error {
+		return svc.WriteIndex(ctx, w)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for file := 1; file <= files; file++ {
+		file := file
+		err = writeFile(filepath.Join(output, sitemap.FileName(file)), func(w *bufio.Writer) error {
+			return svc.Write(ctx, w, file)
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return files, nil
+}
+
+func writeFile(path string, write func(w *bufio.Writer) error) error {
+	f, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	if err := write(w); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
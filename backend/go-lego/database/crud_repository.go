@@ -8,9 +8,18 @@ type CRUDRepository interface {
 	Insert(ctx context.Context, data interface{}, outputInsertedID interface{}) error
 	Find(ctx context.Context, filter interface{}, output interface{}) error
 	FindAll(ctx context.Context, output interface{}) error
+	FindPage(ctx context.Context, filter interface{}, page Page, output interface{}) error
+	Each(ctx context.Context, filter interface{}, page Page, dest interface{}, fn func() error) error
 	FindOne(ctx context.Context, filter interface{}, result interface{}) error
 	Update(ctx context.Context, set map[string]interface{}, filter interface{}) (int64, error)
 	Remove(ctx context.Context, filter interface{}, physicalDeletion bool) (int64, error)
 	Count(ctx context.Context, filter interface{}) (int64, error)
 	ExtractColumnPairs(data interface{}) ([]string, []interface{})
 }
+
+// Page describes which slice of an ordered result set must be returned
+type Page struct {
+	Limit   uint64
+	Offset  uint64
+	OrderBy []string
+}
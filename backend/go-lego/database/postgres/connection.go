@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	// registers the "postgres" driver into database/sql
+	_ "github.com/lib/pq"
+)
+
+// Config contains the configuration of a postgreSQL connection pool
+type Config struct {
+	DSN             string        `validate:"required" secret:"true"`
+	MaxOpenConns    int           `validate:"min=0"`
+	MaxIdleConns    int           `validate:"min=0"`
+	ConnMaxLifetime time.Duration `validate:"min=0s"`
+
+	// SlowQueryThreshold is the duration from which queries are logged as slow
+	SlowQueryThreshold time.Duration `default:"200ms" validate:"min=0s"`
+}
+
+// Connect opens a postgreSQL connection pool and verifies that the database is reachable
+func Connect(ctx context.Context, config Config) (*sqlx.DB, error) {
+	db, err := sqlx.ConnectContext(ctx, "postgres", config.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to postgres: %w", err)
+	}
+
+	// configure connection pool (zero values keep database/sql defaults)
+	if config.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(config.MaxOpenConns)
+	}
+	if config.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(config.MaxIdleConns)
+	}
+	if config.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(config.ConnMaxLifetime)
+	}
+	if config.SlowQueryThreshold > 0 {
+		SetSlowQueryThreshold(config.SlowQueryThreshold)
+	}
+
+	return db, nil
+}
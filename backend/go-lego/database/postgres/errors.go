@@ -0,0 +1,29 @@
+package postgres
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// PostgreSQL error codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	codeForeignKeyViolation = "23503"
+	codeUniqueViolation     = "23505"
+	codeUndefinedTable      = "42P01"
+)
+
+// IsForeignKeyViolation checks if the error was caused by a missing or still referenced row
+func IsForeignKeyViolation(err error) bool {
+	return hasCode(err, codeForeignKeyViolation)
+}
+
+// IsUniqueViolation checks if the error was caused by a duplicated unique value
+func IsUniqueViolation(err error) bool {
+	return hasCode(err, codeUniqueViolation)
+}
+
+func hasCode(err error, code pq.ErrorCode) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code
+}
@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/health"
+)
+
+// PingCheck checks that a connection of the pool reaches the database
+func PingCheck(db *sqlx.DB) health.CheckFunc {
+	return func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	}
+}
+
+// SchemaVersionCheck checks that the migrations were applied up to the given version, as recorded by
+// golang-migrate in the schema_migrations table. A migration that failed halfway leaves the schema dirty
+func SchemaVersionCheck(db *sqlx.DB, version int64) health.CheckFunc {
+	return func(ctx context.Context) error {
+		var current struct {
+			Version int64 `db:"version"`
+			Dirty   bool  `db:"dirty"`
+		}
+		err := db.GetContext(ctx, &current, "SELECT version, dirty FROM schema_migrations LIMIT 1")
+		if errors.Is(err, sql.ErrNoRows) || hasCode(err, codeUndefinedTable) {
+			return errors.New("migrations not applied")
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case current.Dirty:
+			return fmt.Errorf("migration %d failed, the schema is dirty", current.Version)
+		case current.Version < version:
+			return fmt.Errorf("migrations not applied, the schema is at version %d instead of %d", current.Version, version)
+		}
+		return nil
+	}
+}
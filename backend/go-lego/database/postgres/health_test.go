@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+func TestSchemaVersionCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    *sqlmock.Rows
+		err     error
+		wantErr bool
+	}{
+		{
+			name: "up to date",
+			rows: sqlmock.NewRows([]string{"version", "dirty"}).AddRow(9, false),
+		},
+		{
+			name: "newer schema",
+			rows: sqlmock.NewRows([]string{"version", "dirty"}).AddRow(10, false),
+		},
+		{
+			name:    "older schema",
+			rows:    sqlmock.NewRows([]string{"version", "dirty"}).AddRow(8, false),
+			wantErr: true,
+		},
+		{
+			name:    "dirty schema",
+			rows:    sqlmock.NewRows([]string{"version", "dirty"}).AddRow(9, true),
+			wantErr: true,
+		},
+		{
+			name:    "no migration applied",
+			rows:    sqlmock.NewRows([]string{"version", "dirty"}),
+			wantErr: true,
+		},
+		{
+			name:    "migration table missing",
+			err:     &pq.Error{Code: codeUndefinedTable},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("unable to create mocked database: %v", err)
+			}
+			defer conn.Close()
+
+			query := mock.ExpectQuery(`SELECT version, dirty FROM schema_migrations`)
+			if tt.err != nil {
+				query.WillReturnError(tt.err)
+			} else {
+				query.WillReturnRows(tt.rows)
+			}
+
+			err = SchemaVersionCheck(sqlx.NewDb(conn, "postgres"), 9)(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SchemaVersionCheck() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
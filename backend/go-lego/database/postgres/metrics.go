@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// queryDuration and queryErrors are shared by all repositories, like the slow query threshold
+var (
+	queryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "Duration of the queries done by the repositories",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"table", "operation"})
+
+	queryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_query_errors_total",
+		Help: "Queries done by the repositories that returned an error",
+	}, []string{"table", "operation"})
+)
+
+// RegisterMetrics registers the query metrics of the repositories and the pool statistics of db, labelled
+// with the given database name
+func RegisterMetrics(registerer prometheus.Registerer, db *sqlx.DB, name string) error {
+	for _, collector := range []prometheus.Collector{
+		queryDuration,
+		queryErrors,
+		collectors.NewDBStatsCollector(db.DB, name),
+	} {
+		if err := registerer.Register(collector); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
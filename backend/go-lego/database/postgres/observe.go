@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/tracing"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// queryObservation measures a query of a repository, from the moment it is sent until its result is read
+type queryObservation struct {
+	ctx       context.Context
+	table     string
+	operation string
+	query     string
+	start     time.Time
+	span      trace.Span
+}
+
+// startQuery starts the span of the query as a child of the span of the context, usually the one of a service
+func startQuery(ctx context.Context, table string, operation string, query string) *queryObservation {
+	_, span := tracing.Start(ctx, operation+" "+table,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBSQLTable(table),
+			semconv.DBOperation(operation),
+			semconv.DBStatement(query),
+		))
+
+	return &queryObservation{ctx: ctx, table: table, operation: operation, query: query, start: time.Now(), span: span}
+}
+
+// end records the duration and the error of the query, ends its span and logs it when slow. A missing row
+// isn't counted as an error, since it's an expected result of FindOne
+func (o *queryObservation) end(err *error) {
+	queryDuration.WithLabelValues(o.table, o.operation).Observe(time.Since(o.start).Seconds())
+	if *err != nil && !errors.Is(*err, sql.ErrNoRows) {
+		queryErrors.WithLabelValues(o.table, o.operation).Inc()
+		o.span.RecordError(*err)
+		o.span.SetStatus(codes.Error, (*err).Error())
+	}
+	o.span.End()
+
+	logSlowQuery(o.ctx, o.table, o.query, o.start)
+}
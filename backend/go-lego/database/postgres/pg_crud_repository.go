@@ -15,6 +15,7 @@ import (
 
 type Pg interface {
 	database.CRUDRepository
+	Select(ctx context.Context, operation string, qb sq.SelectBuilder, output interface{}) error
 	BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error)
 	WithTx(tx *Tx) PgTx
 	GetConn() *sqlx.DB
@@ -37,23 +38,26 @@ func NewRepository(tableName string, session *sqlx.DB) Pg {
 	}
 }
 
-// Insert inserts a single record
-func (b *pgRepository) Insert(ctx context.Context, data interface{}, lastInsertedID interface{}) error {
+// Insert inserts a single record. The id of the record is scanned into lastInsertedID when it is informed
+func (b *pgRepository) Insert(ctx context.Context, data interface{}, lastInsertedID interface{}) (err error) {
 	columns, values := b.ExtractColumnPairs(data)
 
-	// Prepare query
+	// Prepare query, the id is only returned when asked for, since not every table has one
 	queryBuilder := sq.
 		Insert(b.table).
 		Columns(columns...).
 		Values(values...).
-		Suffix("returning \"id\"").
 		PlaceholderFormat(sq.Dollar)
+	if lastInsertedID != nil {
+		queryBuilder = queryBuilder.Suffix("returning \"id\"")
+	}
 
 	// Build SQL Query
 	query, args, err := queryBuilder.ToSql()
 	if err != nil {
 		return fmt.Errorf("unable to build query: %w", err)
 	}
+	defer startQuery(ctx, b.table, "insert", query).end(&err)
 
 	// Prepare statement and defer it's closure
 	stmt, err := b.session.PreparexContext(ctx, query)
@@ -77,7 +81,7 @@ func (b *pgRepository) Insert(ctx context.Context, data interface{}, lastInserte
 }
 
 // FindAll returns all records from database
-func (b *pgRepository) FindAll(ctx context.Context, output interface{}) error {
+func (b *pgRepository) FindAll(ctx context.Context, output interface{}) (err error) {
 	//TODO implement pagination
 
 	// Prepare query
@@ -88,6 +92,7 @@ func (b *pgRepository) FindAll(ctx context.Context, output interface{}) error {
 	if err != nil {
 		return fmt.Errorf("unable to build query: %w", err)
 	}
+	defer startQuery(ctx, b.table, "find_all", query).end(&err)
 
 	// Prepare statement and defer it's closure
 	stmt, err := b.session.PreparexContext(ctx, query)
@@ -105,8 +110,99 @@ func (b *pgRepository) FindAll(ctx context.Context, output interface{}) error {
 	return nil
 }
 
+// FindPage returns the records that match the filter inside the requested page. A nil filter matches all records
+func (b *pgRepository) FindPage(ctx context.Context, filter interface{}, page database.Page, output interface{}) (err error) {
+	// Prepare query
+	qb := sq.Select("*").
+		From(b.table).
+		OrderBy(page.OrderBy...).
+		PlaceholderFormat(sq.Dollar)
+
+	if filter != nil {
+		qb = qb.Where(filter)
+	}
+	if page.Limit > 0 {
+		qb = qb.Limit(page.Limit)
+	}
+	if page.Offset > 0 {
+		qb = qb.Offset(page.Offset)
+	}
+
+	// Build SQL Query
+	query, args, err := qb.ToSql()
+	if err != nil {
+		return err
+	}
+	defer startQuery(ctx, b.table, "find_page", query).end(&err)
+
+	// Prepare statement and defer it's closure
+	stmt, err := b.session.PreparexContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer func(stmt *sqlx.Stmt) {
+		//b.logger.SafeClose(stmt, "Unable to close statement")
+	}(stmt)
+
+	// Do the find
+	err = stmt.SelectContext(ctx, output, args...)
+	if err != nil {
+		return err
+	}
+
+	// No errors
+	return nil
+}
+
+// Each streams the records that match the filter inside the requested page. Every record is scanned
+// into dest and then fn is called, so the result set is never loaded into memory at once
+func (b *pgRepository) Each(ctx context.Context, filter interface{}, page database.Page, dest interface{}, fn func() error) (err error) {
+	// Prepare query
+	qb := sq.Select("*").
+		From(b.table).
+		OrderBy(page.OrderBy...).
+		PlaceholderFormat(sq.Dollar)
+
+	if filter != nil {
+		qb = qb.Where(filter)
+	}
+	if page.Limit > 0 {
+		qb = qb.Limit(page.Limit)
+	}
+	if page.Offset > 0 {
+		qb = qb.Offset(page.Offset)
+	}
+
+	// Build SQL Query
+	query, args, err := qb.ToSql()
+	if err != nil {
+		return err
+	}
+
+	// Do the query, the time spent by fn isn't part of the query duration
+	observation := startQuery(ctx, b.table, "each", query)
+	rows, err := b.session.QueryxContext(ctx, query, args...)
+	observation.end(&err)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := rows.StructScan(dest); err != nil {
+			return err
+		}
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+
+	// Return possible iteration error
+	return rows.Err()
+}
+
 // FindOne returns only one record given the filter
-func (b *pgRepository) FindOne(ctx context.Context, filter interface{}, output interface{}) error {
+func (b *pgRepository) FindOne(ctx context.Context, filter interface{}, output interface{}) (err error) {
 	columns, _ := b.ExtractColumnPairs(output)
 
 	// Prepare query
@@ -121,6 +217,7 @@ func (b *pgRepository) FindOne(ctx context.Context, filter interface{}, output i
 	if err != nil {
 		return err
 	}
+	defer startQuery(ctx, b.table, "find_one", query).end(&err)
 
 	// Prepare statement and defer it's closure
 	stmt, err := b.session.PreparexContext(ctx, query)
@@ -142,7 +239,7 @@ func (b *pgRepository) FindOne(ctx context.Context, filter interface{}, output i
 }
 
 // Find returns all records from database that match the filter
-func (b *pgRepository) Find(ctx context.Context, filter interface{}, output interface{}) error {
+func (b *pgRepository) Find(ctx context.Context, filter interface{}, output interface{}) (err error) {
 	// Prepare query
 	qb := sq.Select("*").
 		From(b.table).
@@ -154,6 +251,7 @@ func (b *pgRepository) Find(ctx context.Context, filter interface{}, output inte
 	if err != nil {
 		return err
 	}
+	defer startQuery(ctx, b.table, "find", query).end(&err)
 
 	// Prepare statement and defer it's closure
 	stmt, err := b.session.PreparexContext(ctx, query)
@@ -174,8 +272,21 @@ func (b *pgRepository) Find(ctx context.Context, filter interface{}, output inte
 	return nil
 }
 
+// Select runs a query that the CRUD methods can't build, e.g. an aggregation, measured and traced like
+// them. The operation names the query in the metrics and spans, e.g. count_by_posts
+func (b *pgRepository) Select(ctx context.Context, operation string, qb sq.SelectBuilder, output interface{}) (err error) {
+	// Build SQL Query
+	query, args, err := qb.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+	defer startQuery(ctx, b.table, operation, query).end(&err)
+
+	return b.session.SelectContext(ctx, output, query, args...)
+}
+
 // Update updates records matching the given filter
-func (b *pgRepository) Update(ctx context.Context, set map[string]interface{}, filter interface{}) (int64, error) {
+func (b *pgRepository) Update(ctx context.Context, set map[string]interface{}, filter interface{}) (_ int64, err error) {
 	// Prepare query
 	qb := sq.Update(b.table).
 		SetMap(set).
@@ -187,6 +298,7 @@ func (b *pgRepository) Update(ctx context.Context, set map[string]interface{}, f
 	if err != nil {
 		return 0, err
 	}
+	defer startQuery(ctx, b.table, "update", query).end(&err)
 
 	// Prepare statement and defer it's closure
 	stmt, err := b.session.PreparexContext(ctx, query)
@@ -208,7 +320,7 @@ func (b *pgRepository) Update(ctx context.Context, set map[string]interface{}, f
 }
 
 // Remove updates the records that match the given filter to deleted status. The record isn't really deleted from database
-func (b *pgRepository) Remove(ctx context.Context, filter interface{}, physicalDeletion bool) (int64, error) {
+func (b *pgRepository) Remove(ctx context.Context, filter interface{}, physicalDeletion bool) (_ int64, err error) {
 	if !physicalDeletion {
 		// Logical deletion set
 		updateStatusSet := map[string]interface{}{
@@ -228,6 +340,7 @@ func (b *pgRepository) Remove(ctx context.Context, filter interface{}, physicalD
 	if err != nil {
 		return 0, err
 	}
+	defer startQuery(ctx, b.table, "remove", query).end(&err)
 
 	// Prepare statement and defer it's closure
 	stmt, err := b.session.PreparexContext(ctx, query)
@@ -250,7 +363,7 @@ func (b *pgRepository) Remove(ctx context.Context, filter interface{}, physicalD
 
 // Count counts how many records match the filter. If no filter is given will return the quantity
 // of all records stored
-func (b *pgRepository) Count(ctx context.Context, filter interface{}) (int64, error) {
+func (b *pgRepository) Count(ctx context.Context, filter interface{}) (_ int64, err error) {
 	// Prepare query
 	qb := sq.Select("count(*) as count").
 		From(b.table).
@@ -265,6 +378,7 @@ func (b *pgRepository) Count(ctx context.Context, filter interface{}) (int64, er
 	if err != nil {
 		return 0, err
 	}
+	defer startQuery(ctx, b.table, "count", query).end(&err)
 
 	// Prepare statement and defer it's closure
 	stmt, err := b.session.PreparexContext(ctx, query)
@@ -309,17 +423,21 @@ func (b *pgRepository) WithTx(tx *Tx) PgTx {
 // -------------------------------------------------------------------------------------------
 
 func (b *pgRepository) ExtractColumnPairs(data interface{}) ([]string, []interface{}) {
-	// create type mapper
-	valueMap := b.mapper.FieldMap(reflect.ValueOf(data))
+	return extractColumnPairs(b.mapper, data)
+}
+
+// extractColumnPairs reads the columns of data and their values. The fields are only read, unlike with
+// FieldMap, so nil pointers are kept as NULL and data may be passed by value
+func extractColumnPairs(mapper *reflectx.Mapper, data interface{}) ([]string, []interface{}) {
+	value := reflect.Indirect(reflect.ValueOf(data))
+	fields := mapper.TypeMap(value.Type()).Names
 
 	// Extract columns
-	var columns = make([]string, len(valueMap))
-	var values = make([]interface{}, len(valueMap))
-	i := 0
-	for column, value := range valueMap {
-		columns[i] = column
-		values[i] = value.Interface()
-		i++
+	var columns = make([]string, 0, len(fields))
+	var values = make([]interface{}, 0, len(fields))
+	for column, field := range fields {
+		columns = append(columns, column)
+		values = append(values, reflectx.FieldByIndexesReadOnly(value, field.Index).Interface())
 	}
 
 	// Return all elements
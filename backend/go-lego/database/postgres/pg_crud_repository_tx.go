@@ -3,7 +3,6 @@ package postgres
 import (
 	"context"
 	"fmt"
-	"reflect"
 
 	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database"
 
@@ -14,6 +13,8 @@ import (
 
 type PgTx interface {
 	database.CRUDRepository
+	FindOneForUpdate(ctx context.Context, filter interface{}, output interface{}) error
+	Select(ctx context.Context, operation string, qb sq.SelectBuilder, output interface{}) error
 	GetTxConn() *sqlx.Tx
 }
 
@@ -31,23 +32,26 @@ func newTxRepository(tableName string, session *Tx) PgTx {
 	}
 }
 
-// Insert inserts a single record
-func (b *PgTxRepository) Insert(ctx context.Context, data interface{}, lastInsertedID interface{}) error {
+// Insert inserts a single record. The id of the record is scanned into lastInsertedID when it is informed
+func (b *PgTxRepository) Insert(ctx context.Context, data interface{}, lastInsertedID interface{}) (err error) {
 	columns, values := b.ExtractColumnPairs(data)
 
-	// Prepare query
+	// Prepare query, the id is only returned when asked for, since not every table has one
 	queryBuilder := sq.
 		Insert(b.table).
 		Columns(columns...).
 		Values(values...).
-		Suffix("returning \"id\"").
 		PlaceholderFormat(sq.Dollar)
+	if lastInsertedID != nil {
+		queryBuilder = queryBuilder.Suffix("returning \"id\"")
+	}
 
 	// Build SQL Query
 	query, args, err := queryBuilder.ToSql()
 	if err != nil {
 		return fmt.Errorf("unable to build query: %w", err)
 	}
+	defer startQuery(ctx, b.table, "insert", query).end(&err)
 
 	// The statements prepared for a transaction by calling the transaction's Prepare or Stmt methods
 	// are closed by the call to Commit or Rollback.
@@ -69,7 +73,7 @@ func (b *PgTxRepository) Insert(ctx context.Context, data interface{}, lastInser
 }
 
 // FindAll returns all records from database
-func (b *PgTxRepository) FindAll(ctx context.Context, output interface{}) error {
+func (b *PgTxRepository) FindAll(ctx context.Context, output interface{}) (err error) {
 	//TODO implement pagination
 
 	// Prepare query
@@ -80,6 +84,7 @@ func (b *PgTxRepository) FindAll(ctx context.Context, output interface{}) error
 	if err != nil {
 		return fmt.Errorf("unable to build query: %w", err)
 	}
+	defer startQuery(ctx, b.table, "find_all", query).end(&err)
 
 	// The statements prepared for a transaction by calling the transaction's Prepare or Stmt methods
 	// are closed by the call to Commit or Rollback.
@@ -95,8 +100,107 @@ func (b *PgTxRepository) FindAll(ctx context.Context, output interface{}) error
 	return nil
 }
 
+// FindPage returns the records that match the filter inside the requested page. A nil filter matches all records
+func (b *PgTxRepository) FindPage(ctx context.Context, filter interface{}, page database.Page, output interface{}) (err error) {
+	// Prepare query
+	qb := sq.Select("*").
+		From(b.table).
+		OrderBy(page.OrderBy...).
+		PlaceholderFormat(sq.Dollar)
+
+	if filter != nil {
+		qb = qb.Where(filter)
+	}
+	if page.Limit > 0 {
+		qb = qb.Limit(page.Limit)
+	}
+	if page.Offset > 0 {
+		qb = qb.Offset(page.Offset)
+	}
+
+	// Build SQL Query
+	query, args, err := qb.ToSql()
+	if err != nil {
+		return err
+	}
+	defer startQuery(ctx, b.table, "find_page", query).end(&err)
+
+	// The statements prepared for a transaction by calling the transaction's Prepare or Stmt methods
+	// are closed by the call to Commit or Rollback.
+	stmt, err := b.tx.PreparexContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	// Do the find
+	err = stmt.SelectContext(ctx, output, args...)
+	if err != nil {
+		return err
+	}
+
+	// No errors
+	return nil
+}
+
+// Each streams the records that match the filter inside the requested page. Every record is scanned
+// into dest and then fn is called, so the result set is never loaded into memory at once
+func (b *PgTxRepository) Each(ctx context.Context, filter interface{}, page database.Page, dest interface{}, fn func() error) (err error) {
+	// Prepare query
+	qb := sq.Select("*").
+		From(b.table).
+		OrderBy(page.OrderBy...).
+		PlaceholderFormat(sq.Dollar)
+
+	if filter != nil {
+		qb = qb.Where(filter)
+	}
+	if page.Limit > 0 {
+		qb = qb.Limit(page.Limit)
+	}
+	if page.Offset > 0 {
+		qb = qb.Offset(page.Offset)
+	}
+
+	// Build SQL Query
+	query, args, err := qb.ToSql()
+	if err != nil {
+		return err
+	}
+
+	// Do the query, the time spent by fn isn't part of the query duration
+	observation := startQuery(ctx, b.table, "each", query)
+	rows, err := b.tx.QueryxContext(ctx, query, args...)
+	observation.end(&err)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := rows.StructScan(dest); err != nil {
+			return err
+		}
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+
+	// Return possible iteration error
+	return rows.Err()
+}
+
 // FindOne returns only one record given the filter
 func (b *PgTxRepository) FindOne(ctx context.Context, filter interface{}, output interface{}) error {
+	return b.findOne(ctx, filter, output, false)
+}
+
+// FindOneForUpdate returns the first record that matches the filter and locks it until the end of the
+// transaction, so concurrent transactions wait before reading it
+func (b *PgTxRepository) FindOneForUpdate(ctx context.Context, filter interface{}, output interface{}) error {
+	return b.findOne(ctx, filter, output, true)
+}
+
+func (b *PgTxRepository) findOne(ctx context.Context, filter interface{}, output interface{}, lock bool) (err error) {
 	columns, _ := b.ExtractColumnPairs(output)
 
 	// Prepare query
@@ -105,12 +209,18 @@ func (b *PgTxRepository) FindOne(ctx context.Context, filter interface{}, output
 		Where(filter).
 		Limit(1).
 		PlaceholderFormat(sq.Dollar)
+	operation := "find_one"
+	if lock {
+		qb = qb.Suffix("FOR UPDATE")
+		operation = "find_one_for_update"
+	}
 
 	// Build SQL Query
 	query, args, err := qb.ToSql()
 	if err != nil {
 		return err
 	}
+	defer startQuery(ctx, b.table, operation, query).end(&err)
 
 	// The statements prepared for a transaction by calling the transaction's Prepare or Stmt methods
 	// are closed by the call to Commit or Rollback.
@@ -130,7 +240,7 @@ func (b *PgTxRepository) FindOne(ctx context.Context, filter interface{}, output
 }
 
 // Find returns all records from database that match the filter
-func (b *PgTxRepository) Find(ctx context.Context, filter interface{}, output interface{}) error {
+func (b *PgTxRepository) Find(ctx context.Context, filter interface{}, output interface{}) (err error) {
 	// Prepare query
 	qb := sq.Select("*").
 		From(b.table).
@@ -142,6 +252,7 @@ func (b *PgTxRepository) Find(ctx context.Context, filter interface{}, output in
 	if err != nil {
 		return err
 	}
+	defer startQuery(ctx, b.table, "find", query).end(&err)
 
 	// The statements prepared for a transaction by calling the transaction's Prepare or Stmt methods
 	// are closed by the call to Commit or Rollback.
@@ -160,8 +271,20 @@ func (b *PgTxRepository) Find(ctx context.Context, filter interface{}, output in
 	return nil
 }
 
+// Select runs a query that the CRUD methods can't build inside the transaction, see pgRepository.Select
+func (b *PgTxRepository) Select(ctx context.Context, operation string, qb sq.SelectBuilder, output interface{}) (err error) {
+	// Build SQL Query
+	query, args, err := qb.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+	defer startQuery(ctx, b.table, operation, query).end(&err)
+
+	return b.tx.SelectContext(ctx, output, query, args...)
+}
+
 // Update updates records matching the given filter
-func (b *PgTxRepository) Update(ctx context.Context, set map[string]interface{}, filter interface{}) (int64, error) {
+func (b *PgTxRepository) Update(ctx context.Context, set map[string]interface{}, filter interface{}) (_ int64, err error) {
 	// Prepare query
 	qb := sq.Update(b.table).
 		SetMap(set).
@@ -173,6 +296,7 @@ func (b *PgTxRepository) Update(ctx context.Context, set map[string]interface{},
 	if err != nil {
 		return 0, err
 	}
+	defer startQuery(ctx, b.table, "update", query).end(&err)
 
 	// The statements prepared for a transaction by calling the transaction's Prepare or Stmt methods
 	// are closed by the call to Commit or Rollback.
@@ -192,7 +316,7 @@ func (b *PgTxRepository) Update(ctx context.Context, set map[string]interface{},
 }
 
 // Remove updates the records that match the given filter to deleted status. The record isn't really deleted from database
-func (b *PgTxRepository) Remove(ctx context.Context, filter interface{}, physicalDeletion bool) (int64, error) {
+func (b *PgTxRepository) Remove(ctx context.Context, filter interface{}, physicalDeletion bool) (_ int64, err error) {
 	if !physicalDeletion {
 		// Logical deletion set
 		updateStatusSet := map[string]interface{}{
@@ -212,6 +336,7 @@ func (b *PgTxRepository) Remove(ctx context.Context, filter interface{}, physica
 	if err != nil {
 		return 0, err
 	}
+	defer startQuery(ctx, b.table, "remove", query).end(&err)
 
 	// The statements prepared for a transaction by calling the transaction's Prepare or Stmt methods
 	// are closed by the call to Commit or Rollback.
@@ -232,7 +357,7 @@ func (b *PgTxRepository) Remove(ctx context.Context, filter interface{}, physica
 
 // Count counts how many records match the filter. If no filter is given will return the quantity
 // of all records stored
-func (b *PgTxRepository) Count(ctx context.Context, filter interface{}) (int64, error) {
+func (b *PgTxRepository) Count(ctx context.Context, filter interface{}) (_ int64, err error) {
 	// Prepare query
 	qb := sq.Select("count(*) as count").
 		From(b.table).
@@ -247,6 +372,7 @@ func (b *PgTxRepository) Count(ctx context.Context, filter interface{}) (int64,
 	if err != nil {
 		return 0, err
 	}
+	defer startQuery(ctx, b.table, "count", query).end(&err)
 
 	// The statements prepared for a transaction by calling the transaction's Prepare or Stmt methods
 	// are closed by the call to Commit or Rollback.
@@ -266,21 +392,7 @@ func (b *PgTxRepository) Count(ctx context.Context, filter interface{}) (int64,
 }
 
 func (b *PgTxRepository) ExtractColumnPairs(data interface{}) ([]string, []interface{}) {
-	// create type mapper
-	valueMap := b.mapper.FieldMap(reflect.ValueOf(data))
-
-	// Extract columns
-	var columns = make([]string, len(valueMap))
-	var values = make([]interface{}, len(valueMap))
-	i := 0
-	for column, value := range valueMap {
-		columns[i] = column
-		values[i] = value.Interface()
-		i++
-	}
-
-	// Return all elements
-	return columns, values
+	return extractColumnPairs(b.mapper, data)
 }
 
 func (b *PgTxRepository) GetTxConn() *sqlx.Tx {
@@ -0,0 +1,33 @@
+package postgres
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger"
+)
+
+// DefaultSlowQueryThreshold is used when the configuration doesn't inform one
+const DefaultSlowQueryThreshold = 200 * time.Millisecond
+
+// slowQueryThreshold is shared by all repositories, since they are created from the connection only
+var slowQueryThreshold = int64(DefaultSlowQueryThreshold)
+
+// SetSlowQueryThreshold changes the duration from which queries are logged as slow
+func SetSlowQueryThreshold(threshold time.Duration) {
+	atomic.StoreInt64(&slowQueryThreshold, int64(threshold))
+}
+
+// logSlowQuery logs the query through the logger of the context when it took longer than the threshold
+func logSlowQuery(ctx context.Context, table string, query string, start time.Time) {
+	elapsed := time.Since(start)
+	if elapsed < time.Duration(atomic.LoadInt64(&slowQueryThreshold)) {
+		return
+	}
+
+	logger.FromContext(ctx).Warn("Slow query",
+		logger.String("table", table),
+		logger.String("query", query),
+		logger.Duration("duration", elapsed))
+}
@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger"
+)
+
+func TestLogSlowQuery(t *testing.T) {
+	defer SetSlowQueryThreshold(DefaultSlowQueryThreshold)
+
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		elapsed   time.Duration
+		wantLog   bool
+	}{
+		{name: "fast query", threshold: time.Second, elapsed: 0, wantLog: false},
+		{name: "slow query", threshold: 10 * time.Millisecond, elapsed: 20 * time.Millisecond, wantLog: true},
+		{name: "every query without threshold", threshold: 0, elapsed: 0, wantLog: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetSlowQueryThreshold(tt.threshold)
+			recorder := logger.NewRecorder()
+			ctx := logger.IntoContext(context.Background(), recorder)
+
+			logSlowQuery(ctx, "post", "SELECT * FROM post", time.Now().Add(-tt.elapsed))
+
+			entries := recorder.Find("Slow query")
+			if (len(entries) == 1) != tt.wantLog {
+				t.Fatalf("logged entries = %d, want logged %v", len(entries), tt.wantLog)
+			}
+			if !tt.wantLog {
+				return
+			}
+
+			entry := entries[0]
+			if entry.Level != logger.LevelWarn {
+				t.Errorf("level = %s, want %s", entry.Level, logger.LevelWarn)
+			}
+			if table, _ := entry.Field("table"); table != "post" {
+				t.Errorf("field table = %v, want post", table)
+			}
+			if query, _ := entry.Field("query"); query != "SELECT * FROM post" {
+				t.Errorf("field query = %v, want the query", query)
+			}
+			if duration, _ := entry.Field("duration"); duration.(time.Duration) < tt.elapsed {
+				t.Errorf("field duration = %v, want at least %v", duration, tt.elapsed)
+			}
+		})
+	}
+}
+
+func TestRepositoryLogsSlowQueries(t *testing.T) {
+	defer SetSlowQueryThreshold(DefaultSlowQueryThreshold)
+	SetSlowQueryThreshold(10 * time.Millisecond)
+
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unable to create mocked database: %v", err)
+	}
+	defer conn.Close()
+	repository := NewRepository("post", sqlx.NewDb(conn, "postgres"))
+
+	recorder := logger.NewRecorder()
+	ctx := logger.IntoContext(context.Background(), recorder)
+
+	mock.ExpectPrepare(`SELECT count\(\*\) as count FROM post`).
+		ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectPrepare(`SELECT count\(\*\) as count FROM post`).
+		ExpectQuery().WillDelayFor(20 * time.Millisecond).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	for i := 0; i < 2; i++ {
+		if _, err := repository.Count(ctx, nil); err != nil {
+			t.Fatalf("Count() error = %v", err)
+		}
+	}
+
+	// only the delayed query is logged
+	if entries := recorder.Find("Slow query"); len(entries) != 1 {
+		t.Errorf("logged slow queries = %d, want 1", len(entries))
+	}
+}
@@ -0,0 +1,99 @@
+package environment
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ApplyDefaults sets the fields that are still zero to the value of their "default" tag, decoded like
+// environment variables, e.g. `default:"30s"`. Structs inside pointers, slices and maps get their
+// defaults too
+func ApplyDefaults(envOutput interface{}) error {
+	return applyDefaults(reflect.ValueOf(envOutput).Elem(), "", nil)
+}
+
+// applyDefaults sets the zero fields of v, except the ones whose path is in keep
+func applyDefaults(v reflect.Value, path string, keep map[string]bool) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			return applyDefaults(v.Elem(), path, keep)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := applyDefaults(v.Index(i), fmt.Sprintf("%s[%d]", path, i), keep); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		// map values aren't addressable, so they are changed on a copy
+		iter := v.MapRange()
+		for iter.Next() {
+			value := reflect.New(iter.Value().Type()).Elem()
+			value.Set(iter.Value())
+			if err := applyDefaults(value, fmt.Sprintf("%s[%v]", path, iter.Key()), keep); err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), value)
+		}
+	case reflect.Struct:
+		if isScalar(v.Type()) {
+			return nil
+		}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+
+			fieldPath := joinPath(path, field.Name)
+			if value, ok := field.Tag.Lookup("default"); ok && v.Field(i).IsZero() && !keep[fieldPath] {
+				if err := setValue(v.Field(i), value, fieldPath, nil); err != nil {
+					return fmt.Errorf("default of %s: %w", fieldPath, err)
+				}
+			}
+			if err := applyDefaults(v.Field(i), fieldPath, keep); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// applyNestedDefaults applies the defaults of the structs inside pointers, slices and maps, which are
+// created by the layers after the defaults of the other fields were applied. The fields set by the
+// layers are kept, like the top-level ones, even when they are zero
+func applyNestedDefaults(v reflect.Value, path string, set map[string]bool) error {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		return applyDefaults(v, path, set)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := applyNestedDefaults(v.Index(i), fmt.Sprintf("%s[%d]", path, i), set); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		if isScalar(v.Type()) {
+			return nil
+		}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			if err := applyNestedDefaults(v.Field(i), joinPath(path, field.Name), set); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// joinPath returns the path of the field inside the struct at path
+func joinPath(path string, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
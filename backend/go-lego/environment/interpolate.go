@@ -0,0 +1,192 @@
+package environment
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/secret"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrUnresolvedReference is returned when a required reference has no value
+var ErrUnresolvedReference = errors.New("unresolved configuration reference")
+
+// reference matches ${ENV:NAME} and ${FILE:/path}, optionally followed by :-default or :?message.
+// $${...} is kept as the literal ${...}
+var reference = regexp.MustCompile(`\$(\$?)\{(ENV|FILE):([^}]*)\}`)
+
+// interpolate replaces the references in the scalar values of the document:
+//   - ${ENV:NAME} is the value of the environment variable, empty when it isn't defined
+//   - ${FILE:/path} is the content of the file without the trailing newline, e.g. a mounted secret.
+//     Files are secrets, their values are registered with secret.Register
+//   - ${ENV:NAME:-default} and ${FILE:/path:-default} use the default when there is no value
+//   - ${ENV:NAME:?message} and ${FILE:/path:?message} fail with the message when there is no value
+//
+// Keys are never interpolated. The values substituted into every node are added to resolved, so the
+// ones of secret fields can be registered. When nulled isn't nil, the values with required references
+// that can't be resolved become null instead of failing, and are added to it
+func interpolate(node *yaml.Node, resolved map[*yaml.Node][]string, nulled map[*yaml.Node]bool) error {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			if err := interpolate(child, resolved, nulled); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := interpolate(node.Content[i], resolved, nulled); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		if !strings.Contains(node.Value, "${") {
+			return nil
+		}
+		value, values, err := interpolateString(node.Value)
+		if nulled != nil && errors.Is(err, ErrUnresolvedReference) {
+			node.Value, node.Tag, node.Style = "", "!!null", 0
+			nulled[node] = true
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		node.Value = value
+		if len(values) > 0 {
+			resolved[node] = values
+		}
+		if node.Style == 0 {
+			// plain values are resolved again, so a reference can be decoded into a number or duration
+			node.Tag = ""
+		}
+	}
+	return nil
+}
+
+// interpolateString returns the string with its references replaced and the values substituted for them
+func interpolateString(in string) (string, []string, error) {
+	var values []string
+	var err error
+	out := reference.ReplaceAllStringFunc(in, func(match string) string {
+		groups := reference.FindStringSubmatch(match)
+		if groups[1] != "" {
+			return match[1:]
+		}
+
+		value, resolveErr := resolve(groups[2], groups[3])
+		if resolveErr != nil && err == nil {
+			err = resolveErr
+		}
+		if value != "" {
+			values = append(values, value)
+		}
+		return value
+	})
+	return out, values, err
+}
+
+// resolve returns the value of the reference, applying its default or required modifier
+func resolve(kind string, expression string) (string, error) {
+	name, modifier, fallback := expression, "", ""
+	if i := strings.Index(expression, ":-"); i >= 0 {
+		name, modifier, fallback = expression[:i], ":-", expression[i+2:]
+	} else if i := strings.Index(expression, ":?"); i >= 0 {
+		name, modifier, fallback = expression[:i], ":?", expression[i+2:]
+	}
+
+	var value string
+	var found bool
+	switch kind {
+	case "ENV":
+		value, found = os.LookupEnv(name)
+		found = found && value != ""
+	case "FILE":
+		content, err := os.ReadFile(filepath.Clean(name))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("${FILE:%s}: %w", name, err)
+		}
+		value = strings.TrimRight(string(content), "\r\n")
+		found = err == nil
+		if found {
+			secret.Register(value)
+		}
+	}
+	if found {
+		return value, nil
+	}
+
+	switch modifier {
+	case ":-":
+		return fallback, nil
+	case ":?":
+		if fallback == "" {
+			fallback = "value is required"
+		}
+		return "", fmt.Errorf("%w ${%s:%s}: %s", ErrUnresolvedReference, kind, name, fallback)
+	default:
+		return "", nil
+	}
+}
+
+// registerSecrets registers the values of the fields tagged with `secret:"true"`, whichever layer they
+// came from
+func registerSecrets(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			registerSecrets(v.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			registerSecrets(v.Index(i))
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			registerSecrets(iter.Value())
+		}
+	case reflect.Struct:
+		if isScalar(v.Type()) {
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			if field.Tag.Get("secret") == "true" {
+				registerStrings(v.Field(i))
+				continue
+			}
+			registerSecrets(v.Field(i))
+		}
+	}
+}
+
+// registerStrings registers every string of a field tagged with `secret:"true"`, such as the values of a
+// map of headers
+func registerStrings(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.String:
+		secret.Register(v.String())
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			registerStrings(v.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			registerStrings(v.Index(i))
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			registerStrings(iter.Value())
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package environment
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestInterpolateString(t *testing.T) {
+	t.Setenv("BLOG_TEST_HOST", "db.local")
+	t.Setenv("BLOG_TEST_EMPTY", "")
+	dir := writeFile(t, t.TempDir(), "password", "s3cret\n")
+	file := filepath.Join(dir, "password")
+	missing := filepath.Join(dir, "missing")
+
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr error
+	}{
+		{name: "no reference", in: "plain", want: "plain"},
+		{name: "environment variable", in: "${ENV:BLOG_TEST_HOST}", want: "db.local"},
+		{name: "inside a value", in: "postgres://${ENV:BLOG_TEST_HOST}:5432", want: "postgres://db.local:5432"},
+		{name: "undefined variable", in: "${ENV:BLOG_TEST_UNDEFINED}", want: ""},
+		{name: "default of an undefined variable", in: "${ENV:BLOG_TEST_UNDEFINED:-localhost}", want: "localhost"},
+		{name: "default of an empty variable", in: "${ENV:BLOG_TEST_EMPTY:-localhost}", want: "localhost"},
+		{name: "default not used", in: "${ENV:BLOG_TEST_HOST:-localhost}", want: "db.local"},
+		{name: "required variable", in: "${ENV:BLOG_TEST_HOST:?host is required}", want: "db.local"},
+		{
+			name:    "missing required variable",
+			in:      "${ENV:BLOG_TEST_UNDEFINED:?host is required}",
+			wantErr: ErrUnresolvedReference,
+		},
+		{name: "file without trailing newline", in: "${FILE:" + file + "}", want: "s3cret"},
+		{name: "default of a missing file", in: "${FILE:" + missing + ":-none}", want: "none"},
+		{name: "missing required file", in: "${FILE:" + missing + ":?}", wantErr: ErrUnresolvedReference},
+		{name: "escaped reference", in: "$${ENV:BLOG_TEST_HOST}", want: "${ENV:BLOG_TEST_HOST}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := interpolateString(tt.in)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("interpolateString(%q) error = %v, want %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("interpolateString(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
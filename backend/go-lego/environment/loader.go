@@ -0,0 +1,225 @@
+package environment
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidOverride is returned when an override isn't written as <path>=<value> or its path doesn't exist
+var ErrInvalidOverride = errors.New("invalid configuration override")
+
+// Loader loads the configuration in layers, each one overriding the values of the previous:
+//   - the values already set on the output, used as defaults
+//   - the value of the "default" tag of the fields that are still zero (see ApplyDefaults)
+//   - the YAML file env.<name>.yaml
+//   - the optional YAML file env.<name>.local.yaml, for values that aren't committed
+//   - the environment variables named <EnvPrefix>_<FIELD>_<FIELD>, e.g. BLOG_SERVER_HTTP_LISTENADDR
+//   - the overrides, usually given as CLI flags
+//
+// Since the defaults come first, a zero value set by a layer is kept, e.g. a cache TTL of 0s. Structs
+// inside pointers, slices and maps only exist once a layer sets them, so they get their defaults at the
+// end, on the fields that no layer set. The values of the fields tagged with `secret:"true"` are
+// registered with secret.Register, so they are never logged.
+//
+// Environment variables and overrides address struct fields by name, case-insensitive. Strings are
+// used as is, other values are decoded as YAML, e.g. "30s" or "[10.0.0.0/8, 127.0.0.1]"
+type Loader struct {
+	// Path is the directory with the env.<name>.yaml files
+	Path string
+
+	// Name of the environment
+	Name string
+
+	// EnvPrefix of the environment variables, they are ignored when it is empty
+	EnvPrefix string
+
+	// Overrides are written as <path>=<value>, e.g. Server.HTTP.ListenAddr=:9090
+	Overrides []string
+}
+
+// ResolvePath returns the directory with the configuration files. Relative paths that don't exist in the
+// working directory are looked up next to the executable, so the binary can run from anywhere
+func ResolvePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return path
+	}
+	nextToExecutable := filepath.Join(filepath.Dir(executable), path)
+	if _, err := os.Stat(nextToExecutable); err == nil {
+		return nextToExecutable
+	}
+	return path
+}
+
+// Load loads all layers into the "envOutput" parameter
+func (l Loader) Load(envOutput interface{}) error {
+	return l.load(envOutput, nil)
+}
+
+// LoadStructure loads all layers like Load, except that the required references of the YAML files without
+// a value don't fail: their fields keep the values of the previous layers, and their paths are returned in
+// the format of FieldError, e.g. Auth.AccessTokenSecret. It checks the files where their secrets aren't
+// available, together with ValidationErrors.Except
+func (l Loader) LoadStructure(envOutput interface{}) (unresolved []string, err error) {
+	unresolved = []string{}
+	err = l.load(envOutput, &unresolved)
+	return unresolved, err
+}
+
+func (l Loader) load(envOutput interface{}, unresolved *[]string) error {
+	if err := ApplyDefaults(envOutput); err != nil {
+		return err
+	}
+	track := &tracking{unresolved: unresolved, set: map[string]bool{}}
+
+	configFilePath, _ := filepath.Abs(filepath.Join(l.Path, fmt.Sprintf("env.%s.yaml", l.Name)))
+	if err := decodeYAMLFile(configFilePath, envOutput, track); err != nil {
+		return err
+	}
+
+	err := decodeYAMLFile(filepath.Join(l.Path, fmt.Sprintf("env.%s.local.yaml", l.Name)), envOutput, track)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	out := reflect.ValueOf(envOutput).Elem()
+	if l.EnvPrefix != "" {
+		if err := setFromEnv(out, strings.ToUpper(l.EnvPrefix), "", track); err != nil {
+			return err
+		}
+	}
+
+	for _, override := range l.Overrides {
+		parts := strings.SplitN(override, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return fmt.Errorf("%w: %q", ErrInvalidOverride, override)
+		}
+		if err := setPath(out, strings.Split(parts[0], "."), "", parts[1], track); err != nil {
+			return fmt.Errorf("%s: %w", parts[0], err)
+		}
+	}
+
+	if err := applyNestedDefaults(out, "", track.set); err != nil {
+		return err
+	}
+
+	registerSecrets(out)
+	return nil
+}
+
+// Names returns the names of the environments with an env.<name>.yaml file in the directory
+func Names(path string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(path, "env.*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(file), "env."), ".yaml")
+		if !strings.HasSuffix(name, ".local") {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// setFromEnv sets every field whose environment variable is defined. Maps and structs inside slices
+// can only be set as a whole. The path is the one of the field v
+func setFromEnv(v reflect.Value, name string, path string, track *tracking) error {
+	if value, ok := os.LookupEnv(name); ok {
+		if err := setValue(v, value, path, track); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		return nil
+	}
+
+	switch {
+	case v.Kind() == reflect.Struct && !isScalar(v.Type()):
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			err := setFromEnv(v.Field(i), name+"_"+strings.ToUpper(field.Name), joinPath(path, field.Name), track)
+			if err != nil {
+				return err
+			}
+		}
+	case v.Kind() == reflect.Ptr && v.Type().Elem().Kind() == reflect.Struct && !isScalar(v.Type().Elem()):
+		// the struct is only allocated when one of its fields is set
+		elem := reflect.New(v.Type().Elem())
+		if !v.IsNil() {
+			elem.Elem().Set(v.Elem())
+		}
+		if err := setFromEnv(elem.Elem(), name, path, track); err != nil {
+			return err
+		}
+		if !v.IsNil() || !elem.Elem().IsZero() {
+			v.Set(elem)
+		}
+	}
+	return nil
+}
+
+// setPath sets the field found following the path of field names, from the field v at fieldPath
+func setPath(v reflect.Value, path []string, fieldPath string, value string, track *tracking) error {
+	if len(path) == 0 {
+		return setValue(v, value, fieldPath, track)
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setPath(v.Elem(), path, fieldPath, value, track)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath == "" && strings.EqualFold(field.Name, path[0]) {
+				return setPath(v.Field(i), path[1:], joinPath(fieldPath, field.Name), value, track)
+			}
+		}
+	}
+	return ErrInvalidOverride
+}
+
+// setValue sets strings as they are and decodes any other type from YAML, with case-insensitive keys. The
+// path is the one of the field v, added to the fields set by the layers when track isn't nil
+func setValue(v reflect.Value, value string, path string, track *tracking) error {
+	if track != nil && track.set != nil {
+		track.set[path] = true
+	}
+	if v.Kind() == reflect.String {
+		v.SetString(value)
+		return nil
+	}
+
+	decoded := reflect.New(v.Type())
+	if err := decodeYAML([]byte(value), decoded.Interface(), path, track); err != nil {
+		return err
+	}
+	v.Set(decoded.Elem())
+	return nil
+}
+
+// isScalar tells whether the struct is decoded as a single value, e.g. time.Time
+func isScalar(t reflect.Type) bool {
+	return t == reflect.TypeOf(time.Time{}) ||
+		reflect.PtrTo(t).Implements(reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem())
+}
@@ -0,0 +1,329 @@
+package environment
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/secret"
+)
+
+// writeFile writes the file into the directory and returns the directory
+func writeFile(t *testing.T, dir string, name string, content string) string {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+		t.Fatalf("unable to write %s: %v", name, err)
+	}
+	return dir
+}
+
+func TestLoaderDefaults(t *testing.T) {
+	type limit struct {
+		Period time.Duration `default:"1m"`
+	}
+	type config struct {
+		CacheTTL time.Duration `default:"5s"`
+		Timeout  time.Duration `default:"2s"`
+		Limits   map[string]limit
+		Sampling *limit
+		Outputs  []limit
+	}
+
+	tests := []struct {
+		name      string
+		yaml      string
+		env       map[string]string
+		overrides []string
+		want      config
+	}{
+		{
+			name: "missing values get their default",
+			yaml: "",
+			want: config{CacheTTL: 5 * time.Second, Timeout: 2 * time.Second},
+		},
+		{
+			name: "explicit zero values are kept",
+			yaml: "cacheTTL: 0s\n",
+			want: config{CacheTTL: 0, Timeout: 2 * time.Second},
+		},
+		{
+			name: "structs inside maps get their defaults",
+			yaml: "limits:\n  api: {}\n",
+			want: config{
+				CacheTTL: 5 * time.Second,
+				Timeout:  2 * time.Second,
+				Limits:   map[string]limit{"api": {Period: time.Minute}},
+			},
+		},
+		{
+			name: "explicit zero values inside maps, pointers and slices are kept",
+			yaml: "limits:\n  api: {period: 0s}\n  feeds: {}\nsampling: {period: 0s}\noutputs: [{period: 0s}, {}]\n",
+			want: config{
+				CacheTTL: 5 * time.Second,
+				Timeout:  2 * time.Second,
+				Limits:   map[string]limit{"api": {Period: 0}, "feeds": {Period: time.Minute}},
+				Sampling: &limit{Period: 0},
+				Outputs:  []limit{{Period: 0}, {Period: time.Minute}},
+			},
+		},
+		{
+			name: "explicit zero values of environment variables inside maps are kept",
+			env:  map[string]string{"TEST_LIMITS": "{api: {period: 0s}, feeds: {}}"},
+			want: config{
+				CacheTTL: 5 * time.Second,
+				Timeout:  2 * time.Second,
+				Limits:   map[string]limit{"api": {Period: 0}, "feeds": {Period: time.Minute}},
+			},
+		},
+		{
+			name:      "explicit zero values of overrides inside pointers are kept",
+			overrides: []string{"Sampling.Period=0s"},
+			want:      config{CacheTTL: 5 * time.Second, Timeout: 2 * time.Second, Sampling: &limit{Period: 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFile(t, t.TempDir(), "env.test.yaml", tt.yaml)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			var got config
+			loader := Loader{Path: dir, Name: "test", EnvPrefix: "test", Overrides: tt.overrides}
+			if err := loader.Load(&got); err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Load() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoaderLayers(t *testing.T) {
+	type config struct {
+		Name   string
+		Server struct {
+			ListenAddr string
+			Timeout    time.Duration
+		}
+		Tags []string
+	}
+
+	tests := []struct {
+		name      string
+		yaml      string
+		local     string
+		env       map[string]string
+		overrides []string
+		want      func(c *config)
+	}{
+		{
+			name: "keys match the fields case-insensitively",
+			yaml: "NAME: blog\nserver:\n  listenaddr: :8080\n  TimeOut: 5s\n",
+			want: func(c *config) {
+				c.Name = "blog"
+				c.Server.ListenAddr = ":8080"
+				c.Server.Timeout = 5 * time.Second
+			},
+		},
+		{
+			name:  "local file overrides the environment file",
+			yaml:  "name: blog\nserver: {listenAddr: ':8080'}\n",
+			local: "server: {listenAddr: ':8081'}\n",
+			want: func(c *config) {
+				c.Name = "blog"
+				c.Server.ListenAddr = ":8081"
+			},
+		},
+		{
+			name: "environment variables override the files",
+			yaml: "name: blog\nserver: {listenAddr: ':8080'}\n",
+			env:  map[string]string{"TEST_SERVER_LISTENADDR": ":9000", "TEST_TAGS": "[a, b]"},
+			want: func(c *config) {
+				c.Name = "blog"
+				c.Server.ListenAddr = ":9000"
+				c.Tags = []string{"a", "b"}
+			},
+		},
+		{
+			name:      "overrides win over everything",
+			yaml:      "server: {listenAddr: ':8080'}\n",
+			env:       map[string]string{"TEST_SERVER_LISTENADDR": ":9000"},
+			overrides: []string{"server.listenaddr=:9999", "Server.Timeout=1m"},
+			want: func(c *config) {
+				c.Server.ListenAddr = ":9999"
+				c.Server.Timeout = time.Minute
+			},
+		},
+		{
+			name: "interpolated values are decoded like written ones",
+			yaml: "server: {timeout: '${ENV:TEST_TIMEOUT}'}\nname: ${ENV:TEST_TIMEOUT}\n",
+			env:  map[string]string{"TEST_TIMEOUT": "30s"},
+			want: func(c *config) {
+				c.Name = "30s"
+				c.Server.Timeout = 30 * time.Second
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFile(t, t.TempDir(), "env.test.yaml", tt.yaml)
+			if tt.local != "" {
+				writeFile(t, dir, "env.test.local.yaml", tt.local)
+			}
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			var got config
+			loader := Loader{Path: dir, Name: "test", EnvPrefix: "test", Overrides: tt.overrides}
+			if err := loader.Load(&got); err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			var want config
+			tt.want(&want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Load() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestLoaderInvalidOverride(t *testing.T) {
+	type config struct {
+		Name string
+	}
+
+	for _, override := range []string{"name", "=blog", "missing.field=value"} {
+		t.Run(override, func(t *testing.T) {
+			dir := writeFile(t, t.TempDir(), "env.test.yaml", "")
+
+			var got config
+			err := (Loader{Path: dir, Name: "test", Overrides: []string{override}}).Load(&got)
+			if !errors.Is(err, ErrInvalidOverride) {
+				t.Errorf("Load() error = %v, want %v", err, ErrInvalidOverride)
+			}
+		})
+	}
+}
+
+func TestLoaderRegistersSecrets(t *testing.T) {
+	type config struct {
+		DSN     string            `secret:"true"`
+		Headers map[string]string `secret:"true"`
+		Token   string            `secret:"true"`
+		Host    string
+	}
+
+	t.Setenv("TEST_DB_PASSWORD", "loader-db-password")
+	t.Setenv("TEST_API_KEY", "loader-api-key")
+	t.Setenv("TEST_HOST", "loader-db-host")
+	t.Setenv("TEST_TOKEN", "loader-env-token")
+
+	yaml := `dsn: postgres://blog:${ENV:TEST_DB_PASSWORD}@${ENV:TEST_HOST}:5432/blog
+headers: {Authorization: "Bearer ${ENV:TEST_API_KEY}"}
+host: ${ENV:TEST_HOST}
+`
+	dir := writeFile(t, t.TempDir(), "env.test.yaml", yaml)
+
+	var got config
+	if err := (Loader{Path: dir, Name: "test", EnvPrefix: "test"}).Load(&got); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		// the whole values of the secret fields
+		{value: got.DSN, want: secret.Redacted},
+		{value: got.Headers["Authorization"], want: secret.Redacted},
+		{value: got.Token, want: secret.Redacted},
+		// the values substituted into them, wherever they are written
+		{value: "password=loader-db-password", want: "password=" + secret.Redacted},
+		{value: "key loader-api-key", want: "key " + secret.Redacted},
+		// a substituted value is registered since it is inside a secret field, even if it is used elsewhere
+		{value: "host loader-db-host", want: "host " + secret.Redacted},
+	}
+	for _, tt := range tests {
+		if got := secret.Redact(tt.value); got != tt.want {
+			t.Errorf("secret.Redact(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLoaderDoesNotRegisterOtherValues(t *testing.T) {
+	type config struct {
+		Name string
+		Tags []string
+	}
+
+	t.Setenv("TEST_NAME", "loader-public-name")
+	dir := writeFile(t, t.TempDir(), "env.test.yaml", "name: ${ENV:TEST_NAME}\ntags: ['${ENV:TEST_NAME}-tag']\n")
+
+	var got config
+	if err := (Loader{Path: dir, Name: "test"}).Load(&got); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	for _, value := range append([]string{got.Name}, got.Tags...) {
+		if redacted := secret.Redact(value); redacted != value {
+			t.Errorf("secret.Redact(%q) = %q, want the value unchanged", value, redacted)
+		}
+	}
+}
+
+func TestLoaderLoadStructure(t *testing.T) {
+	type config struct {
+		Name    string `default:"blog"`
+		Timeout time.Duration
+		Auth    struct {
+			Secret string `validate:"required,min=32"`
+		}
+		Groups map[string]struct{ Token string }
+		Hosts  []string
+	}
+
+	yaml := `name: ${ENV:TEST_NAME:?name is required}
+timeout: ${ENV:TEST_TIMEOUT:?}
+auth:
+  secret: ${FILE:/run/secrets/missing:?the secret must be mounted}
+groups:
+  api: {token: "${ENV:TEST_TOKEN:?}"}
+hosts: [localhost, "${ENV:TEST_HOST:?}"]
+`
+	dir := writeFile(t, t.TempDir(), "env.test.yaml", yaml)
+	loader := Loader{Path: dir, Name: "test"}
+
+	var strict config
+	if err := loader.Load(&strict); !errors.Is(err, ErrUnresolvedReference) {
+		t.Fatalf("Load() error = %v, want %v", err, ErrUnresolvedReference)
+	}
+
+	var got config
+	unresolved, err := loader.LoadStructure(&got)
+	if err != nil {
+		t.Fatalf("LoadStructure() error = %v", err)
+	}
+
+	wantUnresolved := []string{"Name", "Timeout", "Auth.Secret", "Groups[api].Token", "Hosts[1]"}
+	if !reflect.DeepEqual(unresolved, wantUnresolved) {
+		t.Errorf("LoadStructure() unresolved = %v, want %v", unresolved, wantUnresolved)
+	}
+	// the unresolved fields keep their defaults
+	if got.Name != "blog" || got.Timeout != 0 || got.Auth.Secret != "" || got.Hosts[0] != "localhost" {
+		t.Errorf("LoadStructure() = %+v, want the defaults of the unresolved fields", got)
+	}
+
+	var errs ValidationErrors
+	if err := Validate(&got); !errors.As(err, &errs) || len(errs.Except(unresolved)) != 0 {
+		t.Errorf("Validate() errors except the unresolved = %v, want none", errs.Except(unresolved))
+	}
+}
@@ -0,0 +1,100 @@
+package environment
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/secret"
+	"gopkg.in/yaml.v3"
+)
+
+// WriteYAML writes the configuration as YAML with the names of the struct fields, so it can be read
+// back by the Loader. The values of the fields tagged with `secret:"true"` and the registered secrets
+// found in any other value are replaced by secret.Redacted
+func WriteYAML(w io.Writer, envOutput interface{}) error {
+	node, err := toNode(reflect.ValueOf(envOutput))
+	if err != nil {
+		return err
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// toNode converts the value into a YAML node, keeping the order of the struct fields
+func toNode(v reflect.Value) (*yaml.Node, error) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+		}
+		return toNode(v.Elem())
+	case reflect.Struct:
+		if isScalar(v.Type()) {
+			break
+		}
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+
+			value, err := toNode(v.Field(i))
+			if err != nil {
+				return nil, err
+			}
+			if field.Tag.Get("secret") == "true" && !v.Field(i).IsZero() {
+				value = &yaml.Node{Kind: yaml.ScalarNode, Value: secret.Redacted}
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: field.Name}, value)
+		}
+		return node, nil
+	case reflect.Map:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, key := range keys {
+			keyNode, err := toNode(key)
+			if err != nil {
+				return nil, err
+			}
+			value, err := toNode(v.MapIndex(key))
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, keyNode, value)
+		}
+		return node, nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for i := 0; i < v.Len(); i++ {
+			value, err := toNode(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, value)
+		}
+		return node, nil
+	}
+
+	node := &yaml.Node{}
+	if err := node.Encode(v.Interface()); err != nil {
+		return nil, err
+	}
+	if node.Kind == yaml.ScalarNode {
+		node.Value = secret.Redact(node.Value)
+	}
+	return node, nil
+}
@@ -0,0 +1,64 @@
+package environment
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/secret"
+)
+
+func TestWriteYAML(t *testing.T) {
+	type config struct {
+		Name     string
+		Timeout  time.Duration
+		Password string `secret:"true"`
+		Empty    string `secret:"true"`
+		DSN      string
+		Headers  map[string]string
+		Hosts    []string
+		Missing  *struct{ Value int }
+	}
+
+	secret.Register("registered-s3cret")
+	in := config{
+		Name:     "blog",
+		Timeout:  30 * time.Second,
+		Password: "tagged-s3cret",
+		DSN:      "postgres://blog:registered-s3cret@db/blog",
+		Headers:  map[string]string{"b": "2", "a": "1"},
+		Hosts:    []string{"10.0.0.0/8"},
+	}
+
+	var out bytes.Buffer
+	if err := WriteYAML(&out, &in); err != nil {
+		t.Fatalf("WriteYAML() error = %v", err)
+	}
+
+	want := `Name: blog
+Timeout: 30s
+Password: '[REDACTED]'
+Empty: ""
+DSN: postgres://blog:[REDACTED]@db/blog
+Headers:
+  a: "1"
+  b: "2"
+Hosts:
+  - 10.0.0.0/8
+Missing: null
+`
+	if out.String() != want {
+		t.Errorf("WriteYAML() =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	// the dump is read back by the loader, with the secrets replaced
+	dir := writeFile(t, t.TempDir(), "env.dump.yaml", out.String())
+	var read config
+	if err := (Loader{Path: dir, Name: "dump"}).Load(&read); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if read.Timeout != in.Timeout || read.Password != secret.Redacted || !strings.Contains(read.DSN, secret.Redacted) {
+		t.Errorf("Load() = %+v, want the written configuration", read)
+	}
+}
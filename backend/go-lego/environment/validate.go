@@ -0,0 +1,222 @@
+package environment
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validator is implemented by configuration structs with rules involving more than one field
+type Validator interface {
+	Validate() error
+}
+
+// FieldError is a field that doesn't follow one of its rules
+type FieldError struct {
+	// Path of the field, e.g. Server.HTTP.ListenAddr or RateLimit.Groups[auth].Requests
+	Path    string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ValidationErrors are all the fields that don't follow their rules
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("invalid configuration: %s", strings.Join(messages, "; "))
+}
+
+// Except returns the errors that aren't about the fields with the paths or the fields inside them, e.g. the
+// unresolved fields returned by Loader.LoadStructure
+func (e ValidationErrors) Except(paths []string) ValidationErrors {
+	var kept ValidationErrors
+	for _, err := range e {
+		if !within(err.Path, paths) {
+			kept = append(kept, err)
+		}
+	}
+	return kept
+}
+
+// within checks if the path is one of the paths or is inside one of them
+func within(path string, paths []string) bool {
+	for _, parent := range paths {
+		if path == parent || strings.HasPrefix(path, parent+".") || strings.HasPrefix(path, parent+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the rules of the "validate" tag of every field and calls Validate on the structs
+// implementing Validator. All errors are returned together as ValidationErrors. The rules are separated
+// by commas:
+//   - required: the value isn't zero
+//   - min=<n>, max=<n>: limits of numbers and durations, or of the length of strings, slices and maps
+//   - oneof=<a> <b>: the string is one of the values, case-insensitive
+//   - prefix=<p>: the string starts with the prefix
+//   - url: the string is an absolute URL
+//
+// Except for required, rules aren't checked on zero values
+func Validate(envOutput interface{}) error {
+	var errs ValidationErrors
+	validate(reflect.ValueOf(envOutput), "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validate(v reflect.Value, path string, errs *ValidationErrors) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			validate(v.Elem(), path, errs)
+		}
+		return
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			validate(v.Index(i), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+		return
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			validate(iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key()), errs)
+		}
+		return
+	case reflect.Struct:
+	default:
+		return
+	}
+	if isScalar(v.Type()) {
+		return
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		fieldPath := joinPath(path, field.Name)
+		if rules := field.Tag.Get("validate"); rules != "" {
+			for _, rule := range strings.Split(rules, ",") {
+				if message := checkRule(v.Field(i), rule); message != "" {
+					*errs = append(*errs, FieldError{Path: fieldPath, Message: message})
+				}
+			}
+		}
+		validate(v.Field(i), fieldPath, errs)
+	}
+
+	if validator, ok := addressable(v).Interface().(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			*errs = append(*errs, FieldError{Path: path, Message: err.Error()})
+		}
+	}
+}
+
+// addressable returns a pointer to the value, so Validate can have a pointer receiver
+func addressable(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v.Addr()
+	}
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	return ptr
+}
+
+// checkRule returns why the value doesn't follow the rule, or an empty string when it does
+func checkRule(v reflect.Value, rule string) string {
+	name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+	if name == "required" {
+		if v.IsZero() {
+			return "is required"
+		}
+		return ""
+	}
+	if v.IsZero() {
+		return ""
+	}
+	// rules of optional values apply to the value they point to
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	switch name {
+	case "min", "max":
+		actual, limit, err := measure(v, arg)
+		if err != nil {
+			return fmt.Sprintf("invalid rule %q: %s", rule, err)
+		}
+		unit := ""
+		switch v.Kind() {
+		case reflect.String:
+			unit = " characters long"
+		case reflect.Slice, reflect.Map:
+			unit = " items long"
+		}
+		if name == "min" && actual < limit {
+			return fmt.Sprintf("must be at least %s%s", arg, unit)
+		}
+		if name == "max" && actual > limit {
+			return fmt.Sprintf("must be at most %s%s", arg, unit)
+		}
+	case "oneof":
+		for _, allowed := range strings.Fields(arg) {
+			if strings.EqualFold(v.String(), allowed) {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of %s", strings.Join(strings.Fields(arg), ", "))
+	case "prefix":
+		if !strings.HasPrefix(v.String(), arg) {
+			return fmt.Sprintf("must start with %q", arg)
+		}
+	case "url":
+		u, err := url.Parse(v.String())
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return "must be an absolute URL"
+		}
+	default:
+		return fmt.Sprintf("unknown rule %q", rule)
+	}
+	return ""
+}
+
+// measure returns the number compared by min and max, and the limit of the rule
+func measure(v reflect.Value, arg string) (float64, float64, error) {
+	switch {
+	case v.Type() == reflect.TypeOf(time.Duration(0)):
+		limit, err := time.ParseDuration(arg)
+		return float64(v.Int()), float64(limit), err
+	case v.Kind() == reflect.String, v.Kind() == reflect.Slice, v.Kind() == reflect.Map:
+		limit, err := strconv.ParseFloat(arg, 64)
+		return float64(v.Len()), limit, err
+	case v.CanInt():
+		limit, err := strconv.ParseFloat(arg, 64)
+		return float64(v.Int()), limit, err
+	case v.CanUint():
+		limit, err := strconv.ParseFloat(arg, 64)
+		return float64(v.Uint()), limit, err
+	case v.CanFloat():
+		limit, err := strconv.ParseFloat(arg, 64)
+		return v.Float(), limit, err
+	default:
+		return 0, 0, fmt.Errorf("%s can't be measured", v.Type())
+	}
+}
@@ -0,0 +1,189 @@
+package environment
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// rules has a field for every validation rule
+type rules struct {
+	Name     string        `validate:"required"`
+	Port     int           `validate:"min=1,max=65535"`
+	Timeout  time.Duration `validate:"min=1ms"`
+	Secret   string        `validate:"min=8"`
+	Hosts    []string      `validate:"max=2"`
+	Level    string        `validate:"oneof=debug info"`
+	Endpoint string        `validate:"prefix=/"`
+	BaseURL  string        `validate:"url"`
+	Optional *int          `validate:"min=1"`
+	Groups   map[string]limits
+}
+
+type limits struct {
+	Requests int `validate:"min=0"`
+	Period   time.Duration
+}
+
+// Validate requires the period of the groups with requests
+func (l *limits) Validate() error {
+	if l.Requests > 0 && l.Period == 0 {
+		return errors.New("period is required with requests")
+	}
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	zero := 0
+
+	tests := []struct {
+		name   string
+		change func(r *rules)
+		want   ValidationErrors
+	}{
+		{name: "valid", change: func(r *rules) {}},
+		{
+			name:   "required",
+			change: func(r *rules) { r.Name = "" },
+			want:   ValidationErrors{{Path: "Name", Message: "is required"}},
+		},
+		{
+			name:   "rules aren't checked on zero values",
+			change: func(r *rules) { r.Port, r.Timeout, r.Secret, r.Level, r.BaseURL = 0, 0, "", "", "" },
+		},
+		{
+			name:   "numbers",
+			change: func(r *rules) { r.Port = 70000 },
+			want:   ValidationErrors{{Path: "Port", Message: "must be at most 65535"}},
+		},
+		{
+			name:   "durations",
+			change: func(r *rules) { r.Timeout = time.Microsecond },
+			want:   ValidationErrors{{Path: "Timeout", Message: "must be at least 1ms"}},
+		},
+		{
+			name:   "length of strings",
+			change: func(r *rules) { r.Secret = "short" },
+			want:   ValidationErrors{{Path: "Secret", Message: "must be at least 8 characters long"}},
+		},
+		{
+			name:   "length of slices",
+			change: func(r *rules) { r.Hosts = []string{"a", "b", "c"} },
+			want:   ValidationErrors{{Path: "Hosts", Message: "must be at most 2 items long"}},
+		},
+		{
+			name:   "oneof is case-insensitive",
+			change: func(r *rules) { r.Level = "INFO" },
+		},
+		{
+			name:   "oneof",
+			change: func(r *rules) { r.Level = "trace" },
+			want:   ValidationErrors{{Path: "Level", Message: "must be one of debug, info"}},
+		},
+		{
+			name:   "prefix",
+			change: func(r *rules) { r.Endpoint = "health" },
+			want:   ValidationErrors{{Path: "Endpoint", Message: `must start with "/"`}},
+		},
+		{
+			name:   "url",
+			change: func(r *rules) { r.BaseURL = "blog.example.com" },
+			want:   ValidationErrors{{Path: "BaseURL", Message: "must be an absolute URL"}},
+		},
+		{
+			name:   "pointers are checked by their value",
+			change: func(r *rules) { r.Optional = &zero },
+			want:   ValidationErrors{{Path: "Optional", Message: "must be at least 1"}},
+		},
+		{
+			name: "fields and Validator inside maps",
+			change: func(r *rules) {
+				r.Groups = map[string]limits{"api": {Requests: -1}, "auth": {Requests: 1}}
+			},
+			want: ValidationErrors{
+				{Path: "Groups[api].Requests", Message: "must be at least 0"},
+				{Path: "Groups[auth]", Message: "period is required with requests"},
+			},
+		},
+		{
+			name:   "every error is returned",
+			change: func(r *rules) { r.Name, r.Port = "", -1 },
+			want: ValidationErrors{
+				{Path: "Name", Message: "is required"},
+				{Path: "Port", Message: "must be at least 1"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := rules{
+				Name: "blog", Port: 8080, Timeout: time.Second, Secret: "long enough", Level: "debug",
+				Endpoint: "/health", BaseURL: "https://blog.example.com",
+			}
+			tt.change(&r)
+
+			err := Validate(&r)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var got ValidationErrors
+			if !errors.As(err, &got) {
+				t.Fatalf("Validate() error = %v, want ValidationErrors", err)
+			}
+			// the order of the map entries isn't fixed
+			if len(got) == 2 && len(tt.want) == 2 && got[0].Path > got[1].Path {
+				got[0], got[1] = got[1], got[0]
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidationErrorsExcept(t *testing.T) {
+	errs := ValidationErrors{
+		{Path: "Auth.AccessTokenSecret"},
+		{Path: "Auth.AccessTokenSecretFile"},
+		{Path: "Database"},
+		{Path: "Groups[auth].Requests"},
+		{Path: "Hosts[0]"},
+	}
+
+	tests := []struct {
+		name  string
+		paths []string
+		want  ValidationErrors
+	}{
+		{name: "no paths", want: errs},
+		{
+			name:  "same field, not the ones sharing its prefix",
+			paths: []string{"Auth.AccessTokenSecret"},
+			want:  ValidationErrors{errs[1], errs[2], errs[3], errs[4]},
+		},
+		{
+			name:  "fields inside the paths",
+			paths: []string{"Groups", "Hosts"},
+			want:  ValidationErrors{errs[0], errs[1], errs[2]},
+		},
+		{
+			name:  "parents aren't excepted",
+			paths: []string{"Database.DSN"},
+			want:  errs,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errs.Except(tt.paths); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Except() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package environment
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger"
+)
+
+// LoadFunc loads and validates a new configuration. The configuration is rejected when it returns an error
+type LoadFunc func() (interface{}, error)
+
+// Watcher polls the configuration files and publishes a new snapshot to the subscribers every time
+// they change. Snapshots are only published after being loaded and validated, invalid changes are
+// logged and the previous snapshot stays active. Snapshots are shared by all subscribers, so they
+// must not be changed
+type Watcher struct {
+	logger logger.Logger
+	load   LoadFunc
+	files  []string
+
+	// current is read without the lock, so subscribers can call Current
+	current atomic.Value
+
+	// mu serializes reloads, so subscribers receive the snapshots in order
+	mu          sync.Mutex
+	hashes      map[string][sha256.Size]byte
+	subscribers []subscriber
+	nextID      int
+}
+
+// subscriber is a function subscribed to the snapshots, identified so it can unsubscribe
+type subscriber struct {
+	id int
+	fn func(snapshot interface{})
+}
+
+// NewWatcher creates a watcher of the files whose current snapshot is the initial configuration
+func NewWatcher(logger logger.Logger, load LoadFunc, initial interface{}, files ...string) *Watcher {
+	w := &Watcher{
+		logger: logger,
+		load:   load,
+		files:  files,
+	}
+	w.current.Store(&initial)
+	w.hashes = w.hashFiles()
+	return w
+}
+
+// Files returns the files of the configuration of the loader, including the optional local file
+func (l Loader) Files() []string {
+	return []string{
+		filepath.Join(l.Path, "env."+l.Name+".yaml"),
+		filepath.Join(l.Path, "env."+l.Name+".local.yaml"),
+	}
+}
+
+// Current returns the active snapshot
+func (w *Watcher) Current() interface{} {
+	return *w.current.Load().(*interface{})
+}
+
+// Subscribe calls fn with every new snapshot, until the returned function is called. Subscribers are
+// called one at a time, in the order they subscribed, and must apply the snapshot atomically, e.g.
+// swapping a pointer
+func (w *Watcher) Subscribe(fn func(snapshot interface{})) (unsubscribe func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	id := w.nextID
+	w.nextID++
+	w.subscribers = append(w.subscribers, subscriber{id: id, fn: fn})
+
+	return func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		for i, subscriber := range w.subscribers {
+			if subscriber.id == id {
+				w.subscribers = append(w.subscribers[:i:i], w.subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Run polls the files every interval until the context is done, reloading when any of them changes
+func (w *Watcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			hashes := w.hashFiles()
+			w.mu.Lock()
+			changed := !reflect.DeepEqual(hashes, w.hashes)
+			w.mu.Unlock()
+
+			if changed {
+				_ = w.Reload()
+			}
+		}
+	}
+}
+
+// Reload loads the configuration and publishes it when it is valid and different from the active one
+func (w *Watcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.hashes = w.hashFiles()
+	snapshot, err := w.load()
+	if err != nil {
+		w.logger.Error("Configuration change rejected, the previous configuration stays active",
+			logger.Strings("files", w.files), logger.Err(err))
+		return err
+	}
+	if reflect.DeepEqual(snapshot, w.Current()) {
+		return nil
+	}
+
+	w.current.Store(&snapshot)
+	for _, subscriber := range w.subscribers {
+		subscriber.fn(snapshot)
+	}
+	w.logger.Info("Configuration reloaded", logger.Strings("files", w.files))
+	return nil
+}
+
+// hashFiles returns the hash of the content of every file, missing files have no hash
+func (w *Watcher) hashFiles() map[string][sha256.Size]byte {
+	hashes := make(map[string][sha256.Size]byte, len(w.files))
+	for _, file := range w.files {
+		content, err := os.ReadFile(filepath.Clean(file))
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				w.logger.Warn("Unable to read configuration file", logger.String("file", file), logger.Err(err))
+			}
+			continue
+		}
+		hashes[file] = sha256.Sum256(content)
+	}
+	return hashes
+}
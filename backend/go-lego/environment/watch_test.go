@@ -0,0 +1,204 @@
+package environment
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger"
+)
+
+type watchedConfig struct {
+	Name    string `validate:"required"`
+	Timeout time.Duration
+}
+
+// newTestWatcher creates a watcher of the env.test.yaml file of the directory, with the initial content
+func newTestWatcher(t *testing.T, dir string, content string) (*Watcher, *logger.Recorder) {
+	t.Helper()
+
+	writeFile(t, dir, "env.test.yaml", content)
+	loader := Loader{Path: dir, Name: "test"}
+	load := func() (interface{}, error) {
+		config := &watchedConfig{}
+		if err := loader.Load(config); err != nil {
+			return nil, err
+		}
+		if err := Validate(config); err != nil {
+			return nil, err
+		}
+		return config, nil
+	}
+
+	initial, err := load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := logger.NewRecorder()
+	return NewWatcher(recorder, load, initial, loader.Files()...), recorder
+}
+
+// replaceFile replaces the file of the directory at once, so the watcher never reads it half written
+func replaceFile(t *testing.T, dir string, name string, content string) {
+	t.Helper()
+
+	writeFile(t, dir, name+".tmp", content)
+	if err := os.Rename(filepath.Join(dir, name+".tmp"), filepath.Join(dir, name)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// runWatcher polls the files every few milliseconds until the test ends
+func runWatcher(t *testing.T, w *Watcher) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.Run(ctx, 5*time.Millisecond)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+// waitFor waits until the condition is true
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWatcherPublishesChanges(t *testing.T) {
+	dir := t.TempDir()
+	w, _ := newTestWatcher(t, dir, "name: first\n")
+
+	published := make(chan *watchedConfig, 10)
+	w.Subscribe(func(snapshot interface{}) {
+		published <- snapshot.(*watchedConfig)
+	})
+	runWatcher(t, w)
+
+	replaceFile(t, dir, "env.test.yaml", "name: second\ntimeout: 5s\n")
+	select {
+	case snapshot := <-published:
+		if want := (&watchedConfig{Name: "second", Timeout: 5 * time.Second}); !reflect.DeepEqual(snapshot, want) {
+			t.Errorf("published snapshot = %+v, want %+v", snapshot, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the change wasn't published")
+	}
+	if current := w.Current().(*watchedConfig); current.Name != "second" {
+		t.Errorf("Current() = %+v, want the published snapshot", current)
+	}
+
+	// the optional local file is watched too
+	replaceFile(t, dir, "env.test.local.yaml", "name: local\n")
+	select {
+	case snapshot := <-published:
+		if snapshot.Name != "local" {
+			t.Errorf("published snapshot = %+v, want the local name", snapshot)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the local file wasn't published")
+	}
+}
+
+func TestWatcherRejectsInvalidSnapshots(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "invalid YAML", content: "name: [\n"},
+		{name: "invalid configuration", content: "name: ''\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			w, recorder := newTestWatcher(t, dir, "name: valid\n")
+			var calls int32
+			w.Subscribe(func(interface{}) { atomic.AddInt32(&calls, 1) })
+			runWatcher(t, w)
+
+			replaceFile(t, dir, "env.test.yaml", tt.content)
+			waitFor(t, "the rejection", func() bool {
+				return len(recorder.Find("Configuration change rejected, the previous configuration stays active")) > 0
+			})
+
+			// the file is only loaded again when it changes
+			time.Sleep(20 * time.Millisecond)
+			if got := len(recorder.Find("Configuration change rejected, the previous configuration stays active")); got != 1 {
+				t.Errorf("logged rejections = %d, want 1", got)
+			}
+			if current := w.Current().(*watchedConfig); current.Name != "valid" {
+				t.Errorf("Current() = %+v, want the previous snapshot", current)
+			}
+			if err := w.Reload(); err == nil {
+				t.Error("Reload() error = nil, want the load error")
+			}
+
+			// a fixed file is published
+			replaceFile(t, dir, "env.test.yaml", "name: fixed\n")
+			waitFor(t, "the fixed snapshot", func() bool {
+				return atomic.LoadInt32(&calls) == 1
+			})
+			if current := w.Current().(*watchedConfig); current.Name != "fixed" {
+				t.Errorf("Current() = %+v, want the fixed snapshot", current)
+			}
+		})
+	}
+}
+
+func TestWatcherSubscribers(t *testing.T) {
+	dir := t.TempDir()
+	w, _ := newTestWatcher(t, dir, "name: first\n")
+
+	var calls []string
+	subscribe := func(name string) func() {
+		return w.Subscribe(func(snapshot interface{}) {
+			calls = append(calls, name+":"+snapshot.(*watchedConfig).Name)
+		})
+	}
+	unsubscribeA := subscribe("a")
+	unsubscribeB := subscribe("b")
+	subscribe("c")
+
+	// unchanged configurations aren't published
+	if err := w.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if len(calls) != 0 {
+		t.Fatalf("calls = %v, want none for the same configuration", calls)
+	}
+
+	replaceFile(t, dir, "env.test.yaml", "name: second\n")
+	if err := w.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	unsubscribeB()
+	unsubscribeB()
+	replaceFile(t, dir, "env.test.yaml", "name: third\n")
+	_ = w.Reload()
+
+	unsubscribeA()
+	subscribe("d")
+	replaceFile(t, dir, "env.test.yaml", "name: fourth\n")
+	_ = w.Reload()
+
+	want := []string{"a:second", "b:second", "c:second", "a:third", "c:third", "c:fourth", "d:fourth"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
@@ -2,43 +2,184 @@ package environment
 
 import (
 	"fmt"
-	"io/ioutil"
+	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 
-	"gopkg.in/yaml.v2"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/secret"
+	"gopkg.in/yaml.v3"
 )
 
-// NewFromYAML loads configuration written on YAML file into the "envOutput" parameter
+// NewFromYAML loads configuration written on YAML file into the "envOutput" parameter. Keys match
+// the struct fields case-insensitively, and values can reference environment variables and files
+// with ${ENV:NAME} and ${FILE:/path} (see interpolate)
 func NewFromYAML(envConfigPath string, envName string, envOutput interface{}) error {
-	configFilePath := fmt.Sprintf("%s/env.%s.yaml", envConfigPath, envName)
+	configFilePath := filepath.Join(envConfigPath, fmt.Sprintf("env.%s.yaml", envName))
 	environmentConfigPath, _ := filepath.Abs(configFilePath)
-	environmentConfig, err := ioutil.ReadFile(filepath.Clean(environmentConfigPath))
+
+	// no errors means environment specific configuration was loaded correctly
+	return decodeYAMLFile(environmentConfigPath, envOutput, nil)
+}
+
+// decodeYAMLFile decodes the file over the values already in "envOutput". See decodeYAML for track
+func decodeYAMLFile(path string, envOutput interface{}, track *tracking) error {
+	in, err := os.ReadFile(filepath.Clean(path))
 	if err != nil {
 		return err
 	}
 
-	insensitiveUnmarshal := caseInsensitiveUnmarshal(environmentConfig)
-	err = yaml.Unmarshal(insensitiveUnmarshal, envOutput)
-	if err != nil {
+	if err := decodeYAML(in, envOutput, "", track); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// tracking collects what the layers set while they are decoded
+type tracking struct {
+	// unresolved makes the values whose required references can't be resolved null instead of failing,
+	// and gets the paths of their fields. They fail when it is nil
+	unresolved *[]string
+
+	// set are the paths of the fields written by the layers, so their zero values are kept over the
+	// defaults applied at the end (see applyNestedDefaults)
+	set map[string]bool
+}
+
+// decodeYAML decodes the document matching its keys to the struct fields case-insensitively, since
+// yaml.Unmarshal doesn't support this feature yet. Only the keys of struct fields are changed, map keys
+// and values are decoded as written. The values substituted for references inside the fields tagged with
+// `secret:"true"` are registered with secret.Register, e.g. a password within a DSN, since only the whole
+// field value would be registered otherwise. The path is the one of "out", and track may be nil
+func decodeYAML(in []byte, out interface{}, path string, track *tracking) error {
+	var document yaml.Node
+	if err := yaml.Unmarshal(in, &document); err != nil {
 		return err
 	}
+	if document.Kind == 0 {
+		// empty document
+		return nil
+	}
+	if track == nil {
+		track = &tracking{}
+	}
 
-	// no errors and environment specific configuration was loaded correctly
-	return nil
+	var nulled map[*yaml.Node]bool
+	if track.unresolved != nil {
+		nulled = map[*yaml.Node]bool{}
+	}
+	resolved := map[*yaml.Node][]string{}
+	if err := interpolate(&document, resolved, nulled); err != nil {
+		return err
+	}
+
+	n := normalizer{visited: map[visit]bool{}, resolved: resolved, nulled: nulled, set: track.set}
+	n.normalizeKeys(&document, reflect.TypeOf(out), path, false)
+	if track.unresolved != nil {
+		*track.unresolved = append(*track.unresolved, n.nulledPaths...)
+	}
+	return document.Decode(out)
+}
+
+// visit is a node already normalized for a type, so aliases are followed once
+type visit struct {
+	node *yaml.Node
+	typ  reflect.Type
 }
 
-// caseInsensitiveUnmarshal transforms all keys of yaml to lowercase
-// since yaml.Unmarshal doesn't support this feature yet
-func caseInsensitiveUnmarshal(in []byte) []byte {
-	// var lines []string
-	lines := make([]string, 0, len(in))
-	for _, line := range strings.Split(string(in), "\n") {
-		if strings.Contains(line, ":") {
-			parts := strings.SplitN(line, ":", 2)
-			line = fmt.Sprintf("%s:%s", strings.ToLower(parts[0]), parts[1])
+// normalizer walks the document following the types it is decoded into
+type normalizer struct {
+	visited map[visit]bool
+
+	// resolved are the values substituted into the nodes by interpolate
+	resolved map[*yaml.Node][]string
+
+	// nulled are the values of unresolved references, whose field paths are kept in nulledPaths
+	nulled      map[*yaml.Node]bool
+	nulledPaths []string
+
+	// set gets the paths of the struct fields with a value in the document, when it isn't nil
+	set map[string]bool
+}
+
+// normalizeKeys rewrites the keys of the mappings decoded into structs to the names expected by the decoder.
+// The path is the one of the field decoded from the node, as reported by Validate, and isSecret tells whether
+// the node is inside a field tagged with `secret:"true"`
+func (n *normalizer) normalizeKeys(node *yaml.Node, t reflect.Type, path string, isSecret bool) {
+	if n.nulled[node] {
+		n.nulledPaths = append(n.nulledPaths, path)
+	}
+	if isSecret {
+		secret.Register(n.resolved[node]...)
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if n.visited[visit{node, t}] || isScalar(t) {
+		return
+	}
+	n.visited[visit{node, t}] = true
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			n.normalizeKeys(child, t, path, isSecret)
+		}
+	case yaml.AliasNode:
+		n.normalizeKeys(node.Alias, t, path, isSecret)
+	case yaml.SequenceNode:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i, child := range node.Content {
+				n.normalizeKeys(child, t.Elem(), fmt.Sprintf("%s[%d]", path, i), isSecret)
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			switch {
+			case key.Tag == "!!merge":
+				// the merged mappings are decoded into the same type
+				n.normalizeKeys(value, t, path, isSecret)
+			case t.Kind() == reflect.Map:
+				n.normalizeKeys(value, t.Elem(), fmt.Sprintf("%s[%s]", path, key.Value), isSecret)
+			case t.Kind() == reflect.Struct:
+				if name, field, ok := findField(t, key.Value); ok {
+					fieldPath := joinPath(path, field.Name)
+					if n.set != nil && !n.nulled[value] {
+						n.set[fieldPath] = true
+					}
+					n.normalizeKeys(value, field.Type, fieldPath, isSecret || field.Tag.Get("secret") == "true")
+					key.Value = name
+				}
+			}
+		}
+	}
+}
+
+// findField returns the key expected by the decoder and the field matching the key
+func findField(t reflect.Type, key string) (string, reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if options == "inline" {
+			if name, inlined, ok := findField(field.Type, key); ok {
+				return name, inlined, true
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if strings.EqualFold(name, key) {
+			return name, field, true
 		}
-		lines = append(lines, line)
 	}
-	return []byte(strings.Join(lines, "\n"))
+	return "", reflect.StructField{}, false
 }
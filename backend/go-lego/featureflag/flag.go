@@ -0,0 +1,65 @@
+// Package featureflag evaluates feature flags per request: booleans, percentage rollouts keyed by the
+// subject of the request (usually the user id) and allowlists of subjects
+package featureflag
+
+import (
+	"hash/fnv"
+	"time"
+)
+
+// Config contains the flags defined in the configuration files
+type Config struct {
+	// Flags by name
+	Flags map[string]Flag
+
+	// DatabaseOverrides replaces the configured flags with the ones in the feature_flag table
+	DatabaseOverrides bool
+
+	// RefreshInterval is how often the overrides are read from the database
+	RefreshInterval time.Duration `default:"30s" validate:"min=1s"`
+}
+
+// Flag is the definition of a feature flag. A disabled flag is off for everyone. An enabled flag is on
+// for the subjects in the Allowlist and, when Percentage is informed, for that percentage of the other
+// subjects, or for everyone when it isn't
+type Flag struct {
+	Description string `json:"description,omitempty"`
+	Enabled     bool   `json:"enabled"`
+
+	// Percentage of the subjects for which the flag is on, from 0 to 100. Every subject always gets the
+	// same result, and increasing the percentage keeps the subjects that already had it on
+	Percentage *int `json:"percentage,omitempty" validate:"min=0,max=100"`
+
+	// Allowlist are the subjects for which the flag is always on
+	Allowlist []string `json:"allowlist,omitempty"`
+}
+
+// On evaluates the flag for the subject. Anonymous requests have an empty subject and are only part of
+// full rollouts
+func (f Flag) On(name string, subject string) bool {
+	if !f.Enabled {
+		return false
+	}
+	if subject != "" {
+		for _, allowed := range f.Allowlist {
+			if allowed == subject {
+				return true
+			}
+		}
+	}
+	if f.Percentage == nil || *f.Percentage >= 100 {
+		return true
+	}
+	if subject == "" {
+		return false
+	}
+	return bucket(name, subject) < *f.Percentage
+}
+
+// bucket places the subject in one of 100 buckets. The flag name is part of the hash, so each flag
+// is rolled out to a different set of subjects
+func bucket(name string, subject string) int {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(name + ":" + subject))
+	return int(hash.Sum32() % 100)
+}
@@ -0,0 +1,101 @@
+package featureflag
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFlagOn(t *testing.T) {
+	percentage := func(p int) *int { return &p }
+
+	// a subject inside the first bucket of the rollout and one outside, for the flag "beta"
+	var inside, outside string
+	for i := 0; inside == "" || outside == ""; i++ {
+		subject := fmt.Sprintf("user-%d", i)
+		if bucket("beta", subject) < 10 {
+			inside = subject
+		} else {
+			outside = subject
+		}
+	}
+
+	tests := []struct {
+		name    string
+		flag    Flag
+		subject string
+		want    bool
+	}{
+		{name: "disabled", flag: Flag{}, subject: inside, want: false},
+		{name: "enabled for everyone", flag: Flag{Enabled: true}, subject: outside, want: true},
+		{name: "enabled for anonymous requests", flag: Flag{Enabled: true}, subject: "", want: true},
+		{name: "full rollout", flag: Flag{Enabled: true, Percentage: percentage(100)}, subject: outside, want: true},
+		{name: "no rollout", flag: Flag{Enabled: true, Percentage: percentage(0)}, subject: inside, want: false},
+		{name: "inside the rollout", flag: Flag{Enabled: true, Percentage: percentage(10)}, subject: inside, want: true},
+		{name: "outside the rollout", flag: Flag{Enabled: true, Percentage: percentage(10)}, subject: outside, want: false},
+		{
+			name:    "anonymous requests are outside partial rollouts",
+			flag:    Flag{Enabled: true, Percentage: percentage(99)},
+			subject: "",
+			want:    false,
+		},
+		{
+			name:    "allowlisted outside the rollout",
+			flag:    Flag{Enabled: true, Percentage: percentage(0), Allowlist: []string{outside}},
+			subject: outside,
+			want:    true,
+		},
+		{
+			name:    "allowlist of a disabled flag",
+			flag:    Flag{Allowlist: []string{inside}},
+			subject: inside,
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.flag.On("beta", tt.subject); got != tt.want {
+				t.Errorf("On(%q) = %t, want %t", tt.subject, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBucket(t *testing.T) {
+	tests := []struct {
+		name    string
+		flag    string
+		subject string
+	}{
+		{name: "user id", flag: "beta", subject: "4b9f8e2a-8d1c-4c55-9f57-6a0f4a1e7d3c"},
+		{name: "empty subject", flag: "beta", subject: ""},
+		{name: "other flag", flag: "comments", subject: "4b9f8e2a-8d1c-4c55-9f57-6a0f4a1e7d3c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bucket(tt.flag, tt.subject)
+			if got < 0 || got >= 100 {
+				t.Fatalf("bucket() = %d, want a value from 0 to 99", got)
+			}
+			if again := bucket(tt.flag, tt.subject); again != got {
+				t.Errorf("bucket() = %d then %d, want the same bucket every time", got, again)
+			}
+		})
+	}
+}
+
+func TestBucketDistribution(t *testing.T) {
+	// the subjects are spread evenly enough for the percentages to be meaningful
+	const subjects = 10000
+	counts := make([]int, 100)
+	for i := 0; i < subjects; i++ {
+		counts[bucket("beta", fmt.Sprintf("user-%d", i))]++
+	}
+
+	for b, count := range counts {
+		if count < subjects/100/2 || count > subjects/100*2 {
+			t.Errorf("bucket %d has %d subjects, want about %d", b, count, subjects/100)
+		}
+	}
+}
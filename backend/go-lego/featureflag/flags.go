@@ -0,0 +1,149 @@
+package featureflag
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger"
+)
+
+// Sources of the flag definitions
+const (
+	SourceConfig   = "config"
+	SourceDatabase = "database"
+)
+
+// SubjectFunc returns the subject of the request, e.g. the authenticated user id. Anonymous requests
+// return an empty string
+type SubjectFunc func(r *http.Request) string
+
+// State describes a flag and its result for the request
+type State struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	Flag
+	OnForRequest bool `json:"onForRequest"`
+}
+
+// Flags evaluates the configured flags, replaced by the database overrides when there is a store.
+// Definitions and overrides can change while the application runs
+type Flags struct {
+	logger    logger.Logger
+	store     Store
+	subject   SubjectFunc
+	config    atomic.Value
+	overrides atomic.Value
+
+	// refreshErr is the error of the last refresh, an errorValue so nil can be stored
+	refreshErr atomic.Value
+}
+
+type errorValue struct {
+	err error
+}
+
+// New creates the flags of the configuration. The store is optional
+func New(logger logger.Logger, flags map[string]Flag, store Store, subject SubjectFunc) *Flags {
+	f := &Flags{
+		logger:  logger,
+		store:   store,
+		subject: subject,
+	}
+	f.SetFlags(flags)
+	f.overrides.Store(map[string]Flag{})
+	f.refreshErr.Store(errorValue{})
+	return f
+}
+
+// SetFlags replaces the configured flags, e.g. when the configuration is reloaded
+func (f *Flags) SetFlags(flags map[string]Flag) {
+	if flags == nil {
+		flags = map[string]Flag{}
+	}
+	f.config.Store(flags)
+}
+
+// Enabled evaluates the flag for the subject of the request. Unknown flags are off
+func (f *Flags) Enabled(r *http.Request, name string) bool {
+	return f.EnabledFor(name, f.subject(r))
+}
+
+// EnabledFor evaluates the flag for the subject. Unknown flags are off
+func (f *Flags) EnabledFor(name string, subject string) bool {
+	flag, _, ok := f.lookup(name)
+	return ok && flag.On(name, subject)
+}
+
+// States returns every flag and its result for the request, sorted by name
+func (f *Flags) States(r *http.Request) []State {
+	names := map[string]bool{}
+	for name := range f.config.Load().(map[string]Flag) {
+		names[name] = true
+	}
+	for name := range f.overrides.Load().(map[string]Flag) {
+		names[name] = true
+	}
+
+	subject := f.subject(r)
+	states := make([]State, 0, len(names))
+	for name := range names {
+		flag, source, _ := f.lookup(name)
+		states = append(states, State{Name: name, Source: source, Flag: flag, OnForRequest: flag.On(name, subject)})
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states
+}
+
+// Refresh reads the overrides from the store. The previous overrides are kept when it fails
+func (f *Flags) Refresh(ctx context.Context) error {
+	if f.store == nil {
+		return nil
+	}
+
+	overrides, err := f.store.Overrides(ctx)
+	f.refreshErr.Store(errorValue{err: err})
+	if err != nil {
+		return err
+	}
+	f.overrides.Store(overrides)
+	return nil
+}
+
+// Check returns the error of the last refresh of the overrides, for health checks
+func (f *Flags) Check(ctx context.Context) error {
+	return f.refreshErr.Load().(errorValue).err
+}
+
+// Run refreshes the overrides every interval until the context is done
+func (f *Flags) Run(ctx context.Context, interval time.Duration) {
+	if f.store == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := f.Refresh(ctx); err != nil && ctx.Err() == nil {
+			f.logger.Error("Unable to refresh feature flag overrides", logger.Err(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// lookup returns the definition of the flag and where it comes from
+func (f *Flags) lookup(name string) (Flag, string, bool) {
+	if flag, ok := f.overrides.Load().(map[string]Flag)[name]; ok {
+		return flag, SourceDatabase, true
+	}
+	flag, ok := f.config.Load().(map[string]Flag)[name]
+	return flag, SourceConfig, ok
+}
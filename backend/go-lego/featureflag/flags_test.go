@@ -0,0 +1,88 @@
+package featureflag
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger"
+)
+
+// fakeStore returns the overrides, or the error when informed
+type fakeStore struct {
+	overrides map[string]Flag
+	err       error
+}
+
+func (s *fakeStore) Overrides(ctx context.Context) (map[string]Flag, error) {
+	return s.overrides, s.err
+}
+
+func anonymous(r *http.Request) string { return "" }
+
+func TestFlagsRefresh(t *testing.T) {
+	errUnavailable := errors.New("database unavailable")
+	store := &fakeStore{overrides: map[string]Flag{"comments": {Enabled: true}}}
+	flags := New(logger.NewRecorder(), map[string]Flag{"comments": {}}, store, anonymous)
+
+	steps := []struct {
+		name      string
+		err       error
+		want      bool
+		wantCheck error
+	}{
+		{name: "configured flag before the first refresh", want: false},
+		{name: "database override after a refresh", want: true},
+		{name: "previous overrides kept when the refresh fails", err: errUnavailable, want: true, wantCheck: errUnavailable},
+		{name: "recovered after a successful refresh", want: true},
+	}
+
+	for i, step := range steps {
+		if i > 0 {
+			store.err = step.err
+			if err := flags.Refresh(context.Background()); !errors.Is(err, step.err) {
+				t.Fatalf("%s: Refresh() error = %v, want %v", step.name, err, step.err)
+			}
+		}
+		if got := flags.EnabledFor("comments", ""); got != step.want {
+			t.Errorf("%s: EnabledFor() = %t, want %t", step.name, got, step.want)
+		}
+		if err := flags.Check(context.Background()); !errors.Is(err, step.wantCheck) {
+			t.Errorf("%s: Check() = %v, want %v", step.name, err, step.wantCheck)
+		}
+	}
+}
+
+func TestFlagsRunLogsRefreshErrors(t *testing.T) {
+	errUnavailable := errors.New("database unavailable")
+	recorder := logger.NewRecorder()
+	flags := New(recorder, nil, &fakeStore{err: errUnavailable}, anonymous)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		flags.Run(ctx, time.Hour)
+	}()
+
+	// the first refresh happens as soon as Run starts
+	deadline := time.Now().Add(5 * time.Second)
+	for len(recorder.Find("Unable to refresh feature flag overrides")) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	entries := recorder.Find("Unable to refresh feature flag overrides")
+	if len(entries) != 1 {
+		t.Fatalf("logged %d refresh errors, want 1", len(entries))
+	}
+	if entries[0].Level != logger.LevelError {
+		t.Errorf("logged at level %v, want %v", entries[0].Level, logger.LevelError)
+	}
+	if err, _ := entries[0].Field("error"); err != errUnavailable {
+		t.Errorf("logged error = %v, want %v", err, errUnavailable)
+	}
+}
@@ -0,0 +1,25 @@
+package featureflag
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/httphandler/response"
+)
+
+// ErrFeatureDisabled is returned to the requests of routes whose flag is off
+var ErrFeatureDisabled = errors.New("feature not available")
+
+// Require gates the routes behind the flag. Requests for which the flag is off get 404, as if the
+// routes didn't exist
+func (f *Flags) Require(name string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !f.Enabled(r, name) {
+				response.WithJSONError(w, r, http.StatusNotFound, ErrFeatureDisabled)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package featureflag
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database"
+)
+
+// Store reads the flags overriding the configured ones
+type Store interface {
+	Overrides(ctx context.Context) (map[string]Flag, error)
+}
+
+// override is a row of the feature_flag table
+type override struct {
+	Name        *string        `db:"name"`
+	Description *string        `db:"description"`
+	Enabled     *bool          `db:"enabled"`
+	Percentage  *int           `db:"percentage"`
+	Allowlist   pq.StringArray `db:"allowlist"`
+	UpdatedAt   *time.Time     `db:"updated_at"`
+}
+
+type repositoryStore struct {
+	repo database.CRUDRepository
+}
+
+// NewRepositoryStore reads the overrides from the repository of the feature_flag table
+func NewRepositoryStore(repo database.CRUDRepository) Store {
+	return &repositoryStore{repo: repo}
+}
+
+func (s *repositoryStore) Overrides(ctx context.Context) (map[string]Flag, error) {
+	var rows []override
+	if err := s.repo.FindAll(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	overrides := make(map[string]Flag, len(rows))
+	for _, row := range rows {
+		if row.Name == nil {
+			continue
+		}
+		flag := Flag{Percentage: row.Percentage, Allowlist: row.Allowlist}
+		if row.Description != nil {
+			flag.Description = *row.Description
+		}
+		if row.Enabled != nil {
+			flag.Enabled = *row.Enabled
+		}
+		overrides[*row.Name] = flag
+	}
+	return overrides, nil
+}
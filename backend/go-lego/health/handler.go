@@ -0,0 +1,32 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/httphandler/response"
+)
+
+// LivenessHandler answers the liveness probe with the JSON report, with 503 when it fails
+func (r *Registry) LivenessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		withReport(w, req, r.Liveness(req.Context()))
+	}
+}
+
+// ReadinessHandler answers the readiness probe with the JSON report, with 503 when it fails
+func (r *Registry) ReadinessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		withReport(w, req, r.Readiness(req.Context()))
+	}
+}
+
+func withReport(w http.ResponseWriter, r *http.Request, report Report) {
+	// probes must never be answered from a cache
+	w.Header().Set("Cache-Control", "no-store")
+
+	code := http.StatusOK
+	if report.Status == StatusFail {
+		code = http.StatusServiceUnavailable
+	}
+	response.WithJSON(w, r, code, report)
+}
@@ -0,0 +1,218 @@
+// Package health runs the checks behind the liveness and readiness probes
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Status of a check or of a whole probe
+const (
+	StatusPass = "pass"
+	StatusFail = "fail"
+	StatusWarn = "warn"
+)
+
+// ErrShuttingDown fails the readiness probe once the graceful shutdown begins
+var ErrShuttingDown = errors.New("shutting down")
+
+// Config contains the configuration of the checks
+type Config struct {
+	// Timeout of every check, unless the check defines its own
+	Timeout time.Duration `default:"2s" validate:"min=1ms"`
+
+	// CacheTTL is how long the result of a check is reused, so frequent probes don't overload the
+	// dependencies
+	CacheTTL time.Duration `default:"5s" validate:"min=0s"`
+
+	// DrainDelay is how long the servers keep handling requests after the readiness probe starts failing,
+	// so the load balancer notices it before the listeners close
+	DrainDelay time.Duration `validate:"min=0s"`
+}
+
+// CheckFunc returns an error when the dependency isn't healthy
+type CheckFunc func(ctx context.Context) error
+
+// Check is a health check of a component
+type Check struct {
+	Name  string
+	Check CheckFunc
+
+	// Timeout overrides the timeout of the registry
+	Timeout time.Duration
+
+	// Optional checks are reported with StatusWarn when failing, without failing the probe
+	Optional bool
+}
+
+// Result is the last result of a check
+type Result struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration,omitempty"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// Report is the result of a probe
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// Registry contains the liveness and readiness checks registered by the components
+type Registry struct {
+	config       Config
+	mu           sync.Mutex
+	liveness     []*cachedCheck
+	readiness    []*cachedCheck
+	shuttingDown int32
+}
+
+// cachedCheck keeps the last result of a check. Concurrent probes wait for the running check
+type cachedCheck struct {
+	Check
+	mu     sync.Mutex
+	result Result
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry(config Config) *Registry {
+	return &Registry{config: config}
+}
+
+// AddLiveness registers a check of the liveness probe. Liveness checks must only fail when restarting
+// the process fixes the problem, e.g. a deadlock, never because a dependency is down
+func (r *Registry) AddLiveness(check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.liveness = append(r.liveness, &cachedCheck{Check: check})
+}
+
+// AddReadiness registers a check of the readiness probe, e.g. the database connection
+func (r *Registry) AddReadiness(check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.readiness = append(r.readiness, &cachedCheck{Check: check})
+}
+
+// Shutdown makes the readiness probe fail from now on, so the load balancer stops sending requests
+func (r *Registry) Shutdown() {
+	atomic.StoreInt32(&r.shuttingDown, 1)
+}
+
+// Drain calls Shutdown and waits for the drain delay, or until the context is done
+func (r *Registry) Drain(ctx context.Context) error {
+	r.Shutdown()
+
+	timer := time.NewTimer(r.config.DrainDelay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ShuttingDown tells whether Shutdown was called
+func (r *Registry) ShuttingDown() bool {
+	return atomic.LoadInt32(&r.shuttingDown) == 1
+}
+
+// Liveness runs the liveness checks
+func (r *Registry) Liveness(ctx context.Context) Report {
+	r.mu.Lock()
+	checks := append([]*cachedCheck(nil), r.liveness...)
+	r.mu.Unlock()
+
+	return r.run(ctx, checks)
+}
+
+// Readiness runs the readiness checks. It fails without running them once the shutdown begins
+func (r *Registry) Readiness(ctx context.Context) Report {
+	if r.ShuttingDown() {
+		return Report{
+			Status: StatusFail,
+			Checks: []Result{{Name: "shutdown", Status: StatusFail, Error: ErrShuttingDown.Error(), CheckedAt: time.Now()}},
+		}
+	}
+
+	r.mu.Lock()
+	checks := append([]*cachedCheck(nil), r.readiness...)
+	r.mu.Unlock()
+
+	return r.run(ctx, checks)
+}
+
+// run runs the checks concurrently. The probe fails when any required check fails
+func (r *Registry) run(ctx context.Context, checks []*cachedCheck) Report {
+	report := Report{Status: StatusPass, Checks: make([]Result, len(checks))}
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check *cachedCheck) {
+			defer wg.Done()
+			report.Checks[i] = check.run(ctx, r.config)
+		}(i, check)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status == StatusFail {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+// run returns the cached result, or runs the check when it expired
+func (c *cachedCheck) run(ctx context.Context, config Config) Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.result.CheckedAt.IsZero() && time.Since(c.result.CheckedAt) < config.CacheTTL {
+		return c.result
+	}
+
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = config.Timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.runWithTimeout(ctx)
+
+	c.result = Result{Name: c.Name, Status: StatusPass, Duration: time.Since(start).String(), CheckedAt: start}
+	if err != nil {
+		c.result.Status = StatusFail
+		if c.Optional {
+			c.result.Status = StatusWarn
+		}
+		c.result.Error = err.Error()
+	}
+	return c.result
+}
+
+// runWithTimeout stops waiting for checks that ignore the context
+func (c *cachedCheck) runWithTimeout(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Check.Check(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
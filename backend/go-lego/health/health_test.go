@@ -0,0 +1,144 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var errDown = errors.New("down")
+
+func pass(ctx context.Context) error { return nil }
+
+func fail(ctx context.Context) error { return errDown }
+
+// hang ignores the context, like a driver stuck on a dead connection
+func hang(ctx context.Context) error {
+	time.Sleep(time.Second)
+	return nil
+}
+
+func TestReadiness(t *testing.T) {
+	tests := []struct {
+		name         string
+		checks       []Check
+		shutdown     bool
+		wantStatus   string
+		wantStatuses []string
+	}{
+		{name: "no checks", wantStatus: StatusPass, wantStatuses: []string{}},
+		{
+			name:         "all passing",
+			checks:       []Check{{Name: "database", Check: pass}, {Name: "cache", Check: pass}},
+			wantStatus:   StatusPass,
+			wantStatuses: []string{StatusPass, StatusPass},
+		},
+		{
+			name:         "required check failing",
+			checks:       []Check{{Name: "database", Check: fail}, {Name: "cache", Check: pass}},
+			wantStatus:   StatusFail,
+			wantStatuses: []string{StatusFail, StatusPass},
+		},
+		{
+			name:         "optional check failing",
+			checks:       []Check{{Name: "database", Check: pass}, {Name: "flags", Check: fail, Optional: true}},
+			wantStatus:   StatusPass,
+			wantStatuses: []string{StatusPass, StatusWarn},
+		},
+		{
+			name:         "check ignoring its timeout",
+			checks:       []Check{{Name: "database", Check: hang, Timeout: 10 * time.Millisecond}},
+			wantStatus:   StatusFail,
+			wantStatuses: []string{StatusFail},
+		},
+		{
+			name:         "shutting down",
+			checks:       []Check{{Name: "database", Check: pass}},
+			shutdown:     true,
+			wantStatus:   StatusFail,
+			wantStatuses: []string{StatusFail},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry(Config{Timeout: time.Second})
+			for _, check := range tt.checks {
+				registry.AddReadiness(check)
+			}
+			if tt.shutdown {
+				registry.Shutdown()
+			}
+
+			report := registry.Readiness(context.Background())
+			if report.Status != tt.wantStatus {
+				t.Errorf("Readiness() status = %s, want %s", report.Status, tt.wantStatus)
+			}
+			if len(report.Checks) != len(tt.wantStatuses) {
+				t.Fatalf("Readiness() checks = %+v, want %d", report.Checks, len(tt.wantStatuses))
+			}
+			for i, result := range report.Checks {
+				if result.Status != tt.wantStatuses[i] {
+					t.Errorf("check %s status = %s, want %s", result.Name, result.Status, tt.wantStatuses[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCheckCache(t *testing.T) {
+	tests := []struct {
+		name     string
+		cacheTTL time.Duration
+		want     int
+	}{
+		{name: "cached", cacheTTL: time.Hour, want: 1},
+		{name: "not cached", cacheTTL: 0, want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs := 0
+			registry := NewRegistry(Config{Timeout: time.Second, CacheTTL: tt.cacheTTL})
+			registry.AddLiveness(Check{Name: "counter", Check: func(ctx context.Context) error {
+				runs++
+				return nil
+			}})
+
+			for i := 0; i < 3; i++ {
+				registry.Liveness(context.Background())
+			}
+			if runs != tt.want {
+				t.Errorf("check ran %d times, want %d", runs, tt.want)
+			}
+		})
+	}
+}
+
+func TestDrain(t *testing.T) {
+	tests := []struct {
+		name    string
+		delay   time.Duration
+		timeout time.Duration
+		wantErr error
+	}{
+		{name: "waits for the delay", delay: time.Millisecond, timeout: time.Second},
+		{name: "stops when the context is done", delay: time.Hour, timeout: time.Millisecond, wantErr: context.DeadlineExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry(Config{DrainDelay: tt.delay})
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+
+			if err := registry.Drain(ctx); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Drain() error = %v, want %v", err, tt.wantErr)
+			}
+			if !registry.ShuttingDown() {
+				t.Error("ShuttingDown() = false after Drain")
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+// Package middleware contains HTTP middlewares shared by the applications
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	chimiddleware "github.com/go-chi/chi/middleware"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger"
+)
+
+// redactedValue replaces the value of sensitive headers
+const redactedValue = "[REDACTED]"
+
+// DefaultRedactHeaders are the headers whose values are never logged
+var DefaultRedactHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization", "Set-Cookie", "X-API-Key"}
+
+// AccessLogConfig contains the configuration of the access log
+type AccessLogConfig struct {
+	// SuccessSampling logs one of every N successful requests. Every request is logged when it is 0 or 1.
+	// Failed requests are always logged
+	SuccessSampling int `validate:"min=0"`
+
+	// RedactHeaders are logged without their values, in addition to DefaultRedactHeaders
+	RedactHeaders []string
+}
+
+// AccessLog logs one entry per request, with its route pattern, status, latency and size. Server
+// errors are logged at error level and client errors at warn level. It must be used after
+// middleware.RequestID so the entries carry the request id. The extra fields are added to every entry
+func AccessLog(l logger.Logger, config AccessLogConfig, extra ...LogFields) func(next http.Handler) http.Handler {
+	redact := make(map[string]bool, len(DefaultRedactHeaders)+len(config.RedactHeaders))
+	for _, header := range DefaultRedactHeaders {
+		redact[http.CanonicalHeaderKey(header)] = true
+	}
+	for _, header := range config.RedactHeaders {
+		redact[http.CanonicalHeaderKey(header)] = true
+	}
+	var successes uint64
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			defer func() {
+				status := ww.Status()
+				if status == 0 {
+					status = http.StatusOK
+				}
+				if status < http.StatusBadRequest && config.SuccessSampling > 1 &&
+					atomic.AddUint64(&successes, 1)%uint64(config.SuccessSampling) != 1 {
+					return
+				}
+
+				fields := []logger.Field{
+					logger.String("request_id", chimiddleware.GetReqID(r.Context())),
+					logger.String("method", r.Method),
+					logger.String("path", r.URL.Path),
+					logger.Stringer("route", route{r: r}),
+					logger.Int("status", status),
+					logger.Duration("latency", time.Since(start)),
+					logger.Int("bytes", ww.BytesWritten()),
+					logger.String("remote_addr", r.RemoteAddr),
+					logger.Any("headers", redactHeaders(r.Header, redact)),
+				}
+				for _, fn := range extra {
+					fields = append(fields, fn(r)...)
+				}
+
+				switch {
+				case status >= http.StatusInternalServerError:
+					l.Error("HTTP request", fields...)
+				case status >= http.StatusBadRequest:
+					l.Warn("HTTP request", fields...)
+				default:
+					l.Info("HTTP request", fields...)
+				}
+			}()
+
+			next.ServeHTTP(ww, r)
+		})
+	}
+}
+
+// redactHeaders returns the request headers to be logged, hiding the values of the sensitive ones
+func redactHeaders(header http.Header, redact map[string]bool) map[string]string {
+	redacted := make(map[string]string, len(header))
+	for name, values := range header {
+		if redact[name] {
+			redacted[name] = redactedValue
+			continue
+		}
+		redacted[name] = strings.Join(values, ", ")
+	}
+	return redacted
+}
@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-chi/chi"
+	chimiddleware "github.com/go-chi/chi/middleware"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger"
+)
+
+// newLoggedRouter creates a router whose /posts/{id} route answers with the status of the "status" query
+// parameter, or 200 OK, after the middlewares
+func newLoggedRouter(middlewares ...func(http.Handler) http.Handler) http.Handler {
+	r := chi.NewRouter()
+	r.Use(chimiddleware.RequestID)
+	r.Use(middlewares...)
+	r.Get("/posts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		status := http.StatusOK
+		if value := r.URL.Query().Get("status"); value != "" {
+			status, _ = strconv.Atoi(value)
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("body"))
+	})
+	return r
+}
+
+// get sends a GET request to the handler
+func get(handler http.Handler, target string, header http.Header) {
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	for name, values := range header {
+		r.Header[name] = values
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+}
+
+func TestAccessLog(t *testing.T) {
+	recorder := logger.NewRecorder()
+	user := func(r *http.Request) []logger.Field { return []logger.Field{logger.String("user", "reader")} }
+	handler := newLoggedRouter(AccessLog(recorder, AccessLogConfig{}, user))
+
+	get(handler, "/posts/42", nil)
+
+	entries := recorder.Find("HTTP request")
+	if len(entries) != 1 {
+		t.Fatalf("logged entries = %d, want 1", len(entries))
+	}
+	entry := entries[0]
+	if entry.Level != logger.LevelInfo {
+		t.Errorf("level = %s, want %s", entry.Level, logger.LevelInfo)
+	}
+
+	want := map[string]interface{}{
+		"method": http.MethodGet,
+		"path":   "/posts/42",
+		"status": 200,
+		"bytes":  4,
+		"user":   "reader",
+	}
+	for key, value := range want {
+		if got, _ := entry.Field(key); got != value {
+			t.Errorf("field %s = %v, want %v", key, got, value)
+		}
+	}
+	if got, _ := entry.Field("route"); fmt.Sprint(got) != "/posts/{id}" {
+		t.Errorf("field route = %v, want /posts/{id}", got)
+	}
+	if got, _ := entry.Field("request_id"); got == "" {
+		t.Error("field request_id is empty, want the request id")
+	}
+	if _, ok := entry.Field("latency"); !ok {
+		t.Error("field latency is missing")
+	}
+}
+
+func TestAccessLogLevels(t *testing.T) {
+	tests := []struct {
+		status int
+		want   logger.Level
+	}{
+		{status: http.StatusOK, want: logger.LevelInfo},
+		{status: http.StatusFound, want: logger.LevelInfo},
+		{status: http.StatusNotFound, want: logger.LevelWarn},
+		{status: http.StatusTooManyRequests, want: logger.LevelWarn},
+		{status: http.StatusInternalServerError, want: logger.LevelError},
+		{status: http.StatusServiceUnavailable, want: logger.LevelError},
+	}
+
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.status), func(t *testing.T) {
+			recorder := logger.NewRecorder()
+			handler := newLoggedRouter(AccessLog(recorder, AccessLogConfig{}))
+
+			get(handler, "/posts/42?status="+strconv.Itoa(tt.status), nil)
+
+			entries := recorder.Find("HTTP request")
+			if len(entries) != 1 || entries[0].Level != tt.want {
+				t.Errorf("entries = %+v, want one at %s level", entries, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccessLogSampling(t *testing.T) {
+	recorder := logger.NewRecorder()
+	handler := newLoggedRouter(AccessLog(recorder, AccessLogConfig{SuccessSampling: 3}))
+
+	for i := 0; i < 6; i++ {
+		get(handler, "/posts/42", nil)
+	}
+	get(handler, "/posts/42?status=404", nil)
+	get(handler, "/posts/42?status=500", nil)
+
+	// one of every 3 successes, starting with the first one, and all failures
+	var successes, failures int
+	for _, entry := range recorder.Find("HTTP request") {
+		if status, _ := entry.Field("status"); status.(int) < http.StatusBadRequest {
+			successes++
+		} else {
+			failures++
+		}
+	}
+	if successes != 2 || failures != 2 {
+		t.Errorf("logged successes = %d and failures = %d, want 2 and 2", successes, failures)
+	}
+}
+
+func TestAccessLogRedactsHeaders(t *testing.T) {
+	recorder := logger.NewRecorder()
+	handler := newLoggedRouter(AccessLog(recorder, AccessLogConfig{RedactHeaders: []string{"x-csrf-token"}}))
+
+	get(handler, "/posts/42", http.Header{
+		"Authorization": {"Bearer token"},
+		"Cookie":        {"session=secret"},
+		"X-Api-Key":     {"blog_key"},
+		"X-Csrf-Token":  {"csrf"},
+		"Accept":        {"application/json", "text/html"},
+	})
+
+	entries := recorder.Find("HTTP request")
+	if len(entries) != 1 {
+		t.Fatalf("logged entries = %d, want 1", len(entries))
+	}
+	value, _ := entries[0].Field("headers")
+	headers := value.(map[string]string)
+
+	want := map[string]string{
+		"Authorization": redactedValue,
+		"Cookie":        redactedValue,
+		"X-Api-Key":     redactedValue,
+		"X-Csrf-Token":  redactedValue,
+		"Accept":        "application/json, text/html",
+	}
+	for name, value := range want {
+		if headers[name] != value {
+			t.Errorf("header %s = %q, want %q", name, headers[name], value)
+		}
+	}
+}
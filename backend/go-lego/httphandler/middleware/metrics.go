@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	chimiddleware "github.com/go-chi/chi/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// unmatchedRoute labels the requests that didn't match any route, so unknown paths don't create new series
+const unmatchedRoute = "unmatched"
+
+// Metrics counts the requests and observes their latency labelled by route pattern, method and status, registering
+// the collectors into registerer. The route pattern is only known after routing, so the labels are read once the
+// request is served
+func Metrics(registerer prometheus.Registerer) (func(next http.Handler) http.Handler, error) {
+	labels := []string{"route", "method", "status"}
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests served",
+	}, labels)
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Latency of the HTTP requests",
+		Buckets: prometheus.DefBuckets,
+	}, labels)
+
+	for _, collector := range []prometheus.Collector{requests, duration} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			defer func() {
+				status := ww.Status()
+				if status == 0 {
+					status = http.StatusOK
+				}
+				pattern := route{r: r}.String()
+				if pattern == "" {
+					pattern = unmatchedRoute
+				}
+
+				values := []string{pattern, r.Method, strconv.Itoa(status)}
+				requests.WithLabelValues(values...).Inc()
+				duration.WithLabelValues(values...).Observe(time.Since(start).Seconds())
+			}()
+
+			next.ServeHTTP(ww, r)
+		})
+	}, nil
+}
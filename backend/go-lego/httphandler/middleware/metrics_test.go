@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics, err := Metrics(registry)
+	if err != nil {
+		t.Fatalf("Metrics() error = %v", err)
+	}
+
+	// the middleware is used by the root router, before the routes of the mounted routers are known
+	posts := chi.NewRouter()
+	posts.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("status") == "500" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+	r := chi.NewRouter()
+	r.Use(metrics)
+	r.Mount("/posts", posts)
+
+	get(r, "/posts/1", nil)
+	get(r, "/posts/2", nil)
+	get(r, "/posts/3?status=500", nil)
+	get(r, "/unknown/path", nil)
+	get(r, "/another/unknown", nil)
+
+	// raw paths never become labels, so the series don't grow with the ids or unknown paths
+	expected := `
+# HELP http_requests_total HTTP requests served
+# TYPE http_requests_total counter
+http_requests_total{method="GET",route="/posts/{id}",status="200"} 2
+http_requests_total{method="GET",route="/posts/{id}",status="500"} 1
+http_requests_total{method="GET",route="unmatched",status="404"} 2
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "http_requests_total"); err != nil {
+		t.Error(err)
+	}
+	if count, err := testutil.GatherAndCount(registry, "http_request_duration_seconds"); err != nil || count != 3 {
+		t.Errorf("http_request_duration_seconds series = %d (%v), want 3", count, err)
+	}
+}
+
+func TestMetricsRegistersOnce(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	if _, err := Metrics(registry); err != nil {
+		t.Fatalf("Metrics() error = %v", err)
+	}
+	if _, err := Metrics(registry); err == nil {
+		t.Error("Metrics() registering the collectors twice error = nil, want an error")
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	chimiddleware "github.com/go-chi/chi/middleware"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger"
+)
+
+// LogFields returns extra fields describing the request, e.g. the authenticated user
+type LogFields func(r *http.Request) []logger.Field
+
+// RequestLogger puts a logger seeded with the request id and route pattern into the request context,
+// so handlers, services and repositories get it with logger.FromContext
+// (or zaplog.FromContext when it is backed by zap). It must be used after
+// middleware.RequestID
+func RequestLogger(l logger.Logger, extra ...LogFields) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fields := []logger.Field{
+				logger.String("request_id", chimiddleware.GetReqID(r.Context())),
+				logger.Stringer("route", route{r: r}),
+			}
+			for _, fn := range extra {
+				fields = append(fields, fn(r)...)
+			}
+
+			ctx := logger.IntoContext(r.Context(), l.With(fields...))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// route is resolved when an entry is written, because the pattern is only complete after routing
+type route struct {
+	r *http.Request
+}
+
+func (r route) String() string {
+	if rctx := chi.RouteContext(r.r.Context()); rctx != nil {
+		return rctx.RoutePattern()
+	}
+	return ""
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	chimiddleware "github.com/go-chi/chi/middleware"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger"
+)
+
+func TestRequestLogger(t *testing.T) {
+	recorder := logger.NewRecorder()
+	user := func(r *http.Request) []logger.Field { return []logger.Field{logger.String("user", "reader")} }
+
+	var requestID string
+	r := chi.NewRouter()
+	r.Use(chimiddleware.RequestID)
+	r.Use(RequestLogger(recorder, user))
+	r.Route("/posts", func(r chi.Router) {
+		r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+			requestID = chimiddleware.GetReqID(r.Context())
+			logger.FromContext(r.Context()).Info("Handled", logger.String("key", "value"))
+		})
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/posts/42", nil))
+
+	entries := recorder.Find("Handled")
+	if len(entries) != 1 {
+		t.Fatalf("logged entries = %d, want 1", len(entries))
+	}
+	entry := entries[0]
+
+	// the route is the pattern of the mounted routes, resolved after the context was seeded
+	want := map[string]string{
+		"request_id": requestID,
+		"route":      "/posts/{id}",
+		"user":       "reader",
+		"key":        "value",
+	}
+	for key, value := range want {
+		if got, _ := entry.Field(key); fmt.Sprint(got) != value {
+			t.Errorf("field %s = %v, want %v", key, got, value)
+		}
+	}
+	if requestID == "" {
+		t.Error("request id is empty")
+	}
+}
+
+func TestRequestLoggerWithoutRoute(t *testing.T) {
+	recorder := logger.NewRecorder()
+	handler := RequestLogger(recorder)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.FromContext(r.Context()).Info("Handled")
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	entries := recorder.Find("Handled")
+	if len(entries) != 1 {
+		t.Fatalf("logged entries = %d, want 1", len(entries))
+	}
+	if got, _ := entries[0].Field("route"); fmt.Sprint(got) != "" {
+		t.Errorf("field route = %v, want empty outside a router", got)
+	}
+}
@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	callerTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	callerSpanID  = "00f067aa0ba902b7"
+)
+
+// newTracedRouter routes /posts/{id} through the Tracing middleware, recording the spans in memory. The status of
+// the response is the status query parameter, if any
+func newTracedRouter(t *testing.T) (http.Handler, *tracetest.SpanRecorder) {
+	t.Helper()
+
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	r := chi.NewRouter()
+	r.Use(Tracing)
+	r.Get("/posts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if status := r.URL.Query().Get("status"); status == "404" {
+			w.WriteHeader(http.StatusNotFound)
+		} else if status == "500" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+	return r, recorder
+}
+
+// attributeOf returns the value of the attribute of the span
+func attributeOf(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTracing(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		wantName   string
+		wantStatus int64
+		wantCode   codes.Code
+	}{
+		{name: "renamed after the route", target: "/posts/1", wantName: "GET /posts/{id}", wantStatus: 200},
+		{name: "client error", target: "/posts/1?status=404", wantName: "GET /posts/{id}", wantStatus: 404},
+		{
+			name:       "server error",
+			target:     "/posts/1?status=500",
+			wantName:   "GET /posts/{id}",
+			wantStatus: 500,
+			wantCode:   codes.Error,
+		},
+		{name: "unmatched route", target: "/unknown", wantName: "HTTP GET", wantStatus: 404},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, recorder := newTracedRouter(t)
+			get(handler, tt.target, nil)
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("ended spans = %d, want 1", len(spans))
+			}
+			span := spans[0]
+			if span.Name() != tt.wantName {
+				t.Errorf("span name = %q, want %q", span.Name(), tt.wantName)
+			}
+			if span.SpanKind() != trace.SpanKindServer {
+				t.Errorf("span kind = %v, want %v", span.SpanKind(), trace.SpanKindServer)
+			}
+			if status := attributeOf(span, semconv.HTTPResponseStatusCodeKey).AsInt64(); status != tt.wantStatus {
+				t.Errorf("%s = %d, want %d", semconv.HTTPResponseStatusCodeKey, status, tt.wantStatus)
+			}
+			if span.Status().Code != tt.wantCode {
+				t.Errorf("span status = %v, want %v", span.Status().Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestTracingRouteAttribute(t *testing.T) {
+	handler, recorder := newTracedRouter(t)
+
+	get(handler, "/posts/1", nil)
+
+	span := recorder.Ended()[0]
+	if route := attributeOf(span, semconv.HTTPRouteKey).AsString(); route != "/posts/{id}" {
+		t.Errorf("%s = %q, want /posts/{id}", semconv.HTTPRouteKey, route)
+	}
+	if path := attributeOf(span, semconv.URLPathKey).AsString(); path != "/posts/1" {
+		t.Errorf("%s = %q, want /posts/1", semconv.URLPathKey, path)
+	}
+}
+
+func TestTracingContinuesTheCallerTrace(t *testing.T) {
+	_, recorder := newTracedRouter(t)
+
+	var handled trace.SpanContext
+	r := chi.NewRouter()
+	r.Use(Tracing)
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		handled = trace.SpanContextFromContext(r.Context())
+	})
+	get(r, "/", http.Header{"Traceparent": {"00-" + callerTraceID + "-" + callerSpanID + "-01"}})
+
+	span := recorder.Ended()[0]
+	if traceID := span.SpanContext().TraceID().String(); traceID != callerTraceID {
+		t.Errorf("trace id = %s, want %s", traceID, callerTraceID)
+	}
+	if parent := span.Parent(); parent.SpanID().String() != callerSpanID || !parent.IsRemote() {
+		t.Errorf("parent = %s (remote %t), want the remote %s", parent.SpanID(), parent.IsRemote(), callerSpanID)
+	}
+	// the handler sees the span of the request, so its children and log entries belong to it
+	if handled.SpanID() != span.SpanContext().SpanID() {
+		t.Errorf("span of the handler = %s, want %s", handled.SpanID(), span.SpanContext().SpanID())
+	}
+}
+
+func TestTracingStartsNewTraces(t *testing.T) {
+	handler, recorder := newTracedRouter(t)
+
+	get(handler, "/posts/1", nil)
+	get(handler, "/posts/2", nil)
+
+	spans := recorder.Ended()
+	if spans[0].Parent().IsValid() {
+		t.Errorf("parent = %s, want none", spans[0].Parent().SpanID())
+	}
+	if spans[0].SpanContext().TraceID() == spans[1].SpanContext().TraceID() {
+		t.Error("requests without a traceparent share the trace")
+	}
+}
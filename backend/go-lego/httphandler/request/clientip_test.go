@@ -0,0 +1,133 @@
+package request
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	tests := []struct {
+		name    string
+		proxies []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "none", want: []string{}},
+		{name: "IPv4 address", proxies: []string{"127.0.0.1"}, want: []string{"127.0.0.1/32"}},
+		{name: "IPv6 address", proxies: []string{"::1"}, want: []string{"::1/128"}},
+		{name: "CIDR ranges", proxies: []string{"10.0.0.0/8", "fd00::/8"}, want: []string{"10.0.0.0/8", "fd00::/8"}},
+		{name: "invalid address", proxies: []string{"proxy.local"}, wantErr: true},
+		{name: "invalid range", proxies: []string{"10.0.0.0/33"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			networks, err := ParseTrustedProxies(tt.proxies)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTrustedProxies() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(networks) != len(tt.want) {
+				t.Fatalf("ParseTrustedProxies() = %v, want %v", networks, tt.want)
+			}
+			for i, network := range networks {
+				if network.String() != tt.want[i] {
+					t.Errorf("ParseTrustedProxies()[%d] = %s, want %s", i, network, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	trustedProxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "::1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		realIP       string
+		trustProxies bool
+		want         string
+	}{
+		{name: "direct client", remoteAddr: "203.0.113.7:51000", want: "203.0.113.7"},
+		{
+			name:         "forwarded headers of untrusted clients are ignored",
+			remoteAddr:   "203.0.113.7:51000",
+			forwardedFor: "198.51.100.1",
+			realIP:       "198.51.100.2",
+			trustProxies: true,
+			want:         "203.0.113.7",
+		},
+		{
+			name:         "forwarded headers are ignored without trusted proxies",
+			remoteAddr:   "10.0.0.5:51000",
+			forwardedFor: "198.51.100.1",
+			want:         "10.0.0.5",
+		},
+		{
+			name:         "client forwarded by a trusted proxy",
+			remoteAddr:   "10.0.0.5:51000",
+			forwardedFor: "198.51.100.1",
+			trustProxies: true,
+			want:         "198.51.100.1",
+		},
+		{
+			name:         "the nearest untrusted hop is the client, not the spoofed first one",
+			remoteAddr:   "10.0.0.5:51000",
+			forwardedFor: "192.0.2.66, 198.51.100.1, 10.0.0.9",
+			trustProxies: true,
+			want:         "198.51.100.1",
+		},
+		{
+			name:         "only trusted proxies forwarded the request",
+			remoteAddr:   "10.0.0.5:51000",
+			forwardedFor: "10.0.0.9, 10.0.0.8",
+			trustProxies: true,
+			want:         "10.0.0.5",
+		},
+		{
+			name:         "X-Real-IP of a trusted proxy",
+			remoteAddr:   "[::1]:51000",
+			realIP:       " 198.51.100.3 ",
+			trustProxies: true,
+			want:         "198.51.100.3",
+		},
+		{
+			name:         "X-Forwarded-For wins over X-Real-IP",
+			remoteAddr:   "10.0.0.5:51000",
+			forwardedFor: "198.51.100.1",
+			realIP:       "198.51.100.3",
+			trustProxies: true,
+			want:         "198.51.100.1",
+		},
+		{name: "remote address without port", remoteAddr: "203.0.113.7", want: "203.0.113.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.forwardedFor != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			proxies := trustedProxies
+			if !tt.trustProxies {
+				proxies = nil
+			}
+			if got := ClientIP(r, proxies); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
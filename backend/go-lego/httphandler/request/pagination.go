@@ -0,0 +1,40 @@
+package request
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database"
+)
+
+const (
+	// DefaultPageSize is used when the request doesn't inform the "limit" query param
+	DefaultPageSize = 20
+
+	// MaxPageSize is the biggest "limit" accepted from the request
+	MaxPageSize = 100
+)
+
+// ParsePage reads the "limit" and "offset" query params of the request
+func ParsePage(r *http.Request) (database.Page, error) {
+	page := database.Page{Limit: DefaultPageSize}
+
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		value, err := strconv.ParseUint(limit, 10, 64)
+		if err != nil || value == 0 || value > MaxPageSize {
+			return page, fmt.Errorf("query param limit must be between 1 and %d", MaxPageSize)
+		}
+		page.Limit = value
+	}
+
+	if offset := r.URL.Query().Get("offset"); offset != "" {
+		value, err := strconv.ParseUint(offset, 10, 64)
+		if err != nil {
+			return page, fmt.Errorf("query param offset must be a positive number")
+		}
+		page.Offset = value
+	}
+
+	return page, nil
+}
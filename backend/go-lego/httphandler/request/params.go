@@ -0,0 +1,40 @@
+package request
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+// UUIDParam parses the chi URL param with the given name as an uuid
+func UUIDParam(r *http.Request, name string) (uuid.UUID, error) {
+	value, err := uuid.Parse(chi.URLParam(r, name))
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("url param %s must be a valid uuid", name)
+	}
+
+	return value, nil
+}
+
+// IntParam parses the chi URL param with the given name as an integer
+func IntParam(r *http.Request, name string) (int, error) {
+	value, err := strconv.Atoi(chi.URLParam(r, name))
+	if err != nil {
+		return 0, fmt.Errorf("url param %s must be a number", name)
+	}
+
+	return value, nil
+}
+
+// IntQuery parses the query param with the given name as an integer
+func IntQuery(r *http.Request, name string) (int, error) {
+	value, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil {
+		return 0, fmt.Errorf("query param %s must be a number", name)
+	}
+
+	return value, nil
+}
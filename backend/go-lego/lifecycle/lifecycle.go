@@ -0,0 +1,185 @@
+// Package lifecycle starts the components of an application in the order they were registered and stops
+// them in the reverse order, so every component stops before the ones it depends on
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger"
+)
+
+// Config contains the timeouts of the lifecycle
+type Config struct {
+	// StartTimeout bounds the start of every component
+	StartTimeout time.Duration `default:"15s" validate:"min=1ms"`
+
+	// StopTimeout bounds the whole shutdown. The components that are still stopping when it expires are abandoned
+	StopTimeout time.Duration `default:"30s" validate:"min=1ms"`
+}
+
+// Hook is a component of the application. Both functions are optional
+type Hook struct {
+	Name  string
+	Start func(ctx context.Context) error
+	Stop  func(ctx context.Context) error
+}
+
+// Manager runs the hooks of the components
+type Manager struct {
+	logger  logger.Logger
+	config  Config
+	mu      sync.Mutex
+	hooks   []Hook
+	started int
+	failed  chan error
+}
+
+// New creates a manager without hooks
+func New(l logger.Logger, config Config) *Manager {
+	return &Manager{logger: l, config: config, failed: make(chan error, 1)}
+}
+
+// Append registers a hook. It is started after the hooks already registered, and stopped before them
+func (m *Manager) Append(hook Hook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.hooks = append(m.hooks, hook)
+}
+
+// Go registers a background job, which runs in its own goroutine from its start until its stop, when its
+// context is canceled and the manager waits for it to return
+func (m *Manager) Go(name string, job func(ctx context.Context)) {
+	var cancel context.CancelFunc
+	done := make(chan struct{})
+
+	m.Append(Hook{
+		Name: name,
+		Start: func(ctx context.Context) error {
+			// the job outlives the start, so only the values of the context are kept
+			var jobCtx context.Context
+			jobCtx, cancel = context.WithCancel(context.WithoutCancel(ctx))
+			go func() {
+				defer close(done)
+				job(jobCtx)
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	})
+}
+
+// Fail reports that a component stopped working after its start, e.g. a server that can't accept connections.
+// Run stops the application and returns the first error reported
+func (m *Manager) Fail(name string, err error) {
+	select {
+	case m.failed <- fmt.Errorf("%s failed: %w", name, err):
+	default:
+	}
+}
+
+// Start starts the hooks in order. When one of them fails, the ones already started are stopped
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	hooks := append([]Hook(nil), m.hooks...)
+	m.mu.Unlock()
+
+	for _, hook := range hooks {
+		if hook.Start != nil {
+			m.logger.Debug("Starting component", logger.String("component", hook.Name))
+			startCtx, cancel := context.WithTimeout(ctx, m.config.StartTimeout)
+			err := hook.Start(startCtx)
+			cancel()
+			if err != nil {
+				err = fmt.Errorf("unable to start %s: %w", hook.Name, err)
+				if stopErr := m.Stop(ctx); stopErr != nil {
+					return errors.Join(err, stopErr)
+				}
+				return err
+			}
+		}
+
+		m.mu.Lock()
+		m.started++
+		m.mu.Unlock()
+	}
+
+	return nil
+}
+
+// Stop stops the started hooks in the reverse order, all of them sharing the stop timeout. Every hook is stopped
+// even when the previous ones fail, and the errors are returned together
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	hooks := append([]Hook(nil), m.hooks[:m.started]...)
+	m.started = 0
+	m.mu.Unlock()
+
+	// the context is usually done already when the shutdown begins
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), m.config.StopTimeout)
+	defer cancel()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if hooks[i].Stop == nil {
+			continue
+		}
+
+		m.logger.Debug("Stopping component", logger.String("component", hooks[i].Name))
+		if err := hooks[i].Stop(ctx); err != nil {
+			m.logger.Error("Unable to stop component", logger.String("component", hooks[i].Name), logger.Err(err))
+			errs = append(errs, fmt.Errorf("unable to stop %s: %w", hooks[i].Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Run starts the hooks and stops them once the context is done or a component fails. The context is usually
+// canceled by a signal, and receiving one of the force signals while stopping exits immediately
+func (m *Manager) Run(ctx context.Context, force ...os.Signal) error {
+	if err := m.Start(ctx); err != nil {
+		return err
+	}
+
+	var failure error
+	select {
+	case <-ctx.Done():
+		m.logger.Info("Shutting down")
+	case failure = <-m.failed:
+		m.logger.Error("Shutting down after a component failed", logger.Err(failure))
+	}
+
+	forced := make(chan os.Signal, 1)
+	if len(force) > 0 {
+		signal.Notify(forced, force...)
+		defer signal.Stop(forced)
+	}
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- m.Stop(ctx)
+	}()
+
+	select {
+	case err := <-stopped:
+		return errors.Join(failure, err)
+	case sig := <-forced:
+		m.logger.Error("Forced exit, the components still stopping are abandoned", logger.Stringer("signal", sig))
+		os.Exit(1)
+		return nil
+	}
+}
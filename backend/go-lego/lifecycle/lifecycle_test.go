@@ -0,0 +1,143 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger"
+)
+
+var errBroken = errors.New("broken")
+
+// component describes a hook of a test, whose calls are recorded in order
+type component struct {
+	name     string
+	startErr error
+	stopErr  error
+}
+
+func TestManager(t *testing.T) {
+	tests := []struct {
+		name       string
+		components []component
+		wantCalls  []string
+		wantErrs   []error
+		wantLogged int
+	}{
+		{
+			name:       "starts in order and stops in reverse",
+			components: []component{{name: "database"}, {name: "cache"}, {name: "server"}},
+			wantCalls: []string{
+				"start database", "start cache", "start server",
+				"stop server", "stop cache", "stop database",
+			},
+		},
+		{
+			name:       "failed start stops the started components",
+			components: []component{{name: "database"}, {name: "cache", startErr: errBroken}, {name: "server"}},
+			wantCalls:  []string{"start database", "start cache", "stop database"},
+			wantErrs:   []error{errBroken},
+		},
+		{
+			name: "stop errors are joined",
+			components: []component{
+				{name: "database", stopErr: errBroken},
+				{name: "cache", stopErr: context.Canceled},
+			},
+			wantCalls:  []string{"start database", "start cache", "stop cache", "stop database"},
+			wantErrs:   []error{errBroken, context.Canceled},
+			wantLogged: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := logger.NewRecorder()
+			manager := New(recorder, Config{StartTimeout: time.Second, StopTimeout: time.Second})
+
+			var calls []string
+			for _, c := range tt.components {
+				c := c
+				manager.Append(Hook{
+					Name: c.name,
+					Start: func(ctx context.Context) error {
+						calls = append(calls, "start "+c.name)
+						return c.startErr
+					},
+					Stop: func(ctx context.Context) error {
+						calls = append(calls, "stop "+c.name)
+						return c.stopErr
+					},
+				})
+			}
+
+			err := manager.Start(context.Background())
+			if err == nil {
+				err = manager.Stop(context.Background())
+			}
+
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+			if len(tt.wantErrs) == 0 && err != nil {
+				t.Errorf("error = %v, want nil", err)
+			}
+			for _, want := range tt.wantErrs {
+				if !errors.Is(err, want) {
+					t.Errorf("error = %v, want it to wrap %v", err, want)
+				}
+			}
+			if logged := recorder.Find("Unable to stop component"); len(logged) != tt.wantLogged {
+				t.Errorf("logged %d stop errors, want %d", len(logged), tt.wantLogged)
+			}
+		})
+	}
+}
+
+func TestManagerGo(t *testing.T) {
+	manager := New(logger.NewRecorder(), Config{StartTimeout: time.Second, StopTimeout: time.Second})
+
+	stopped := false
+	manager.Go("worker", func(ctx context.Context) {
+		<-ctx.Done()
+		stopped = true
+	})
+
+	if err := manager.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := manager.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if !stopped {
+		t.Error("the job didn't return before Stop")
+	}
+}
+
+func TestManagerRunStopsOnFailure(t *testing.T) {
+	manager := New(logger.NewRecorder(), Config{StartTimeout: time.Second, StopTimeout: time.Second})
+
+	stopped := false
+	manager.Append(Hook{
+		Name: "server",
+		Start: func(ctx context.Context) error {
+			manager.Fail("server", errBroken)
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			stopped = true
+			return nil
+		},
+	})
+
+	err := manager.Run(context.Background())
+	if !errors.Is(err, errBroken) {
+		t.Errorf("Run() error = %v, want %v", err, errBroken)
+	}
+	if !stopped {
+		t.Error("Run() didn't stop the components")
+	}
+}
@@ -1,7 +1,69 @@
 package logger
 
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Output types
+const (
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
+	OutputFile   = "file"
+)
+
 type Config struct {
 	LogLevel   string
 	AppName    string
 	Production bool
+
+	// Outputs are the sinks of the entries. Entries are written to stderr when there is none
+	Outputs []Output
+
+	// Sampling limits repeated entries. Production loggers sample by default, development ones don't
+	Sampling *Sampling
+
+	// Version of the application, added to every entry when informed
+	Version string
+
+	// Hostname adds the name of the host to every entry
+	Hostname bool
+
+	// Fields are static key/values added to every entry
+	Fields map[string]string
+}
+
+// Output is a sink of log entries. MinLevel and MaxLevel route a range of levels to the output,
+// e.g. errors to stderr and everything else to stdout
+type Output struct {
+	// Type is stdout, stderr or file
+	Type string `validate:"required,oneof=stdout stderr file"`
+
+	MinLevel string `validate:"oneof=debug info warn error dpanic panic fatal"`
+	MaxLevel string `validate:"oneof=debug info warn error dpanic panic fatal"`
+
+	// Path of the log file. The file is rotated when it reaches MaxSizeMB megabytes, and rotated
+	// files are removed after MaxAgeDays days or when there are more than MaxBackups of them
+	Path       string
+	MaxSizeMB  int `validate:"min=0"`
+	MaxAgeDays int `validate:"min=0"`
+	MaxBackups int `validate:"min=0"`
+	Compress   bool
+}
+
+// Validate checks that file outputs have a path
+func (o *Output) Validate() error {
+	if strings.EqualFold(o.Type, OutputFile) && o.Path == "" {
+		return errors.New("file outputs require a path")
+	}
+	return nil
+}
+
+// Sampling logs the first Initial entries with the same level and message every Tick, and then
+// one of every Thereafter entries
+type Sampling struct {
+	Initial    int           `validate:"min=0"`
+	Thereafter int           `validate:"min=0"`
+	Tick       time.Duration `validate:"min=0s"`
 }
@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type contextKey struct{}
+
+// defaultLogger is returned by FromContext when the context has no logger
+var defaultLogger atomic.Value
+
+func init() {
+	SetDefault(Nop())
+}
+
+// SetDefault changes the logger returned by FromContext when the context has none
+func SetDefault(logger Logger) {
+	defaultLogger.Store(&logger)
+}
+
+// IntoContext returns a copy of the context carrying the logger
+func IntoContext(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger of the context, usually seeded with the fields of the current
+// request. The default logger is returned when the context has none
+func FromContext(ctx context.Context) Logger {
+	if logger, ok := ctx.Value(contextKey{}).(Logger); ok {
+		return logger
+	}
+	return *defaultLogger.Load().(*Logger)
+}
+
+// Nop returns a logger that discards all entries
+func Nop() Logger {
+	return nop{}
+}
+
+type nop struct{}
+
+func (nop) Debug(string, ...Field) {}
+func (nop) Info(string, ...Field)  {}
+func (nop) Warn(string, ...Field)  {}
+func (nop) Error(string, ...Field) {}
+func (n nop) With(...Field) Logger { return n }
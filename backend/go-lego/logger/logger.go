@@ -0,0 +1,105 @@
+// Package logger defines a logging interface that doesn't depend on a logging library. Adapters
+// implement it on top of zap (zaplog) and log/slog (sloglog)
+package logger
+
+import (
+	"fmt"
+	"time"
+)
+
+// Level is the severity of an entry
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+}
+
+// Logger writes structured entries with key/value fields
+type Logger interface {
+	Debug(message string, fields ...Field)
+	Info(message string, fields ...Field)
+	Warn(message string, fields ...Field)
+	Error(message string, fields ...Field)
+
+	// With returns a child logger that adds the fields to every entry
+	With(fields ...Field) Logger
+}
+
+// Field is a key/value added to an entry
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// String creates a string field
+func String(key string, value string) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Strings creates a field with a list of strings
+func Strings(key string, value []string) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Int creates an integer field
+func Int(key string, value int) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Int64 creates a 64 bits integer field
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Float64 creates a floating point field
+func Float64(key string, value float64) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Bool creates a boolean field
+func Bool(key string, value bool) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Duration creates a duration field
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Time creates a time field
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Err creates an "error" field
+func Err(err error) Field {
+	return Field{Key: "error", Value: err}
+}
+
+// Stringer creates a field whose value is only formatted when the entry is written
+func Stringer(key string, value fmt.Stringer) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Any creates a field with any value, encoded by the logging library
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
@@ -0,0 +1,86 @@
+package logger
+
+import "sync"
+
+// Entry is an entry written to a Recorder
+type Entry struct {
+	Level   Level
+	Message string
+	Fields  []Field
+}
+
+// Field returns the value of the field with the key, and whether it exists
+func (e Entry) Field(key string) (interface{}, bool) {
+	for i := len(e.Fields) - 1; i >= 0; i-- {
+		if e.Fields[i].Key == key {
+			return e.Fields[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+// Recorder keeps the entries in memory, so tests can assert on what was logged. Child loggers
+// created with With record into the same Recorder
+type Recorder struct {
+	store  *recorderStore
+	fields []Field
+}
+
+type recorderStore struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// NewRecorder creates an empty recording logger
+func NewRecorder() *Recorder {
+	return &Recorder{store: &recorderStore{}}
+}
+
+func (r *Recorder) Debug(message string, fields ...Field) { r.record(LevelDebug, message, fields) }
+func (r *Recorder) Info(message string, fields ...Field)  { r.record(LevelInfo, message, fields) }
+func (r *Recorder) Warn(message string, fields ...Field)  { r.record(LevelWarn, message, fields) }
+func (r *Recorder) Error(message string, fields ...Field) { r.record(LevelError, message, fields) }
+
+func (r *Recorder) With(fields ...Field) Logger {
+	return &Recorder{store: r.store, fields: append(append([]Field{}, r.fields...), fields...)}
+}
+
+// Entries returns a copy of all recorded entries, oldest first
+func (r *Recorder) Entries() []Entry {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return append([]Entry(nil), r.store.entries...)
+}
+
+// Find returns the recorded entries with the message
+func (r *Recorder) Find(message string) []Entry {
+	var found []Entry
+	for _, entry := range r.Entries() {
+		if entry.Message == message {
+			found = append(found, entry)
+		}
+	}
+	return found
+}
+
+// Reset removes all recorded entries
+func (r *Recorder) Reset() {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.entries = nil
+}
+
+func (r *Recorder) record(level Level, message string, fields []Field) {
+	entry := Entry{
+		Level:   level,
+		Message: message,
+		Fields:  append(append([]Field{}, r.fields...), fields...),
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.entries = append(r.store.entries, entry)
+}
@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	recorder := NewRecorder()
+	child := recorder.With(String("request_id", "abc"))
+
+	recorder.Info("parent")
+	child.Error("child", String("request_id", "def"), Int("status", 500))
+
+	entries := recorder.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Entries() = %v, want the entries of the parent and the child", entries)
+	}
+
+	found := recorder.Find("child")
+	if len(found) != 1 || found[0].Level != LevelError {
+		t.Fatalf("Find() = %v, want one error", found)
+	}
+	// the last field with a key wins, like in the encoded entries
+	if value, ok := found[0].Field("request_id"); !ok || value != "def" {
+		t.Errorf("Field(request_id) = %v, want def", value)
+	}
+	if _, ok := entries[0].Field("request_id"); ok {
+		t.Error("the parent has the fields of the child")
+	}
+
+	recorder.Reset()
+	if entries := recorder.Entries(); len(entries) != 0 {
+		t.Errorf("Entries() after Reset = %v, want none", entries)
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	fallback := NewRecorder()
+	carried := NewRecorder()
+
+	SetDefault(fallback)
+	defer SetDefault(Nop())
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want Logger
+	}{
+		{name: "without logger", ctx: context.Background(), want: fallback},
+		{name: "with logger", ctx: IntoContext(context.Background(), carried), want: carried},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromContext(tt.ctx); got != tt.want {
+				t.Errorf("FromContext() = %p, want %p", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Package sloglog adapts the standard library log/slog to the backend-neutral logger.Logger
+package sloglog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"time"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger"
+)
+
+// New returns a backend-neutral logger writing to the slog logger
+func New(l *slog.Logger) logger.Logger {
+	return &adapter{logger: l}
+}
+
+// adapter implements logger.Logger converting its fields into slog attributes
+type adapter struct {
+	logger *slog.Logger
+}
+
+// Debug logs an debug message with fields
+func (a *adapter) Debug(message string, fields ...logger.Field) {
+	a.log(slog.LevelDebug, message, fields)
+}
+
+// Info logs an info message with fields
+func (a *adapter) Info(message string, fields ...logger.Field) {
+	a.log(slog.LevelInfo, message, fields)
+}
+
+// Warn logs a warning with fields
+func (a *adapter) Warn(message string, fields ...logger.Field) {
+	a.log(slog.LevelWarn, message, fields)
+}
+
+// Error logs an error message with fields
+func (a *adapter) Error(message string, fields ...logger.Field) {
+	a.log(slog.LevelError, message, fields)
+}
+
+// With returns a child logger that adds the fields to every entry
+func (a *adapter) With(fields ...logger.Field) logger.Logger {
+	args := make([]interface{}, 0, len(fields))
+	for _, attr := range attrs(fields) {
+		args = append(args, attr)
+	}
+	return &adapter{logger: a.logger.With(args...)}
+}
+
+// log writes the record directly to the handler, so the source is the caller of the adapter
+func (a *adapter) log(level slog.Level, message string, fields []logger.Field) {
+	ctx := context.Background()
+	if !a.logger.Enabled(ctx, level) {
+		return
+	}
+
+	// skip runtime.Callers, log and the level method
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+
+	record := slog.NewRecord(time.Now(), level, message, pcs[0])
+	record.AddAttrs(attrs(fields)...)
+	_ = a.logger.Handler().Handle(ctx, record)
+}
+
+func attrs(fields []logger.Field) []slog.Attr {
+	converted := make([]slog.Attr, 0, len(fields))
+	for _, field := range fields {
+		switch value := field.Value.(type) {
+		case time.Duration, time.Time:
+			converted = append(converted, slog.Any(field.Key, value))
+		case error:
+			converted = append(converted, slog.String(field.Key, value.Error()))
+		case fmt.Stringer:
+			converted = append(converted, slog.Any(field.Key, stringer{value}))
+		default:
+			converted = append(converted, slog.Any(field.Key, value))
+		}
+	}
+	return converted
+}
+
+// stringer is only formatted when a handler writes the record
+type stringer struct {
+	value fmt.Stringer
+}
+
+func (s stringer) LogValue() slog.Value {
+	return slog.StringValue(s.value.String())
+}
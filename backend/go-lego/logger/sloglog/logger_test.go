@@ -0,0 +1,123 @@
+package sloglog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger"
+)
+
+// newJSON returns the adapter writing JSON lines to the buffer
+func newJSON(level slog.Level) (logger.Logger, *bytes.Buffer) {
+	var out bytes.Buffer
+	handler := slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: level, AddSource: true})
+	return New(slog.New(handler)), &out
+}
+
+// decode returns the entries written to the buffer
+func decode(t *testing.T, out *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid entry %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestFields(t *testing.T) {
+	tests := []struct {
+		name  string
+		field logger.Field
+		want  interface{}
+	}{
+		{name: "string", field: logger.String("key", "value"), want: "value"},
+		{name: "strings", field: logger.Strings("key", []string{"a", "b"}), want: []interface{}{"a", "b"}},
+		{name: "int", field: logger.Int("key", 42), want: 42.0},
+		{name: "bool", field: logger.Bool("key", true), want: true},
+		{name: "duration", field: logger.Duration("key", time.Second), want: float64(time.Second)},
+		{name: "error", field: logger.Err(errors.New("failed")), want: "failed"},
+		{name: "stringer", field: logger.Stringer("key", net.IPv4(10, 0, 0, 1)), want: "10.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, out := newJSON(slog.LevelDebug)
+			l.Info("message", tt.field)
+
+			if got := decode(t, out)[0][tt.field.Key]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("field = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevels(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(l logger.Logger)
+		want string
+	}{
+		{name: "debug", log: func(l logger.Logger) { l.Debug("message") }},
+		{name: "info", log: func(l logger.Logger) { l.Info("message") }, want: "INFO"},
+		{name: "warn", log: func(l logger.Logger) { l.Warn("message") }, want: "WARN"},
+		{name: "error", log: func(l logger.Logger) { l.Error("message") }, want: "ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, out := newJSON(slog.LevelInfo)
+			tt.log(l)
+
+			entries := decode(t, out)
+			if tt.want == "" {
+				if len(entries) != 0 {
+					t.Fatalf("entries below the level of the handler = %v, want none", entries)
+				}
+				return
+			}
+			if len(entries) != 1 || entries[0]["level"] != tt.want {
+				t.Fatalf("entries = %v, want one at %s", entries, tt.want)
+			}
+		})
+	}
+}
+
+func TestWith(t *testing.T) {
+	parent, out := newJSON(slog.LevelDebug)
+
+	parent.With(logger.String("request_id", "abc")).Info("child")
+	parent.Info("parent")
+
+	entries := decode(t, out)
+	if entries[0]["request_id"] != "abc" {
+		t.Errorf("child request_id = %v, want abc", entries[0]["request_id"])
+	}
+	if _, ok := entries[1]["request_id"]; ok {
+		t.Errorf("parent has the fields of the child: %v", entries[1])
+	}
+}
+
+func TestSource(t *testing.T) {
+	l, out := newJSON(slog.LevelDebug)
+	l.Info("message")
+
+	source, _ := decode(t, out)[0][slog.SourceKey].(map[string]interface{})
+	if file, _ := source["file"].(string); !strings.HasSuffix(file, "logger_test.go") {
+		t.Errorf("source = %v, want the test", source)
+	}
+}
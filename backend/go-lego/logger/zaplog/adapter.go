@@ -0,0 +1,88 @@
+package zaplog
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Adapt returns the backend-neutral logger writing to the same zap logger
+func Adapt(l Logger) logger.Logger {
+	if zl, ok := l.(*zaplogger); ok {
+		return &adapter{logger: zl.logger}
+	}
+	return &adapter{logger: zap.L().WithOptions(zap.AddCallerSkip(1))}
+}
+
+// NewAdapter returns a backend-neutral logger writing to the zap logger
+func NewAdapter(l *zap.Logger) logger.Logger {
+	return &adapter{logger: l.WithOptions(zap.AddCallerSkip(1))}
+}
+
+// adapter implements logger.Logger converting its fields into zap fields
+type adapter struct {
+	logger *zap.Logger
+}
+
+// Debug logs an debug message with fields
+func (a *adapter) Debug(message string, fields ...logger.Field) {
+	a.logger.Debug(message, zapFields(fields)...)
+}
+
+// Info logs an info message with fields
+func (a *adapter) Info(message string, fields ...logger.Field) {
+	a.logger.Info(message, zapFields(fields)...)
+}
+
+// Warn logs a warning with fields
+func (a *adapter) Warn(message string, fields ...logger.Field) {
+	a.logger.Warn(message, zapFields(fields)...)
+}
+
+// Error logs an error message with fields
+func (a *adapter) Error(message string, fields ...logger.Field) {
+	a.logger.Error(message, zapFields(fields)...)
+}
+
+// With returns a child logger that adds the fields to every entry
+func (a *adapter) With(fields ...logger.Field) logger.Logger {
+	return &adapter{logger: a.logger.With(zapFields(fields)...)}
+}
+
+func zapFields(fields []logger.Field) []zapcore.Field {
+	converted := make([]zapcore.Field, 0, len(fields))
+	for _, field := range fields {
+		converted = append(converted, zapField(field))
+	}
+	return converted
+}
+
+func zapField(field logger.Field) zapcore.Field {
+	switch value := field.Value.(type) {
+	case string:
+		return zap.String(field.Key, value)
+	case []string:
+		return zap.Strings(field.Key, value)
+	case int:
+		return zap.Int(field.Key, value)
+	case int64:
+		return zap.Int64(field.Key, value)
+	case float64:
+		return zap.Float64(field.Key, value)
+	case bool:
+		return zap.Bool(field.Key, value)
+	case time.Duration:
+		return zap.Duration(field.Key, value)
+	case time.Time:
+		return zap.Time(field.Key, value)
+	case error:
+		return zap.NamedError(field.Key, value)
+	case fmt.Stringer:
+		return zap.Stringer(field.Key, value)
+	default:
+		return zap.Any(field.Key, value)
+	}
+}
@@ -0,0 +1,142 @@
+package zaplog
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestAdapterFields(t *testing.T) {
+	at := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		field logger.Field
+		want  interface{}
+	}{
+		{name: "string", field: logger.String("key", "value"), want: "value"},
+		{name: "strings", field: logger.Strings("key", []string{"a", "b"}), want: []interface{}{"a", "b"}},
+		{name: "int", field: logger.Int("key", 42), want: int64(42)},
+		{name: "int64", field: logger.Int64("key", 42), want: int64(42)},
+		{name: "float64", field: logger.Float64("key", 1.5), want: 1.5},
+		{name: "bool", field: logger.Bool("key", true), want: true},
+		{name: "duration", field: logger.Duration("key", time.Second), want: time.Second},
+		{name: "time", field: logger.Time("key", at), want: at},
+		{name: "error", field: logger.Field{Key: "key", Value: errors.New("failed")}, want: "failed"},
+		{name: "stringer", field: logger.Stringer("key", net.IPv4(10, 0, 0, 1)), want: "10.0.0.1"},
+		{name: "any", field: logger.Any("key", map[string]int{"a": 1}), want: map[string]int{"a": 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+			NewAdapter(zap.New(core)).Info("message", tt.field)
+
+			got := logs.All()[0].ContextMap()["key"]
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("field = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdapterLevels(t *testing.T) {
+	tests := []struct {
+		name  string
+		log   func(l logger.Logger)
+		level zapcore.Level
+	}{
+		{name: "debug", log: func(l logger.Logger) { l.Debug("message") }, level: zapcore.DebugLevel},
+		{name: "info", log: func(l logger.Logger) { l.Info("message") }, level: zapcore.InfoLevel},
+		{name: "warn", log: func(l logger.Logger) { l.Warn("message") }, level: zapcore.WarnLevel},
+		{name: "error", log: func(l logger.Logger) { l.Error("message") }, level: zapcore.ErrorLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.InfoLevel)
+			tt.log(NewAdapter(zap.New(core)))
+
+			entries := logs.All()
+			if tt.level < zapcore.InfoLevel {
+				if len(entries) != 0 {
+					t.Fatalf("entries below the level of the core = %v, want none", entries)
+				}
+				return
+			}
+			if len(entries) != 1 || entries[0].Level != tt.level {
+				t.Fatalf("entries = %v, want one at %s", entries, tt.level)
+			}
+		})
+	}
+}
+
+func TestAdapterWith(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	parent := NewAdapter(zap.New(core))
+
+	child := parent.With(logger.String("request_id", "abc"))
+	child.Info("child", logger.Int("status", 200))
+	parent.Info("parent")
+
+	entries := logs.All()
+	want := map[string]interface{}{"request_id": "abc", "status": int64(200)}
+	if got := entries[0].ContextMap(); !reflect.DeepEqual(got, want) {
+		t.Errorf("child fields = %v, want %v", got, want)
+	}
+	if got := entries[1].ContextMap(); len(got) != 0 {
+		t.Errorf("parent fields = %v, want none", got)
+	}
+}
+
+func TestAdapterCaller(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	NewAdapter(zap.New(core, zap.AddCaller())).Info("message")
+
+	if caller := logs.All()[0].Caller; !caller.Defined || !strings.HasSuffix(caller.File, "adapter_test.go") {
+		t.Errorf("caller = %s, want the test", caller)
+	}
+}
+
+func TestContextPropagation(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	ctx := IntoContext(context.Background(), New(zap.New(core)).With(zap.String("request_id", "abc")))
+
+	// the same logger is reachable through both interfaces
+	logger.FromContext(ctx).Info("neutral", logger.String("key", "value"))
+	FromContext(ctx).Info("zap", zap.String("key", "value"))
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("entries = %v, want 2", entries)
+	}
+	for _, entry := range entries {
+		want := map[string]interface{}{"request_id": "abc", "key": "value"}
+		if got := entry.ContextMap(); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s fields = %v, want %v", entry.Message, got, want)
+		}
+	}
+}
+
+func TestFromContextWithoutZap(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	defer zap.ReplaceGlobals(zap.New(core))()
+
+	// a logger of another backend can't be converted, so the global logger is used
+	recorder := logger.NewRecorder()
+	ctx := logger.IntoContext(context.Background(), recorder)
+	FromContext(ctx).Info("message")
+
+	if len(recorder.Entries()) != 0 || logs.Len() != 1 {
+		t.Errorf("recorded %d and observed %d entries, want 0 and 1", len(recorder.Entries()), logs.Len())
+	}
+}
@@ -0,0 +1,24 @@
+package zaplog
+
+import (
+	"context"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger"
+	"go.uber.org/zap"
+)
+
+// IntoContext returns a copy of the context carrying the logger. It is also returned by
+// logger.FromContext, adapted to the backend-neutral interface
+func IntoContext(ctx context.Context, l Logger) context.Context {
+	return logger.IntoContext(ctx, Adapt(l))
+}
+
+// FromContext returns the logger of the context, usually seeded with the fields of the current
+// request. The global logger is returned when the context has none or it isn't backed by zap
+func FromContext(ctx context.Context) Logger {
+	if a, ok := logger.FromContext(ctx).(*adapter); ok {
+		return &zaplogger{logger: a.logger}
+	}
+
+	return &zaplogger{logger: zap.L().WithOptions(zap.AddCallerSkip(1))}
+}
@@ -0,0 +1,147 @@
+package zaplog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Level is the minimum level of a logger, which can be changed while the application runs. A changed
+// level may revert to the default level automatically, so a debug session doesn't stay enabled forever
+type Level struct {
+	atomic zap.AtomicLevel
+
+	mu           sync.Mutex
+	defaultLevel zapcore.Level
+	overridden   bool
+	revert       *time.Timer
+	revertAt     time.Time
+}
+
+// LevelState describes the current level of a logger. Overridden tells whether the level was set
+// explicitly, in which case it is kept when the default level changes until it is reset or reverted
+type LevelState struct {
+	Level      string     `json:"level"`
+	Default    string     `json:"default"`
+	Overridden bool       `json:"overridden"`
+	RevertAt   *time.Time `json:"revertAt,omitempty"`
+}
+
+// NewLevel creates a level from its name (debug, info, warn, error...). An empty name means info
+func NewLevel(name string) (*Level, error) {
+	defaultLevel, err := parseLevel(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Level{
+		atomic:       zap.NewAtomicLevelAt(defaultLevel),
+		defaultLevel: defaultLevel,
+	}, nil
+}
+
+// Set overrides the level until Reset is called. When revertAfter is positive, the default level is
+// restored after it
+func (l *Level) Set(name string, revertAfter time.Duration) error {
+	level, err := parseLevel(name)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.stopRevert()
+	l.atomic.SetLevel(level)
+	l.overridden = true
+	if revertAfter > 0 {
+		// the timer only reverts the level it was created for, not a newer one set while it fired
+		var timer *time.Timer
+		timer = time.AfterFunc(revertAfter, func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+
+			if l.revert == timer {
+				l.restore()
+			}
+		})
+		l.revert = timer
+		l.revertAt = time.Now().Add(revertAfter)
+	}
+
+	return nil
+}
+
+// SetDefault changes the default level, e.g. after the configuration is reloaded. The current level
+// is only changed when it isn't overridden by Set
+func (l *Level) SetDefault(name string) error {
+	level, err := parseLevel(name)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.defaultLevel = level
+	if !l.overridden {
+		l.atomic.SetLevel(level)
+	}
+
+	return nil
+}
+
+// Reset restores the default level
+func (l *Level) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.restore()
+}
+
+// State returns the current and default levels
+func (l *Level) State() LevelState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state := LevelState{
+		Level:      l.atomic.Level().String(),
+		Default:    l.defaultLevel.String(),
+		Overridden: l.overridden,
+	}
+	if l.revert != nil {
+		revertAt := l.revertAt
+		state.RevertAt = &revertAt
+	}
+
+	return state
+}
+
+// restore ends the override and restores the default level. It must be called holding the lock
+func (l *Level) restore() {
+	l.stopRevert()
+	l.overridden = false
+	l.atomic.SetLevel(l.defaultLevel)
+}
+
+// stopRevert cancels the scheduled revert. It must be called holding the lock
+func (l *Level) stopRevert() {
+	if l.revert != nil {
+		l.revert.Stop()
+		l.revert = nil
+	}
+}
+
+func parseLevel(name string) (zapcore.Level, error) {
+	level := zapcore.InfoLevel
+	if name == "" {
+		return level, nil
+	}
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return level, fmt.Errorf("invalid log level %q: %w", name, err)
+	}
+	return level, nil
+}
@@ -0,0 +1,145 @@
+package zaplog
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "", want: "info"},
+		{name: "debug", want: "debug"},
+		{name: "WARN", want: "warn"},
+		{name: "verbose", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level, err := NewLevel(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewLevel(%q) error = %v, want error %v", tt.name, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if state := level.State(); state.Level != tt.want || state.Default != tt.want || state.Overridden {
+				t.Errorf("NewLevel(%q) state = %+v, want %s and not overridden", tt.name, state, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevelSet(t *testing.T) {
+	level, _ := NewLevel("info")
+
+	if err := level.Set("verbose", 0); err == nil {
+		t.Fatal("Set() with an invalid level error = nil, want an error")
+	}
+	if err := level.Set("debug", 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	state := level.State()
+	if state.Level != "debug" || state.Default != "info" || !state.Overridden || state.RevertAt != nil {
+		t.Errorf("State() = %+v, want debug overriding info without revert", state)
+	}
+	if !level.atomic.Enabled(zapcore.DebugLevel) {
+		t.Error("debug entries are disabled, want them enabled")
+	}
+
+	level.Reset()
+	if state := level.State(); state.Level != "info" || state.Overridden {
+		t.Errorf("State() after Reset = %+v, want info not overridden", state)
+	}
+}
+
+func TestLevelRevert(t *testing.T) {
+	level, _ := NewLevel("info")
+
+	before := time.Now()
+	if err := level.Set("debug", 20*time.Millisecond); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	state := level.State()
+	if state.RevertAt == nil || state.RevertAt.Before(before) {
+		t.Fatalf("State() = %+v, want the revert time", state)
+	}
+
+	waitForLevel(t, level, "info")
+	if state := level.State(); state.Overridden || state.RevertAt != nil {
+		t.Errorf("State() after revert = %+v, want it not overridden", state)
+	}
+}
+
+func TestLevelRevertIsCanceled(t *testing.T) {
+	level, _ := NewLevel("info")
+
+	// a newer level replaces the scheduled revert, and Reset cancels it
+	_ = level.Set("debug", 20*time.Millisecond)
+	_ = level.Set("warn", 0)
+	time.Sleep(50 * time.Millisecond)
+	if state := level.State(); state.Level != "warn" || state.RevertAt != nil {
+		t.Errorf("State() = %+v, want warn without revert", state)
+	}
+
+	_ = level.Set("debug", 20*time.Millisecond)
+	level.Reset()
+	_ = level.Set("error", 0)
+	time.Sleep(50 * time.Millisecond)
+	if state := level.State(); state.Level != "error" {
+		t.Errorf("State() = %+v, want error kept after the canceled revert", state)
+	}
+}
+
+func TestLevelSetDefault(t *testing.T) {
+	level, _ := NewLevel("info")
+
+	if err := level.SetDefault("verbose"); err == nil {
+		t.Fatal("SetDefault() with an invalid level error = nil, want an error")
+	}
+
+	// without override the current level follows the default
+	if err := level.SetDefault("warn"); err != nil {
+		t.Fatalf("SetDefault() error = %v", err)
+	}
+	if state := level.State(); state.Level != "warn" || state.Default != "warn" {
+		t.Errorf("State() = %+v, want warn", state)
+	}
+
+	// an explicit level is kept, with or without revert, until it is reset
+	_ = level.Set("debug", 0)
+	_ = level.SetDefault("error")
+	if state := level.State(); state.Level != "debug" || state.Default != "error" {
+		t.Errorf("State() = %+v, want debug overriding error", state)
+	}
+	level.Reset()
+	if state := level.State(); state.Level != "error" {
+		t.Errorf("State() after Reset = %+v, want the new default", state)
+	}
+
+	_ = level.Set("debug", 20*time.Millisecond)
+	_ = level.SetDefault("info")
+	if state := level.State(); state.Level != "debug" {
+		t.Errorf("State() = %+v, want debug until the revert", state)
+	}
+	waitForLevel(t, level, "info")
+}
+
+// waitForLevel waits until the level reverts to the wanted one
+func waitForLevel(t *testing.T, level *Level, want string) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for level.State().Level != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("level = %s, want %s", level.State().Level, want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -1,12 +1,26 @@
 package zaplog
 
 import (
+	"os"
+
 	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
+// NewCustomZap creates a logger with the configured log level
 func NewCustomZap(config logger.Config) (Logger, error) {
+	level, err := NewLevel(config.LogLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCustomZapWithLevel(config, level)
+}
+
+// NewCustomZapWithLevel creates a logger whose level is controlled by the given Level, so it can be
+// changed while the application runs. The configured log level is ignored
+func NewCustomZapWithLevel(config logger.Config, level *Level) (Logger, error) {
 	var logConfig zap.Config
 
 	if config.Production {
@@ -25,26 +39,39 @@ func NewCustomZap(config logger.Config) (Logger, error) {
 		logConfig.EncoderConfig.CallerKey = "caller"
 	}
 
-	// Parse and verify given configuration log level
-	if config.LogLevel != "" {
-		errLogLevel := logConfig.Level.UnmarshalText([]byte(config.LogLevel))
-		if errLogLevel != nil {
-			panic(errLogLevel)
-		}
+	// files are always written as JSON, so they can be shipped and parsed later
+	var encoder zapcore.Encoder
+	if logConfig.Encoding == "json" {
+		encoder = zapcore.NewJSONEncoder(logConfig.EncoderConfig)
+	} else {
+		encoder = zapcore.NewConsoleEncoder(logConfig.EncoderConfig)
+	}
+	fileEncoderConfig := zap.NewProductionEncoderConfig()
+	fileEncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	fileEncoderConfig.TimeKey = "@timestamp"
+	fileEncoder := zapcore.NewJSONEncoder(fileEncoderConfig)
+
+	core, err := newCore(config, encoder, fileEncoder, level)
+	if err != nil {
+		return nil, err
 	}
 
 	// create zaplogger with configurations
-	zapLog, err := logConfig.Build(
+	options := []zap.Option{
 		// supplying this option prevents zaplog from always reporting the wrapper code as the caller
 		zap.AddCallerSkip(1),
-	)
-	if err != nil {
-		return nil, err
+		zap.ErrorOutput(zapcore.Lock(os.Stderr)),
+		zap.Fields(staticFields(config)...),
+	}
+	if !logConfig.DisableCaller {
+		options = append(options, zap.AddCaller())
 	}
+	zapLog := zap.New(core, options...)
 	defer zapLog.Sync()
 
-	// Override zaplog default zaplogger
+	// Override the default loggers of zap and of the backend-neutral logger.FromContext
 	zap.ReplaceGlobals(zapLog)
+	logger.SetDefault(&adapter{logger: zapLog})
 
 	return &zaplogger{logger: zapLog}, nil
 }
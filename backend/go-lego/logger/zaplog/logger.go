@@ -16,6 +16,8 @@ type Logger interface {
 	Fatal(message string, fields ...zapcore.Field)
 	CheckErr(message string, err error, fields ...zapcore.Field)
 	SafeClose(closer io.Closer, message string)
+	With(fields ...zapcore.Field) Logger
+	Sync() error
 }
 
 // zaplogger delegates all calls to the underlying zaplog.Logger
@@ -23,6 +25,11 @@ type zaplogger struct {
 	logger *zap.Logger
 }
 
+// New wraps an existing zap logger, e.g. zap.NewNop() in tests
+func New(l *zap.Logger) Logger {
+	return &zaplogger{logger: l}
+}
+
 // Debug logs an debug message with fields
 func (l *zaplogger) Debug(message string, fields ...zapcore.Field) {
 	l.logger.Debug(message, fields...)
@@ -48,6 +55,16 @@ func (l *zaplogger) Fatal(message string, fields ...zapcore.Field) {
 	l.logger.Fatal(message, fields...)
 }
 
+// With returns a child logger that adds the fields to every entry
+func (l *zaplogger) With(fields ...zapcore.Field) Logger {
+	return &zaplogger{logger: l.logger.With(fields...)}
+}
+
+// Sync flushes the buffered entries of the outputs
+func (l *zaplogger) Sync() error {
+	return l.logger.Sync()
+}
+
 // SafeClose closes a Closer and log a message of error in case it happened
 func (l *zaplogger) SafeClose(closer io.Closer, message string) {
 	err := closer.Close()
@@ -0,0 +1,127 @@
+package zaplog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// newCore creates one core per output, all sharing the level. Files use their own encoder. Registered
+// secrets are redacted by every encoder
+func newCore(config logger.Config, encoder zapcore.Encoder, fileEncoder zapcore.Encoder, level *Level) (zapcore.Core, error) {
+	outputs := config.Outputs
+	if len(outputs) == 0 {
+		outputs = []logger.Output{{Type: logger.OutputStderr}}
+	}
+
+	cores := make([]zapcore.Core, 0, len(outputs))
+	for _, output := range outputs {
+		sink, err := newSink(output)
+		if err != nil {
+			return nil, err
+		}
+		enabler, err := newLevelRange(output, level)
+		if err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(output.Type, logger.OutputFile) {
+			cores = append(cores, zapcore.NewCore(newRedactEncoder(fileEncoder.Clone()), sink, enabler))
+		} else {
+			cores = append(cores, zapcore.NewCore(newRedactEncoder(encoder.Clone()), sink, enabler))
+		}
+	}
+	core := zapcore.NewTee(cores...)
+
+	if sampling := samplingOf(config); sampling != nil {
+		tick := sampling.Tick
+		if tick <= 0 {
+			tick = time.Second
+		}
+		core = zapcore.NewSamplerWithOptions(core, tick, sampling.Initial, sampling.Thereafter)
+	}
+
+	return core, nil
+}
+
+func newSink(output logger.Output) (zapcore.WriteSyncer, error) {
+	switch strings.ToLower(output.Type) {
+	case logger.OutputStdout:
+		return zapcore.Lock(os.Stdout), nil
+	case logger.OutputStderr, "":
+		return zapcore.Lock(os.Stderr), nil
+	case logger.OutputFile:
+		if output.Path == "" {
+			return nil, errors.New("log file output requires a path")
+		}
+		// lumberjack is safe for concurrent use and rotates the file by itself
+		return zapcore.AddSync(&lumberjack.Logger{
+			Filename:   output.Path,
+			MaxSize:    output.MaxSizeMB,
+			MaxAge:     output.MaxAgeDays,
+			MaxBackups: output.MaxBackups,
+			Compress:   output.Compress,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown log output type %q", output.Type)
+	}
+}
+
+// newLevelRange enables the levels of the output range that are also enabled by the logger level
+func newLevelRange(output logger.Output, level *Level) (zapcore.LevelEnabler, error) {
+	min, max := zapcore.DebugLevel, zapcore.FatalLevel
+	if output.MinLevel != "" {
+		if err := min.UnmarshalText([]byte(output.MinLevel)); err != nil {
+			return nil, fmt.Errorf("invalid log output min level %q: %w", output.MinLevel, err)
+		}
+	}
+	if output.MaxLevel != "" {
+		if err := max.UnmarshalText([]byte(output.MaxLevel)); err != nil {
+			return nil, fmt.Errorf("invalid log output max level %q: %w", output.MaxLevel, err)
+		}
+	}
+
+	return zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return l >= min && l <= max && level.atomic.Enabled(l)
+	}), nil
+}
+
+// samplingOf returns the configured sampling, or zap's defaults for production loggers
+func samplingOf(config logger.Config) *logger.Sampling {
+	if config.Sampling != nil {
+		if config.Sampling.Initial <= 0 && config.Sampling.Thereafter <= 0 {
+			return nil
+		}
+		return config.Sampling
+	}
+	if config.Production {
+		return &logger.Sampling{Initial: 100, Thereafter: 100, Tick: time.Second}
+	}
+	return nil
+}
+
+// staticFields returns the fields added to every entry
+func staticFields(config logger.Config) []zapcore.Field {
+	var fields []zapcore.Field
+	if config.AppName != "" {
+		fields = append(fields, zap.String("app", config.AppName))
+	}
+	if config.Version != "" {
+		fields = append(fields, zap.String("version", config.Version))
+	}
+	if config.Hostname {
+		if hostname, err := os.Hostname(); err == nil {
+			fields = append(fields, zap.String("hostname", hostname))
+		}
+	}
+	for key, value := range config.Fields {
+		fields = append(fields, zap.String(key, value))
+	}
+	return fields
+}
@@ -0,0 +1,200 @@
+package zaplog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// newTestCore creates the core of the outputs with JSON encoders and the level
+func newTestCore(t *testing.T, config logger.Config, levelName string) zapcore.Core {
+	t.Helper()
+
+	level, err := NewLevel(levelName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoder := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	core, err := newCore(config, encoder, encoder, level)
+	if err != nil {
+		t.Fatalf("newCore() error = %v", err)
+	}
+	return core
+}
+
+// logEveryLevel writes one entry per level, whose message is the level name
+func logEveryLevel(core zapcore.Core) {
+	log := zap.New(core)
+	log.Debug("debug")
+	log.Info("info")
+	log.Warn("warn")
+	log.Error("error")
+	_ = log.Sync()
+}
+
+// readMessages returns the messages written to the file, in order
+func readMessages(t *testing.T, path string) []string {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+
+	var messages []string
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		if _, after, ok := strings.Cut(line, `"msg":"`); ok {
+			message, _, _ := strings.Cut(after, `"`)
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+func TestOutputLevelRanges(t *testing.T) {
+	dir := t.TempDir()
+	info, errors, all := filepath.Join(dir, "info.log"), filepath.Join(dir, "errors.log"), filepath.Join(dir, "all.log")
+
+	core := newTestCore(t, logger.Config{Outputs: []logger.Output{
+		{Type: logger.OutputFile, Path: info, MaxLevel: "info"},
+		{Type: logger.OutputFile, Path: errors, MinLevel: "warn"},
+		{Type: logger.OutputFile, Path: all},
+	}}, "debug")
+	logEveryLevel(core)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: info, want: "debug,info"},
+		{path: errors, want: "warn,error"},
+		{path: all, want: "debug,info,warn,error"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(readMessages(t, tt.path), ","); got != tt.want {
+			t.Errorf("%s messages = %s, want %s", filepath.Base(tt.path), got, tt.want)
+		}
+	}
+}
+
+func TestOutputRangesFollowTheLevel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	core := newTestCore(t, logger.Config{Outputs: []logger.Output{
+		{Type: logger.OutputFile, Path: path, MaxLevel: "warn"},
+	}}, "info")
+	logEveryLevel(core)
+
+	// the logger level removes debug, the output range removes error
+	if got := strings.Join(readMessages(t, path), ","); got != "info,warn" {
+		t.Errorf("messages = %s, want info,warn", got)
+	}
+}
+
+func TestOutputStandardStreams(t *testing.T) {
+	dir := t.TempDir()
+	stdout, err := os.Create(filepath.Join(dir, "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr, err := os.Create(filepath.Join(dir, "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the sinks are bound to the streams when the core is created
+	originalStdout, originalStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = stdout, stderr
+	core := newTestCore(t, logger.Config{Outputs: []logger.Output{
+		{Type: "STDOUT", MaxLevel: "info"},
+		{Type: logger.OutputStderr, MinLevel: "warn"},
+	}}, "debug")
+	os.Stdout, os.Stderr = originalStdout, originalStderr
+
+	logEveryLevel(core)
+	stdout.Close()
+	stderr.Close()
+
+	if got := strings.Join(readMessages(t, stdout.Name()), ","); got != "debug,info" {
+		t.Errorf("stdout messages = %s, want debug,info", got)
+	}
+	if got := strings.Join(readMessages(t, stderr.Name()), ","); got != "warn,error" {
+		t.Errorf("stderr messages = %s, want warn,error", got)
+	}
+}
+
+func TestNewCoreErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		output logger.Output
+	}{
+		{name: "unknown type", output: logger.Output{Type: "syslog"}},
+		{name: "file without path", output: logger.Output{Type: logger.OutputFile}},
+		{name: "invalid min level", output: logger.Output{Type: logger.OutputStdout, MinLevel: "verbose"}},
+		{name: "invalid max level", output: logger.Output{Type: logger.OutputStdout, MaxLevel: "verbose"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level, _ := NewLevel("info")
+			encoder := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+			if _, err := newCore(logger.Config{Outputs: []logger.Output{tt.output}}, encoder, encoder, level); err == nil {
+				t.Error("newCore() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestOutputSampling(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	core := newTestCore(t, logger.Config{
+		Outputs:  []logger.Output{{Type: logger.OutputFile, Path: path}},
+		Sampling: &logger.Sampling{Initial: 2, Thereafter: 3, Tick: time.Hour},
+	}, "info")
+	log := zap.New(core)
+	for i := 0; i < 8; i++ {
+		log.Info("repeated")
+	}
+	log.Info("other")
+	_ = log.Sync()
+
+	// the first 2 entries with the same message, then one of every 3: the 5th and the 8th
+	if got := len(readMessages(t, path)); got != 5 {
+		t.Errorf("written entries = %d, want 5", got)
+	}
+}
+
+func TestSamplingOf(t *testing.T) {
+	configured := &logger.Sampling{Initial: 10, Thereafter: 10}
+
+	tests := []struct {
+		name   string
+		config logger.Config
+		want   *logger.Sampling
+	}{
+		{name: "development", config: logger.Config{}},
+		{
+			name:   "production defaults",
+			config: logger.Config{Production: true},
+			want:   &logger.Sampling{Initial: 100, Thereafter: 100, Tick: time.Second},
+		},
+		{name: "configured", config: logger.Config{Sampling: configured}, want: configured},
+		{name: "disabled in production", config: logger.Config{Production: true, Sampling: &logger.Sampling{}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := samplingOf(tt.config)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("samplingOf() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package zaplog
+
+import (
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/secret"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+var redactedBuffers = buffer.NewPool()
+
+// redactEncoder replaces the registered secrets in the encoded entries, whatever field or message
+// they were written in
+type redactEncoder struct {
+	zapcore.Encoder
+}
+
+func newRedactEncoder(encoder zapcore.Encoder) zapcore.Encoder {
+	return &redactEncoder{Encoder: encoder}
+}
+
+func (e *redactEncoder) Clone() zapcore.Encoder {
+	return &redactEncoder{Encoder: e.Encoder.Clone()}
+}
+
+func (e *redactEncoder) EncodeEntry(entry zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	encoded, err := e.Encoder.EncodeEntry(entry, fields)
+	if err != nil || !secret.Any() {
+		return encoded, err
+	}
+
+	redacted := redactedBuffers.Get()
+	redacted.AppendString(secret.Redact(encoded.String()))
+	encoded.Free()
+	return redacted, nil
+}
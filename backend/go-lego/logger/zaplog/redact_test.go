@@ -0,0 +1,64 @@
+package zaplog
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/secret"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRedactEncoder(t *testing.T) {
+	secret.Register("redact-encoder-secret", `redact"encoder\secret`)
+
+	tests := []struct {
+		name    string
+		encoder zapcore.Encoder
+	}{
+		{name: "json", encoder: zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())},
+		{name: "console", encoder: zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			core := zapcore.NewCore(newRedactEncoder(tt.encoder), zapcore.AddSync(&out), zapcore.DebugLevel)
+			log := zap.New(core).With(zap.String("dsn", "postgres://blog:redact-encoder-secret@db"))
+
+			log.Info("connecting with redact-encoder-secret",
+				zap.String("password", "redact-encoder-secret"),
+				zap.Error(errors.New("auth failed for redact-encoder-secret")),
+				zap.String("escaped", `redact"encoder\secret`),
+				zap.String("user", "blog"))
+
+			got := out.String()
+			if strings.Contains(got, "redact-encoder-secret") || strings.Contains(got, `encoder\\secret`) {
+				t.Errorf("the entry contains a secret: %s", got)
+			}
+			if count := strings.Count(got, secret.Redacted); count != 5 {
+				t.Errorf("the entry has %d redacted values, want 5: %s", count, got)
+			}
+			if !strings.Contains(got, "blog") {
+				t.Errorf("the entry lost the other values: %s", got)
+			}
+		})
+	}
+}
+
+func TestRedactEncoderClone(t *testing.T) {
+	secret.Register("redact-clone-secret")
+
+	var out bytes.Buffer
+	encoder := newRedactEncoder(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()))
+	encoder.AddString("token", "redact-clone-secret")
+
+	core := zapcore.NewCore(encoder.Clone(), zapcore.AddSync(&out), zapcore.DebugLevel)
+	zap.New(core).Info("message")
+
+	if got := out.String(); strings.Contains(got, "redact-clone-secret") || !strings.Contains(got, secret.Redacted) {
+		t.Errorf("the entry of the clone isn't redacted: %s", got)
+	}
+}
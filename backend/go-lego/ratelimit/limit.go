@@ -0,0 +1,129 @@
+// Package ratelimit limits the rate of HTTP requests with token buckets
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+// ErrTooManyRequests is returned to clients that exceeded the limit
+var ErrTooManyRequests = errors.New("too many requests, retry later")
+
+// Limit is the rate of a token bucket: Requests are allowed every Period, with bursts of up to Burst
+// requests. Burst defaults to Requests
+type Limit struct {
+	Requests int           `validate:"min=0"`
+	Period   time.Duration `validate:"min=1ms"`
+	Burst    int           `validate:"min=0"`
+}
+
+// Validate checks that requests and period are informed together
+func (l *Limit) Validate() error {
+	if (l.Requests > 0) != (l.Period > 0) {
+		return errors.New("requests and period must be informed together")
+	}
+	return nil
+}
+
+// Enabled checks if the limit restricts any request
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0
+}
+
+// capacity returns the size of the bucket
+func (l Limit) capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.Requests)
+}
+
+// perSecond returns how many tokens are added to the bucket every second
+func (l Limit) perSecond() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Result is the state of a bucket after taking a token from it
+type Result struct {
+	// Allowed is true when the bucket had a token for the request
+	Allowed bool
+
+	// Remaining is the quantity of requests that are still allowed right now
+	Remaining int
+
+	// RetryAfter is how long until the next request is allowed. It is zero for allowed requests
+	RetryAfter time.Duration
+
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+}
+
+// Store keeps the buckets of all keys. The in-memory store works for a single instance, a shared
+// store (e.g. Redis) is needed to limit requests across instances
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+
+	// Peek returns the state of the bucket of the key without taking a token from it. Allowed tells if
+	// a token is available
+	Peek(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+// bucket is the state of a token bucket
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// take refills the bucket until now and takes a token from it when available
+func (b *bucket) take(limit Limit, now time.Time) Result {
+	b.refill(limit, now)
+	if b.tokens < 1 {
+		return b.rejected(limit)
+	}
+
+	b.tokens--
+	result := b.state(limit)
+	result.Allowed = true
+	return result
+}
+
+// peek refills the bucket until now and tells if a token is available, without taking it
+func (b *bucket) peek(limit Limit, now time.Time) Result {
+	b.refill(limit, now)
+	if b.tokens < 1 {
+		return b.rejected(limit)
+	}
+
+	result := b.state(limit)
+	result.Allowed = true
+	return result
+}
+
+// refill adds the tokens of the time elapsed since the last update
+func (b *bucket) refill(limit Limit, now time.Time) {
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(limit.capacity(), b.tokens+elapsed*limit.perSecond())
+		b.updated = now
+	}
+}
+
+// rejected returns the state of a bucket without tokens, with the time until the next one
+func (b *bucket) rejected(limit Limit) Result {
+	result := b.state(limit)
+	result.RetryAfter = seconds((1 - b.tokens) / limit.perSecond())
+	return result
+}
+
+// state returns the remaining tokens of the bucket and the time until it is full
+func (b *bucket) state(limit Limit) Result {
+	return Result{
+		Remaining: int(math.Floor(b.tokens)),
+		Reset:     seconds((limit.capacity() - b.tokens) / limit.perSecond()),
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often the buckets that are full again are removed from memory
+const sweepInterval = time.Minute
+
+// MemoryStore keeps the buckets in the memory of this instance
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	lastSweep time.Time
+}
+
+type memoryBucket struct {
+	bucket
+	fullAt time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*memoryBucket{}}
+}
+
+// Take takes a token from the bucket of the key, creating a full bucket for new keys
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &memoryBucket{bucket: bucket{tokens: limit.capacity(), updated: now}}
+		s.buckets[key] = b
+	}
+
+	result := b.take(limit, now)
+	b.fullAt = now.Add(result.Reset)
+
+	return result, nil
+}
+
+// Peek returns the state of the bucket of the key, a full one for new keys, without taking a token
+func (s *MemoryStore) Peek(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := bucket{tokens: limit.capacity(), updated: now}
+	if existing, ok := s.buckets[key]; ok {
+		b = existing.bucket
+	}
+
+	return b.peek(limit, now), nil
+}
+
+// sweep removes the buckets that are full again, since they are the same as new ones
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if !now.Before(b.fullAt) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreTake(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limit := Limit{Requests: 2, Period: time.Second}
+
+	// every step takes a token at the given offset from the start
+	type step struct {
+		at   time.Duration
+		want Result
+	}
+	tests := []struct {
+		name  string
+		limit Limit
+		steps []step
+	}{
+		{
+			name:  "new keys start with a full bucket",
+			limit: limit,
+			steps: []step{
+				{at: 0, want: Result{Allowed: true, Remaining: 1, Reset: 500 * time.Millisecond}},
+				{at: 0, want: Result{Allowed: true, Remaining: 0, Reset: time.Second}},
+				{at: 0, want: Result{RetryAfter: 500 * time.Millisecond, Reset: time.Second}},
+			},
+		},
+		{
+			name:  "tokens are refilled with time",
+			limit: limit,
+			steps: []step{
+				{at: 0, want: Result{Allowed: true, Remaining: 1, Reset: 500 * time.Millisecond}},
+				{at: 0, want: Result{Allowed: true, Remaining: 0, Reset: time.Second}},
+				{at: 500 * time.Millisecond, want: Result{Allowed: true, Remaining: 0, Reset: time.Second}},
+				{at: 2 * time.Second, want: Result{Allowed: true, Remaining: 1, Reset: 500 * time.Millisecond}},
+			},
+		},
+		{
+			name:  "burst larger than the rate",
+			limit: Limit{Requests: 1, Period: time.Second, Burst: 3},
+			steps: []step{
+				{at: 0, want: Result{Allowed: true, Remaining: 2, Reset: time.Second}},
+				{at: 0, want: Result{Allowed: true, Remaining: 1, Reset: 2 * time.Second}},
+				{at: 0, want: Result{Allowed: true, Remaining: 0, Reset: 3 * time.Second}},
+				{at: 0, want: Result{RetryAfter: time.Second, Reset: 3 * time.Second}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStore()
+			for i, step := range tt.steps {
+				got, err := store.Take(context.Background(), "key", tt.limit, start.Add(step.at))
+				if err != nil {
+					t.Fatalf("step %d: Take() error = %v", i, err)
+				}
+				if got != step.want {
+					t.Errorf("step %d: Take() = %+v, want %+v", i, got, step.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limit := Limit{Requests: 1, Period: time.Hour}
+
+	tests := []struct {
+		name string
+		// the other key takes a token at this offset, which may sweep the bucket of "key"
+		at   time.Duration
+		want bool
+	}{
+		{name: "before the sweep interval", at: sweepInterval / 2, want: true},
+		{name: "bucket not full yet", at: sweepInterval, want: true},
+		{name: "bucket full again", at: time.Hour, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStore()
+			ctx := context.Background()
+			if _, err := store.Take(ctx, "key", limit, start); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := store.Take(ctx, "other", limit, start.Add(tt.at)); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, ok := store.buckets["key"]; ok != tt.want {
+				t.Errorf("bucket kept = %t, want %t", ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryStorePeek(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limit := Limit{Requests: 1, Period: time.Second}
+
+	tests := []struct {
+		name  string
+		takes int
+		at    time.Duration
+		want  Result
+	}{
+		{name: "new key", want: Result{Allowed: true, Remaining: 1}},
+		{name: "empty bucket", takes: 1, want: Result{RetryAfter: time.Second, Reset: time.Second}},
+		{name: "refilled bucket", takes: 1, at: time.Second, want: Result{Allowed: true, Remaining: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStore()
+			ctx := context.Background()
+			for i := 0; i < tt.takes; i++ {
+				if _, err := store.Take(ctx, "key", limit, start); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// peeking twice returns the same, since no token is taken
+			for i := 0; i < 2; i++ {
+				got, err := store.Peek(ctx, "key", limit, start.Add(tt.at))
+				if err != nil {
+					t.Fatalf("Peek() error = %v", err)
+				}
+				if got != tt.want {
+					t.Errorf("Peek() = %+v, want %+v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	chimiddleware "github.com/go-chi/chi/middleware"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/httphandler/response"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger"
+)
+
+// KeyFunc returns the key identifying the client of the request, e.g. its user or IP address
+type KeyFunc func(r *http.Request) string
+
+// Limiter creates rate limiting middlewares for groups of routes
+type Limiter struct {
+	logger logger.Logger
+	store  Store
+	groups atomic.Value
+	key    KeyFunc
+}
+
+// NewLimiter creates a limiter using the limits of every route group
+func NewLimiter(logger logger.Logger, store Store, groups map[string]Limit, key KeyFunc) *Limiter {
+	l := &Limiter{
+		logger: logger,
+		store:  store,
+		key:    key,
+	}
+	l.SetGroups(groups)
+	return l
+}
+
+// SetGroups replaces the limits of every route group. The buckets are kept, so clients don't get
+// a new burst when the limits change
+func (l *Limiter) SetGroups(groups map[string]Limit) {
+	l.groups.Store(groups)
+}
+
+// Middleware limits the requests of the group. Every client has its own bucket in each group.
+// Groups without a configured limit are not limited
+func (l *Limiter) Middleware(group string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit := l.groups.Load().(map[string]Limit)[group]
+			if !limit.Enabled() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := l.key(r)
+			result, err := l.store.Take(r.Context(), group+"|"+key, limit, time.Now())
+			if err != nil {
+				// requests are allowed when the store is unavailable, so it doesn't take the API down
+				l.logger.Error("Unable to check rate limit", logger.String("group", group), logger.Err(err))
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(int(limit.capacity())))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+			if !result.Allowed {
+				l.reject(w, r, group, key, result)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Failures limits the failed requests of the group, e.g. the ones with invalid credentials. Only the
+// requests answered with one of the statuses take a token, and clients without tokens left are rejected
+// before reaching the handler, so failures can't be retried at the cost of the handler. Concurrent
+// requests of a client may all pass the check before the first failure is counted
+func (l *Limiter) Failures(group string, statuses ...int) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit := l.groups.Load().(map[string]Limit)[group]
+			if !limit.Enabled() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := l.key(r)
+			result, err := l.store.Peek(r.Context(), group+"|"+key, limit, time.Now())
+			if err != nil {
+				l.logger.Error("Unable to check rate limit", logger.String("group", group), logger.Err(err))
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !result.Allowed {
+				l.reject(w, r, group, key, result)
+				return
+			}
+
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+			if !failed(ww.Status(), statuses) {
+				return
+			}
+
+			if _, err := l.store.Take(r.Context(), group+"|"+key, limit, time.Now()); err != nil {
+				l.logger.Error("Unable to count failed request", logger.String("group", group), logger.Err(err))
+			}
+		})
+	}
+}
+
+// reject answers 429 Too Many Requests to a client without tokens left
+func (l *Limiter) reject(w http.ResponseWriter, r *http.Request, group string, key string, result Result) {
+	l.logger.Warn("Request rejected by rate limit",
+		logger.String("group", group), logger.String("key", key),
+		logger.String("method", r.Method), logger.String("path", r.URL.Path))
+
+	w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+	response.WithJSONError(w, r, http.StatusTooManyRequests, ErrTooManyRequests)
+}
+
+// failed checks if the status is one of the failure statuses
+func failed(status int, statuses []int) bool {
+	for _, failure := range statuses {
+		if status == failure {
+			return true
+		}
+	}
+	return false
+}
+
+// ceilSeconds rounds the duration up to whole seconds, as used by the rate limit headers
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,245 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger"
+)
+
+// clientKey identifies the clients of the tests by their address
+func clientKey(r *http.Request) string {
+	return r.RemoteAddr
+}
+
+// statusHandler answers every request with the status of its "status" query parameter, or 200 OK
+func statusHandler(calls *int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		if r.URL.Query().Get("status") == "401" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// failingStore is a store that is unavailable
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, Limit, time.Time) (Result, error) {
+	return Result{}, errors.New("store unavailable")
+}
+
+func (failingStore) Peek(context.Context, string, Limit, time.Time) (Result, error) {
+	return Result{}, errors.New("store unavailable")
+}
+
+// send sends a request of the client to the handler
+func send(handler http.Handler, client string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = client
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func TestMiddleware(t *testing.T) {
+	limit := Limit{Requests: 1, Period: time.Hour, Burst: 2}
+	limiter := NewLimiter(logger.NewRecorder(), NewMemoryStore(), map[string]Limit{"api": limit}, clientKey)
+	calls := 0
+	handler := limiter.Middleware("api")(statusHandler(&calls))
+
+	tests := []struct {
+		client        string
+		wantStatus    int
+		wantRemaining string
+		wantReset     string
+	}{
+		{client: "a", wantStatus: http.StatusOK, wantRemaining: "1", wantReset: "3600"},
+		{client: "a", wantStatus: http.StatusOK, wantRemaining: "0", wantReset: "7200"},
+		{client: "a", wantStatus: http.StatusTooManyRequests, wantRemaining: "0", wantReset: "7200"},
+		{client: "b", wantStatus: http.StatusOK, wantRemaining: "1", wantReset: "3600"},
+	}
+
+	for i, tt := range tests {
+		w := send(handler, tt.client)
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("request %d: status = %d, want %d", i, w.Code, tt.wantStatus)
+		}
+		headers := map[string]string{
+			"RateLimit-Limit":     "2",
+			"RateLimit-Remaining": tt.wantRemaining,
+			"RateLimit-Reset":     tt.wantReset,
+		}
+		for name, want := range headers {
+			if got := w.Header().Get(name); got != want {
+				t.Errorf("request %d: %s = %q, want %q", i, name, got, want)
+			}
+		}
+
+		wantRetryAfter := ""
+		if tt.wantStatus == http.StatusTooManyRequests {
+			wantRetryAfter = "3600"
+		}
+		if got := w.Header().Get("Retry-After"); got != wantRetryAfter {
+			t.Errorf("request %d: Retry-After = %q, want %q", i, got, wantRetryAfter)
+		}
+	}
+	if calls != 3 {
+		t.Errorf("handler calls = %d, want 3", calls)
+	}
+}
+
+func TestMiddlewareWithoutLimit(t *testing.T) {
+	limiter := NewLimiter(logger.NewRecorder(), NewMemoryStore(), map[string]Limit{"api": {}}, clientKey)
+	calls := 0
+
+	for _, group := range []string{"api", "unknown"} {
+		w := send(limiter.Middleware(group)(statusHandler(&calls)), "a")
+		if w.Code != http.StatusOK || w.Header().Get("RateLimit-Limit") != "" {
+			t.Errorf("group %s: status = %d with RateLimit-Limit %q, want 200 without limit", group, w.Code,
+				w.Header().Get("RateLimit-Limit"))
+		}
+	}
+}
+
+func TestMiddlewareFailsOpen(t *testing.T) {
+	groups := map[string]Limit{"api": {Requests: 1, Period: time.Hour}}
+	recorder := logger.NewRecorder()
+	limiter := NewLimiter(recorder, failingStore{}, groups, clientKey)
+	calls := 0
+
+	handlers := map[string]http.Handler{
+		"Middleware": limiter.Middleware("api")(statusHandler(&calls)),
+		"Failures":   limiter.Failures("api", http.StatusUnauthorized)(statusHandler(&calls)),
+	}
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			recorder.Reset()
+			calls = 0
+
+			for i := 0; i < 3; i++ {
+				if w := send(handler, "a"); w.Code != http.StatusOK {
+					t.Errorf("request %d: status = %d, want %d", i, w.Code, http.StatusOK)
+				}
+			}
+			if calls != 3 {
+				t.Errorf("handler calls = %d, want 3", calls)
+			}
+
+			entries := recorder.Find("Unable to check rate limit")
+			if len(entries) != 3 || entries[0].Level != logger.LevelError {
+				t.Errorf("logged errors = %+v, want 3", entries)
+			}
+		})
+	}
+}
+
+func TestSetGroups(t *testing.T) {
+	limiter := NewLimiter(logger.NewRecorder(), NewMemoryStore(),
+		map[string]Limit{"api": {Requests: 1, Period: time.Hour}}, clientKey)
+	calls := 0
+	// the middleware is created once, like when the routes are mounted
+	handler := limiter.Middleware("api")(statusHandler(&calls))
+
+	if w := send(handler, "a"); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if w := send(handler, "a"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+
+	// the new limits apply to the existing middleware
+	limiter.SetGroups(map[string]Limit{})
+	if w := send(handler, "a"); w.Code != http.StatusOK {
+		t.Errorf("status without limit = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	// the bucket is kept, so a larger limit doesn't give a new burst right away
+	limiter.SetGroups(map[string]Limit{"api": {Requests: 1, Period: time.Hour, Burst: 5}})
+	w := send(handler, "a")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("RateLimit-Limit") != "5" {
+		t.Errorf("status = %d with RateLimit-Limit %q, want %d with 5", w.Code, w.Header().Get("RateLimit-Limit"),
+			http.StatusTooManyRequests)
+	}
+}
+
+func TestFailures(t *testing.T) {
+	limit := Limit{Requests: 1, Period: time.Hour, Burst: 2}
+
+	// every request is sent by a client, and fails when asked to
+	type request struct {
+		client     string
+		fail       bool
+		wantStatus int
+	}
+	tests := []struct {
+		name      string
+		requests  []request
+		wantCalls int
+	}{
+		{
+			name: "successful requests aren't counted",
+			requests: []request{
+				{client: "a", wantStatus: http.StatusOK},
+				{client: "a", wantStatus: http.StatusOK},
+				{client: "a", wantStatus: http.StatusOK},
+			},
+			wantCalls: 3,
+		},
+		{
+			name: "clients without tokens are rejected before the handler",
+			requests: []request{
+				{client: "a", fail: true, wantStatus: http.StatusUnauthorized},
+				{client: "a", fail: true, wantStatus: http.StatusUnauthorized},
+				{client: "a", fail: true, wantStatus: http.StatusTooManyRequests},
+				{client: "a", wantStatus: http.StatusTooManyRequests},
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "every client has its own bucket",
+			requests: []request{
+				{client: "a", fail: true, wantStatus: http.StatusUnauthorized},
+				{client: "a", fail: true, wantStatus: http.StatusUnauthorized},
+				{client: "b", fail: true, wantStatus: http.StatusUnauthorized},
+			},
+			wantCalls: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewLimiter(logger.NewRecorder(), NewMemoryStore(), map[string]Limit{"failures": limit}, clientKey)
+			calls := 0
+			handler := limiter.Failures("failures", http.StatusUnauthorized)(statusHandler(&calls))
+
+			for i, request := range tt.requests {
+				target := "/"
+				if request.fail {
+					target = "/?status=401"
+				}
+				r := httptest.NewRequest(http.MethodGet, target, nil)
+				r.RemoteAddr = request.client
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, r)
+
+				if w.Code != request.wantStatus {
+					t.Errorf("request %d: status = %d, want %d", i, w.Code, request.wantStatus)
+				}
+				if w.Code == http.StatusTooManyRequests && w.Header().Get("Retry-After") != "3600" {
+					t.Errorf("request %d: Retry-After = %q, want 3600", i, w.Header().Get("Retry-After"))
+				}
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("handler calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+// Package secret keeps the values that must never be written to logs or configuration dumps
+package secret
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Redacted replaces the secret values
+const Redacted = "[REDACTED]"
+
+// minLength is the length from which values are registered, shorter ones would redact common text
+const minLength = 4
+
+var (
+	mu       sync.Mutex
+	values   = map[string]bool{}
+	replacer atomic.Value
+
+	// registered is read on every log entry, so it doesn't take the lock
+	registered int32
+)
+
+func init() {
+	replacer.Store(strings.NewReplacer())
+}
+
+// Register marks the values as secrets, so Redact replaces them. Values shorter than 4 characters are
+// ignored
+func Register(secrets ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	changed := false
+	for _, value := range secrets {
+		if len(value) >= minLength && !values[value] {
+			values[value] = true
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	// longer secrets first, so a secret containing another one is fully replaced
+	sorted := make([]string, 0, len(values))
+	for value := range values {
+		sorted = append(sorted, value)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+
+	pairs := make([]string, 0, len(sorted)*4)
+	for _, value := range sorted {
+		pairs = append(pairs, value, Redacted)
+		// the JSON encoders write the value escaped
+		if escaped := jsonEscape(value); escaped != value {
+			pairs = append(pairs, escaped, Redacted)
+		}
+	}
+	replacer.Store(strings.NewReplacer(pairs...))
+	atomic.StoreInt32(&registered, 1)
+}
+
+// Redact replaces every secret found in the text
+func Redact(text string) string {
+	return replacer.Load().(*strings.Replacer).Replace(text)
+}
+
+// Any tells whether a secret has been registered
+func Any() bool {
+	return atomic.LoadInt32(&registered) == 1
+}
+
+func jsonEscape(value string) string {
+	escaped, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	return string(escaped[1 : len(escaped)-1])
+}
@@ -0,0 +1,38 @@
+package secret
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	Register("s3cret-password", "s3cret-password-with-suffix", `quote"and\backslash`, "abc", "")
+
+	if !Any() {
+		t.Fatal("Any() = false, want true after registering secrets")
+	}
+
+	quoted, _ := json.Marshal(`quote"and\backslash`)
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "no secret", in: "plain text", want: "plain text"},
+		{name: "whole value", in: "s3cret-password", want: Redacted},
+		{name: "inside a value", in: "postgres://blog:s3cret-password@db", want: "postgres://blog:" + Redacted + "@db"},
+		{name: "every occurrence", in: "s3cret-password s3cret-password", want: Redacted + " " + Redacted},
+		{name: "longest secret first", in: "s3cret-password-with-suffix", want: Redacted},
+		{name: "JSON escaped", in: `{"dsn":` + string(quoted) + `}`, want: `{"dsn":"` + Redacted + `"}`},
+		{name: "short values are ignored", in: "abc", want: "abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Redact(tt.in); got != tt.want {
+				t.Errorf("Redact(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package slug
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database/postgres"
+)
+
+// Entry is a row of a history table, keeping a slug previously used by a record
+type Entry interface {
+	// RecordID returns the id of the record that used the slug
+	RecordID() *uuid.UUID
+}
+
+// NewEntry creates the row of the history table, a pointer to a struct mapped with db tags. Resolve scans
+// into an entry created with zero values
+type NewEntry func(slug string, recordID uuid.UUID, createdAt time.Time) Entry
+
+// History generates unique slugs for the records of a table, identified by their "id" and "slug" columns, and
+// keeps the slugs they used before in a history table, so old URLs can still be resolved
+type History struct {
+	records  postgres.Pg
+	history  postgres.Pg
+	idColumn string
+	newEntry NewEntry
+}
+
+// NewHistory creates the history of the records. idColumn is the column of the history table referencing
+// the record, e.g. "post_id"
+func NewHistory(records postgres.Pg, history postgres.Pg, idColumn string, newEntry NewEntry) *History {
+	return &History{
+		records:  records,
+		history:  history,
+		idColumn: idColumn,
+		newEntry: newEntry,
+	}
+}
+
+// Resolve returns the id of the record that used the slug in the past. The error is sql.ErrNoRows when
+// no record used it
+func (h *History) Resolve(ctx context.Context, slug string) (uuid.UUID, error) {
+	entry := h.newEntry("", uuid.Nil, time.Time{})
+	if err := h.history.FindOne(ctx, sq.Eq{"slug": slug}, entry); err != nil {
+		return uuid.Nil, err
+	}
+
+	return *entry.RecordID(), nil
+}
+
+// Unique derives a slug from the text that isn't used, now or in the past, by other records. Texts without
+// any transliterable character fall back to the beginning of the record id
+func (h *History) Unique(ctx context.Context, tx *postgres.Tx, text string, recordID uuid.UUID) (string, error) {
+	base := Make(text)
+	if base == "" {
+		base = recordID.String()[:8]
+	}
+
+	recordsTx := h.records.WithTx(tx)
+	historyTx := h.history.WithTx(tx)
+	return Unique(base, func(candidate string) (bool, error) {
+		count, err := recordsTx.Count(ctx, sq.And{sq.Eq{"slug": candidate}, sq.NotEq{"id": recordID}})
+		if err != nil || count > 0 {
+			return count > 0, err
+		}
+
+		count, err = historyTx.Count(ctx, sq.And{sq.Eq{"slug": candidate}, sq.NotEq{h.idColumn: recordID}})
+		return count > 0, err
+	})
+}
+
+// Change generates the slug of a record whose text changed, and keeps its current slug in the history. The
+// current slug is returned when it still matches the text
+func (h *History) Change(ctx context.Context, tx *postgres.Tx, text string, recordID uuid.UUID,
+	current *string) (string, error) {
+	newSlug, err := h.Unique(ctx, tx, text, recordID)
+	if err != nil {
+		return "", err
+	}
+	if current != nil && *current == newSlug {
+		return newSlug, nil
+	}
+
+	historyTx := h.history.WithTx(tx)
+
+	// the record may be going back to one of its old slugs, which must leave the history
+	_, err = historyTx.Remove(ctx, sq.Eq{"slug": newSlug, h.idColumn: recordID}, true)
+	if err != nil {
+		return "", err
+	}
+
+	if current != nil {
+		err = historyTx.Insert(ctx, h.newEntry(*current, recordID, time.Now().UTC()), nil)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return newSlug, nil
+}
@@ -0,0 +1,86 @@
+// Package slug contains methods to create URL friendly identifiers from texts
+package slug
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxLength is the maximum quantity of characters of a slug, without the collision suffix
+const maxLength = 80
+
+// maxAttempts limits how many collision suffixes are tried before giving up
+const maxAttempts = 1000
+
+// transliterations contains the letters that don't decompose into an ASCII letter plus diacritics
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'Æ': "ae", 'œ': "oe", 'Œ': "oe", 'ø': "o", 'Ø': "o",
+	'ł': "l", 'Ł': "l", 'đ': "d", 'Đ': "d", 'ð': "d", 'Ð': "d", 'þ': "th", 'Þ': "th",
+	'ı': "i", '&': "and",
+}
+
+// Make converts the text into a lowercase slug made of ASCII letters, digits and hyphens
+func Make(text string) string {
+	// decompose letters and drop their diacritics (e.g. "é" becomes "e")
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	normalized, _, err := transform.String(t, text)
+	if err != nil {
+		normalized = text
+	}
+
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range normalized {
+		replacement, ok := transliterations[r]
+		if !ok {
+			replacement = string(unicode.ToLower(r))
+		}
+
+		for _, c := range replacement {
+			if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') {
+				if pendingHyphen && b.Len() > 0 {
+					b.WriteByte('-')
+				}
+				pendingHyphen = false
+				b.WriteRune(c)
+			} else {
+				// any other character separates words
+				pendingHyphen = true
+			}
+		}
+	}
+
+	slug := b.String()
+	if len(slug) > maxLength {
+		slug = strings.TrimRight(slug[:maxLength], "-")
+	}
+
+	return slug
+}
+
+// Unique returns the first candidate made from the base slug that is not taken, appending
+// "-2", "-3", ... when needed
+func Unique(base string, taken func(candidate string) (bool, error)) (string, error) {
+	if base == "" {
+		return "", fmt.Errorf("slug must not be empty")
+	}
+
+	candidate := base
+	for i := 2; i <= maxAttempts+1; i++ {
+		exists, err := taken(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", base, i)
+	}
+
+	return "", fmt.Errorf("unable to find a free slug for %q", base)
+}
@@ -0,0 +1,75 @@
+package slug
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "lowercase words", text: "Hello World", want: "hello-world"},
+		{name: "diacritics removed", text: "Café com pão", want: "cafe-com-pao"},
+		{name: "transliterated letters", text: "Straße & Œuvre", want: "strasse-and-oeuvre"},
+		{name: "separators collapsed", text: "  Go -- 1.22!  ", want: "go-1-22"},
+		{name: "digits kept", text: "Top 10 tips", want: "top-10-tips"},
+		{name: "nothing transliterable", text: "日本語", want: ""},
+		{name: "empty", text: "", want: ""},
+		{name: "cut at the maximum length", text: strings.Repeat("a", maxLength+10), want: strings.Repeat("a", maxLength)},
+		{
+			name: "no hyphen left at the cut",
+			text: strings.Repeat("a", maxLength-1) + " bcd",
+			want: strings.Repeat("a", maxLength-1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Make(tt.text); got != tt.want {
+				t.Errorf("Make(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnique(t *testing.T) {
+	errDatabase := errors.New("database unavailable")
+
+	tests := []struct {
+		name    string
+		base    string
+		taken   map[string]bool
+		err     error
+		want    string
+		wantErr bool
+	}{
+		{name: "free base", base: "hello", want: "hello"},
+		{name: "first suffix", base: "hello", taken: map[string]bool{"hello": true}, want: "hello-2"},
+		{
+			name:  "next free suffix",
+			base:  "hello",
+			taken: map[string]bool{"hello": true, "hello-2": true, "hello-3": true},
+			want:  "hello-4",
+		},
+		{name: "empty base", base: "", wantErr: true},
+		{name: "lookup error", base: "hello", err: errDatabase, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Unique(tt.base, func(candidate string) (bool, error) {
+				return tt.taken[candidate], tt.err
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unique() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Unique() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
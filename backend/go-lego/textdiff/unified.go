@@ -0,0 +1,267 @@
+// Package textdiff contains methods to compare texts line by line
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the quantity of unchanged lines printed around every change
+const contextLines = 3
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+type op struct {
+	kind opKind
+	line string
+	// position of the line in the old and new text (0-based)
+	oldPos int
+	newPos int
+}
+
+// Unified returns the unified diff between oldText and newText. An empty string is returned when both texts are equal
+func Unified(oldName, newName, oldText, newText string) string {
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+
+	var hunks []string
+	for _, h := range groupHunks(ops) {
+		hunks = append(hunks, formatHunk(ops[h[0]:h[1]]))
+	}
+
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("--- %s\n+++ %s\n%s", oldName, newName, strings.Join(hunks, ""))
+}
+
+// splitLines splits the text into lines, ignoring the empty line after a trailing newline
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines computes the shortest edit script between a and b with the linear space variant of the Myers
+// algorithm, so large texts can be compared without a quadratic table
+func diffLines(a, b []string) []op {
+	d := &differ{a: a, b: b, ops: make([]op, 0, len(a)+len(b))}
+	d.compare(0, len(a), 0, len(b))
+	return d.ops
+}
+
+// differ appends the edit script of a and b to ops, in order
+type differ struct {
+	a   []string
+	b   []string
+	ops []op
+}
+
+// compare appends the edit script of a[aLo:aHi] and b[bLo:bHi]. The common prefix and suffix are removed
+// first, then both texts are split at the middle snake of their shortest edit path and compared recursively
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.ops = append(d.ops, op{kind: opEqual, line: d.a[aLo], oldPos: aLo, newPos: bLo})
+		aLo++
+		bLo++
+	}
+	suffix := 0
+	for aLo < aHi-suffix && bLo < bHi-suffix && d.a[aHi-suffix-1] == d.b[bHi-suffix-1] {
+		suffix++
+	}
+	aHi -= suffix
+	bHi -= suffix
+
+	if aLo < aHi && bLo < bHi {
+		if x, y, ok := middleSnake(d.a[aLo:aHi], d.b[bLo:bHi]); ok {
+			d.compare(aLo, aLo+x, bLo, bLo+y)
+			d.compare(aLo+x, aHi, bLo+y, bHi)
+			aLo, bLo = aHi, bHi
+		}
+	}
+
+	// what is left has nothing in common: one of the sides is empty, or no line is shared
+	for i := aLo; i < aHi; i++ {
+		d.ops = append(d.ops, op{kind: opDelete, line: d.a[i], oldPos: i, newPos: bLo})
+	}
+	for j := bLo; j < bHi; j++ {
+		d.ops = append(d.ops, op{kind: opInsert, line: d.b[j], oldPos: aHi, newPos: j})
+	}
+
+	for i := 0; i < suffix; i++ {
+		d.ops = append(d.ops, op{kind: opEqual, line: d.a[aHi+i], oldPos: aHi + i, newPos: bHi + i})
+	}
+}
+
+// middleSnake walks the shortest edit path of a and b from both ends at once, and returns the point where
+// both walks meet. Only the furthest point reached on every diagonal is kept, so the space is linear. It
+// returns false when a and b have no line in common
+func middleSnake(a, b []string) (int, int, bool) {
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset := maxD + 1
+	forward := make([]int, 2*offset+1)
+	backward := make([]int, 2*offset+1)
+	for i := range forward {
+		forward[i] = -1
+		backward[i] = -1
+	}
+	forward[offset+1] = 0
+	backward[offset+1] = 0
+
+	// when the difference of the lengths is odd, the walks can only meet during the forward one
+	delta := n - m
+	odd := delta%2 != 0
+
+	// diagonals that went past the end of a text are skipped in the next rounds
+	var fStart, fEnd, bStart, bEnd int
+	for D := 0; D <= maxD; D++ {
+		for k := -D + fStart; k <= D-fEnd; k += 2 {
+			var x int
+			if k == -D || (k != D && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+
+			switch {
+			case x > n:
+				fEnd += 2
+			case y > m:
+				fStart += 2
+			case odd:
+				// the backward walk on the same diagonal counts its steps from the end of the texts
+				if i := offset + delta - k; i >= 0 && i < len(backward) && backward[i] != -1 && x >= n-backward[i] {
+					return x, y, true
+				}
+			}
+		}
+
+		for k := -D + bStart; k <= D-bEnd; k += 2 {
+			var x int
+			if k == -D || (k != D && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+
+			switch {
+			case x > n:
+				bEnd += 2
+			case y > m:
+				bStart += 2
+			case !odd:
+				if i := offset + delta - k; i >= 0 && i < len(forward) && forward[i] != -1 {
+					fx := forward[i]
+					if fx >= n-x {
+						return fx, fx - (delta - k), true
+					}
+				}
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+// groupHunks returns the [start, end) ranges of ops that must be printed together
+func groupHunks(ops []op) [][2]int {
+	var hunks [][2]int
+	for i := 0; i < len(ops); i++ {
+		if ops[i].kind == opEqual {
+			continue
+		}
+
+		start := i - contextLines
+		if start < 0 {
+			start = 0
+		}
+
+		// extend the hunk while the next change is close enough to share context
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != opEqual {
+				end++
+				continue
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == opEqual {
+				next++
+			}
+			if next == len(ops) || next-end > 2*contextLines {
+				end += contextLines
+				if end > len(ops) {
+					end = len(ops)
+				}
+				break
+			}
+			end = next
+		}
+
+		// merge with the previous hunk when they overlap
+		if n := len(hunks); n > 0 && start <= hunks[n-1][1] {
+			hunks[n-1][1] = end
+		} else {
+			hunks = append(hunks, [2]int{start, end})
+		}
+		i = end - 1
+	}
+
+	return hunks
+}
+
+// formatHunk writes the hunk header followed by its lines
+func formatHunk(ops []op) string {
+	var oldCount, newCount int
+	var body strings.Builder
+	for _, o := range ops {
+		switch o.kind {
+		case opEqual:
+			oldCount++
+			newCount++
+			body.WriteString(" ")
+		case opDelete:
+			oldCount++
+			body.WriteString("-")
+		case opInsert:
+			newCount++
+			body.WriteString("+")
+		}
+		body.WriteString(o.line)
+		body.WriteString("\n")
+	}
+
+	return fmt.Sprintf("@@ -%s +%s @@\n%s",
+		hunkRange(ops[0].oldPos, oldCount), hunkRange(ops[0].newPos, newCount), body.String())
+}
+
+// hunkRange formats the line range of a hunk side as expected by the unified format
+func hunkRange(start, count int) string {
+	if count == 0 {
+		// an empty range points to the line before the change
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
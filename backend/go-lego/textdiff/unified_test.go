@@ -0,0 +1,138 @@
+package textdiff
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name    string
+		oldText string
+		newText string
+		want    string
+	}{
+		{
+			name:    "equal texts",
+			oldText: "a\nb\n",
+			newText: "a\nb\n",
+			want:    "",
+		},
+		{
+			name:    "both empty",
+			oldText: "",
+			newText: "",
+			want:    "",
+		},
+		{
+			name:    "added to an empty text",
+			oldText: "",
+			newText: "a\nb\n",
+			want:    "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:    "everything removed",
+			oldText: "a\nb\n",
+			newText: "",
+			want:    "--- old\n+++ new\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			name:    "changed line with context",
+			oldText: "1\n2\n3\n4\n5\n6\n7\n",
+			newText: "1\n2\n3\nfour\n5\n6\n7\n",
+			want:    "--- old\n+++ new\n@@ -1,7 +1,7 @@\n 1\n 2\n 3\n-4\n+four\n 5\n 6\n 7\n",
+		},
+		{
+			name:    "distant changes in separate hunks",
+			oldText: "a\n1\n2\n3\n4\n5\n6\n7\n8\nb\n",
+			newText: "A\n1\n2\n3\n4\n5\n6\n7\n8\nB\n",
+			want: "--- old\n+++ new\n@@ -1,4 +1,4 @@\n-a\n+A\n 1\n 2\n 3\n" +
+				"@@ -7,4 +7,4 @@\n 6\n 7\n 8\n-b\n+B\n",
+		},
+		{
+			name:    "close changes share a hunk",
+			oldText: "a\n1\n2\n3\nb\n",
+			newText: "A\n1\n2\n3\nB\n",
+			want:    "--- old\n+++ new\n@@ -1,5 +1,5 @@\n-a\n+A\n 1\n 2\n 3\n-b\n+B\n",
+		},
+		{
+			name:    "missing trailing newline",
+			oldText: "a\nb",
+			newText: "a\nc",
+			want:    "--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("old", "new", tt.oldText, tt.newText); got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffLinesIsShortest(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	lines := func() []string {
+		// few distinct lines, so the texts share many of them
+		text := make([]string, random.Intn(40))
+		for i := range text {
+			text[i] = string(rune('a' + random.Intn(4)))
+		}
+		return text
+	}
+
+	for i := 0; i < 500; i++ {
+		a, b := lines(), lines()
+		ops := diffLines(a, b)
+
+		// applying the script to a must give b
+		var oldLines, newLines []string
+		edits := 0
+		for _, o := range ops {
+			if o.oldPos != len(oldLines) || o.newPos != len(newLines) {
+				t.Fatalf("diffLines(%q, %q) has op %+v at the wrong position", a, b, o)
+			}
+			switch o.kind {
+			case opEqual:
+				oldLines = append(oldLines, o.line)
+				newLines = append(newLines, o.line)
+			case opDelete:
+				oldLines = append(oldLines, o.line)
+				edits++
+			case opInsert:
+				newLines = append(newLines, o.line)
+				edits++
+			}
+		}
+		if strings.Join(oldLines, "\n") != strings.Join(a, "\n") || strings.Join(newLines, "\n") != strings.Join(b, "\n") {
+			t.Fatalf("diffLines(%q, %q) doesn't transform one text into the other: %+v", a, b, ops)
+		}
+		if want := len(a) + len(b) - 2*lcsLength(a, b); edits != want {
+			t.Fatalf("diffLines(%q, %q) has %d edits, want %d", a, b, edits, want)
+		}
+	}
+}
+
+// lcsLength is the length of the longest common subsequence of a and b, computed with the quadratic table
+func lcsLength(a, b []string) int {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	return lcs[0][0]
+}
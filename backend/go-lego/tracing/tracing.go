@@ -0,0 +1,126 @@
+// Package tracing configures OpenTelemetry: the exporter of the spans, the W3C trace context propagation
+// and the helpers to start spans and correlate the log entries with them
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporters of the spans
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// tracerName is the instrumentation scope of the spans started by Start
+const tracerName = "github.com/thiagoretondar/golang-blog-example/backend/go-lego/tracing"
+
+// ErrMissingEndpoint is returned when the OTLP exporter is configured without an endpoint
+var ErrMissingEndpoint = errors.New("the otlp exporter requires an endpoint")
+
+// Config contains the configuration of the traces
+type Config struct {
+	// Exporter sends the spans to nowhere (none), to the standard output (stdout) or to an OTLP collector (otlp).
+	// The trace context is propagated even when the spans aren't exported
+	Exporter string `default:"none" validate:"oneof=none stdout otlp"`
+
+	// Endpoint is the host:port of the OTLP/HTTP collector
+	Endpoint string
+
+	// Insecure sends the spans to the collector over plain HTTP
+	Insecure bool
+
+	// Headers are sent with every export, usually to authenticate with the collector
+	Headers map[string]string `secret:"true"`
+
+	// SampleRatio of the traces started by this service, from 0 to 1. Traces continued from a caller follow its
+	// sampling decision. Every trace is sampled when it isn't informed
+	SampleRatio *float64 `validate:"min=0,max=1"`
+}
+
+// Validate checks that the OTLP exporter has somewhere to send the spans
+func (c *Config) Validate() error {
+	if c.Exporter == ExporterOTLP && c.Endpoint == "" {
+		return ErrMissingEndpoint
+	}
+	return nil
+}
+
+// Setup installs the global tracer provider and the W3C trace context propagator. The returned function flushes
+// the pending spans and must be called before the application exits
+func Setup(ctx context.Context, config Config, serviceName string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{},
+		propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch config.Exporter {
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		options := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(config.Endpoint),
+			otlptracehttp.WithHeaders(config.Headers),
+		}
+		if config.Insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, options...)
+	default:
+		return func(context.Context) error { return nil }, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the %s exporter: %w", config.Exporter, err)
+	}
+
+	ratio := 1.0
+	if config.SampleRatio != nil {
+		ratio = *config.SampleRatio
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Start starts a span as a child of the span of the context, if any. The span must be ended by the caller
+func Start(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, options...)
+}
+
+// Fields returns the ids of the span of the context, so log entries can be correlated with the traces
+func Fields(ctx context.Context) []logger.Field {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return nil
+	}
+
+	return []logger.Field{
+		logger.String("trace_id", spanContext.TraceID().String()),
+		logger.String("span_id", spanContext.SpanID().String()),
+	}
+}
+
+// LogFields returns the ids of the span of the request, to be used with the request logger and the access log
+func LogFields(r *http.Request) []logger.Field {
+	return Fields(r.Context())
+}
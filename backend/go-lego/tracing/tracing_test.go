@@ -0,0 +1,125 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// useRecorder installs a tracer provider that records the ended spans in memory, restoring the global one when the
+// test ends
+func useRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	return recorder
+}
+
+func TestStart(t *testing.T) {
+	recorder := useRecorder(t)
+
+	ctx, parent := Start(context.Background(), "parent")
+	_, child := Start(ctx, "child")
+	child.End()
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("ended spans = %d, want 2", len(spans))
+	}
+	if spans[0].Name() != "child" || spans[1].Name() != "parent" {
+		t.Fatalf("ended spans = %q and %q, want child and parent", spans[0].Name(), spans[1].Name())
+	}
+	if spans[0].Parent().SpanID() != spans[1].SpanContext().SpanID() {
+		t.Errorf("child parent = %s, want %s", spans[0].Parent().SpanID(), spans[1].SpanContext().SpanID())
+	}
+	if spans[0].SpanContext().TraceID() != spans[1].SpanContext().TraceID() {
+		t.Error("child and parent are in different traces")
+	}
+	if scope := spans[0].InstrumentationScope().Name; scope != tracerName {
+		t.Errorf("instrumentation scope = %q, want %q", scope, tracerName)
+	}
+}
+
+func TestFields(t *testing.T) {
+	useRecorder(t)
+
+	if fields := Fields(context.Background()); fields != nil {
+		t.Errorf("Fields() without a span = %v, want none", fields)
+	}
+
+	ctx, span := Start(context.Background(), "span")
+	defer span.End()
+
+	spanContext := trace.SpanContextFromContext(ctx)
+	want := map[string]string{
+		"trace_id": spanContext.TraceID().String(),
+		"span_id":  spanContext.SpanID().String(),
+	}
+	fields := Fields(ctx)
+	if len(fields) != len(want) {
+		t.Fatalf("Fields() = %v, want %v", fields, want)
+	}
+	for _, field := range fields {
+		if field.Value != want[field.Key] {
+			t.Errorf("Fields() %s = %v, want %s", field.Key, field.Value, want[field.Key])
+		}
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   error
+	}{
+		{name: "none", config: Config{Exporter: ExporterNone}},
+		{name: "stdout", config: Config{Exporter: ExporterStdout}},
+		{name: "otlp with an endpoint", config: Config{Exporter: ExporterOTLP, Endpoint: "collector:4318"}},
+		{name: "otlp without an endpoint", config: Config{Exporter: ExporterOTLP}, want: ErrMissingEndpoint},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); !errors.Is(err, tt.want) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetupWithoutExporter(t *testing.T) {
+	useRecorder(t)
+	provider := otel.GetTracerProvider()
+
+	shutdown, err := Setup(context.Background(), Config{Exporter: ExporterNone}, "blog")
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown() error = %v", err)
+	}
+	if otel.GetTracerProvider() != provider {
+		t.Error("Setup() without an exporter replaced the tracer provider")
+	}
+
+	// the trace context is still propagated to the callees
+	carrier := propagation.MapCarrier{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), carrier)
+	if traceID := trace.SpanContextFromContext(ctx).TraceID().String(); traceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("extracted trace id = %s, want the one of the traceparent", traceID)
+	}
+}
@@ -0,0 +1,95 @@
+// Package admin contains the operational endpoints used by administrators
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/featureflag"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/httphandler/request"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/httphandler/response"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/auth"
+	"go.uber.org/zap"
+)
+
+var errInvalidRevertAfter = errors.New("revertAfter must be a positive duration, e.g. 15m")
+
+type handler struct {
+	level *zaplog.Level
+	flags *featureflag.Flags
+}
+
+// LevelChange contains the data sent to change the log level. The level is kept when the configuration is
+// reloaded, until it is reset or reverted
+type LevelChange struct {
+	Level string `json:"level"`
+
+	// RevertAfter is an optional duration (e.g. "15m") after which the default level is restored
+	RevertAfter string `json:"revertAfter"`
+}
+
+// NewHandler creates the HTTP routes for administrators
+func NewHandler(level *zaplog.Level, flags *featureflag.Flags) http.Handler {
+	h := &handler{level: level, flags: flags}
+
+	r := chi.NewRouter()
+	r.Route("/loglevel", func(r chi.Router) {
+		r.Use(auth.Require(auth.PermissionManageLogging))
+		r.Get("/", h.getLogLevel)
+		r.Put("/", h.setLogLevel)
+		r.Delete("/", h.resetLogLevel)
+	})
+	r.With(auth.Require(auth.PermissionViewFeatureFlags)).Get("/flags", h.getFlags)
+
+	return r
+}
+
+func (h *handler) getLogLevel(w http.ResponseWriter, r *http.Request) {
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: h.level.State()})
+}
+
+func (h *handler) setLogLevel(w http.ResponseWriter, r *http.Request) {
+	var change LevelChange
+	if err := request.ParseBody(r, &change); err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	var revertAfter time.Duration
+	if change.RevertAfter != "" {
+		var err error
+		revertAfter, err = time.ParseDuration(change.RevertAfter)
+		if err != nil || revertAfter <= 0 {
+			response.WithJSONError(w, r, http.StatusBadRequest, errInvalidRevertAfter)
+			return
+		}
+	}
+
+	if err := h.level.Set(change.Level, revertAfter); err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	state := h.level.State()
+	zaplog.FromContext(r.Context()).Warn("Log level changed",
+		zap.String("level", state.Level), zap.Duration("revertAfter", revertAfter))
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: state})
+}
+
+func (h *handler) resetLogLevel(w http.ResponseWriter, r *http.Request) {
+	h.level.Reset()
+
+	state := h.level.State()
+	zaplog.FromContext(r.Context()).Warn("Log level reset", zap.String("level", state.Level))
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: state})
+}
+
+// getFlags lists every feature flag, where it is defined and whether it is on for the caller
+func (h *handler) getFlags(w http.ResponseWriter, r *http.Request) {
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: h.flags.States(r)})
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	// apiKeyPrefix identifies API keys, so they can be told apart from JWTs in the Authorization header
+	apiKeyPrefix = "blog_"
+
+	// apiKeySecretLength is the quantity of random bytes of an API key
+	apiKeySecretLength = 32
+)
+
+// APIKey gives non-interactive clients scoped access. Only the hash of the key is stored
+type APIKey struct {
+	ID          *uuid.UUID      `db:"id" json:"id,omitempty"`
+	Name        *string         `db:"name" json:"name,omitempty"`
+	Prefix      *string         `db:"prefix" json:"prefix,omitempty"`
+	KeyHash     *string         `db:"key_hash" json:"-"`
+	Permissions *pq.StringArray `db:"permissions" json:"permissions,omitempty"`
+	AuthorID    *uuid.UUID      `db:"author_id" json:"authorId,omitempty"`
+	CreatedBy   *uuid.UUID      `db:"created_by" json:"createdBy,omitempty"`
+	ExpiresAt   *time.Time      `db:"expires_at" json:"expiresAt,omitempty"`
+	LastUsedAt  *time.Time      `db:"last_used_at" json:"lastUsedAt,omitempty"`
+	RevokedAt   *time.Time      `db:"revoked_at" json:"revokedAt,omitempty"`
+	CreatedAt   *time.Time      `db:"created_at" json:"createdAt,omitempty"`
+}
+
+// NewAPIKey contains the data sent to create an API key
+type NewAPIKey struct {
+	Name        string       `json:"name"`
+	Permissions []Permission `json:"permissions"`
+	AuthorID    *uuid.UUID   `json:"authorId"`
+	ExpiresAt   *time.Time   `json:"expiresAt"`
+}
+
+// CreatedAPIKey is returned once after creating an API key. The key can't be recovered later
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// active checks if the key is not revoked nor expired
+func (k APIKey) active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// principal returns the identity of requests authenticated by the key
+func (k APIKey) principal() *Principal {
+	var permissions []Permission
+	if k.Permissions != nil {
+		for _, permission := range *k.Permissions {
+			permissions = append(permissions, Permission(permission))
+		}
+	}
+
+	return &Principal{
+		AuthorID:    k.AuthorID,
+		APIKeyID:    k.ID,
+		Permissions: permissions,
+	}
+}
+
+// generateAPIKey returns a new random key and its display prefix
+func generateAPIKey() (key string, prefix string, err error) {
+	secret := make([]byte, apiKeySecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	key = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	return key, key[:len(apiKeyPrefix)+8], nil
+}
+
+// hashAPIKey returns the stored hash of the key. Keys are long random values, so a fast hash is
+// enough and allows looking them up directly
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// isAPIKey checks if the credential looks like an API key
+func isAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, apiKeyPrefix)
+}
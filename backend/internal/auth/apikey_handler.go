@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/httphandler/request"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/httphandler/response"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"go.uber.org/zap"
+)
+
+type apiKeyHandler struct {
+	svc APIKeyService
+}
+
+// NewAPIKeyHandler creates the HTTP routes to manage API keys
+func NewAPIKeyHandler(svc APIKeyService) http.Handler {
+	h := &apiKeyHandler{svc: svc}
+
+	r := chi.NewRouter()
+	r.Use(Require(PermissionManageAPIKeys))
+	r.Post("/", h.create)
+	r.Get("/", h.getAll)
+	r.Delete("/{id}", h.revoke)
+
+	return r
+}
+
+func (h *apiKeyHandler) create(w http.ResponseWriter, r *http.Request) {
+	var newKey NewAPIKey
+	if err := request.ParseBody(r, &newKey); err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	created, err := h.svc.Create(r.Context(), newKey, PrincipalFromContext(r.Context()))
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusCreated, &response.HTTPResponse{Data: created})
+}
+
+func (h *apiKeyHandler) getAll(w http.ResponseWriter, r *http.Request) {
+	page, err := request.ParsePage(r)
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	keys, err := h.svc.GetAllPaginated(r.Context(), page)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: keys})
+}
+
+func (h *apiKeyHandler) revoke(w http.ResponseWriter, r *http.Request) {
+	id, err := request.UUIDParam(r, "id")
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.svc.Revoke(r.Context(), id); err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusNoContent, nil)
+}
+
+// withServiceError translates the errors returned by the APIKeyService into HTTP responses
+func (h *apiKeyHandler) withServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrAPIKeyNotFound):
+		response.WithJSONError(w, r, http.StatusNotFound, err)
+	case errors.Is(err, ErrInvalidAPIKeyScope), errors.Is(err, ErrInvalidAuthor):
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+	case errors.Is(err, ErrForbidden):
+		response.WithJSONError(w, r, http.StatusForbidden, err)
+	default:
+		zaplog.FromContext(r.Context()).Error("Unable to handle API key request", zap.String("path", r.URL.Path), zap.Error(err))
+		response.WithJSONError(w, r, http.StatusInternalServerError, nil)
+	}
+}
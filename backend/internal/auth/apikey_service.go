@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database/postgres"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/tracing"
+	"go.uber.org/zap"
+)
+
+// lastUsedResolution avoids writing the last use of a key on every request
+const lastUsedResolution = time.Minute
+
+var (
+	// ErrInvalidAPIKey is returned when the API key doesn't exist, is expired or was revoked
+	ErrInvalidAPIKey = errors.New("invalid, expired or revoked API key")
+
+	// ErrAPIKeyNotFound is returned when managing an API key that doesn't exist
+	ErrAPIKeyNotFound = errors.New("API key not found")
+
+	// ErrInvalidAPIKeyScope is returned when the name, permissions or expiry of a new key are invalid
+	ErrInvalidAPIKeyScope = errors.New("API key name and known permissions are required, and expiry must be in the future")
+)
+
+type APIKeyService interface {
+	Create(ctx context.Context, newKey NewAPIKey, createdBy *Principal) (*CreatedAPIKey, error)
+	GetAllPaginated(ctx context.Context, page database.Page) (*[]APIKey, error)
+	Revoke(ctx context.Context, ID uuid.UUID) error
+	Authenticate(ctx context.Context, key string) (*Principal, error)
+}
+
+type apiKeySvc struct {
+	logger zaplog.Logger
+	repo   APIKeyRepository
+}
+
+func NewAPIKeyService(logger zaplog.Logger, repo APIKeyRepository) APIKeyService {
+	return &apiKeySvc{
+		logger: logger,
+		repo:   repo,
+	}
+}
+
+// Create stores a new API key and returns it with the plain key, which is never shown again.
+// Keys created by a principal can't have permissions that the principal doesn't have. Keys created
+// from the command line have no creator
+func (s *apiKeySvc) Create(ctx context.Context, newKey NewAPIKey, createdBy *Principal) (*CreatedAPIKey, error) {
+	ctx, span := tracing.Start(ctx, "auth.APIKeyService.Create")
+	defer span.End()
+
+	now := time.Now().UTC()
+	name := strings.TrimSpace(newKey.Name)
+	if name == "" || len(newKey.Permissions) == 0 || (newKey.ExpiresAt != nil && !newKey.ExpiresAt.After(now)) {
+		return nil, ErrInvalidAPIKeyScope
+	}
+
+	permissions := make(pq.StringArray, 0, len(newKey.Permissions))
+	for _, permission := range newKey.Permissions {
+		if !permission.Valid() {
+			return nil, ErrInvalidAPIKeyScope
+		}
+		if createdBy != nil && !createdBy.Can(permission) {
+			return nil, ErrForbidden
+		}
+		permissions = append(permissions, string(permission))
+	}
+
+	key, prefix, err := generateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	hash := hashAPIKey(key)
+
+	id := uuid.New()
+	apiKey := APIKey{
+		ID:          &id,
+		Name:        &name,
+		Prefix:      &prefix,
+		KeyHash:     &hash,
+		Permissions: &permissions,
+		AuthorID:    newKey.AuthorID,
+		ExpiresAt:   newKey.ExpiresAt,
+		CreatedAt:   &now,
+	}
+	if createdBy != nil && createdBy.APIKeyID == nil {
+		apiKey.CreatedBy = &createdBy.UserID
+	}
+
+	err = s.repo.Insert(ctx, apiKey, nil)
+	if postgres.IsForeignKeyViolation(err) {
+		return nil, ErrInvalidAuthor
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &CreatedAPIKey{APIKey: apiKey, Key: key}, nil
+}
+
+// GetAllPaginated returns the API keys, newest first. Revoked and expired keys are included
+func (s *apiKeySvc) GetAllPaginated(ctx context.Context, page database.Page) (*[]APIKey, error) {
+	ctx, span := tracing.Start(ctx, "auth.APIKeyService.GetAllPaginated")
+	defer span.End()
+
+	page.OrderBy = []string{"created_at desc", "id"}
+
+	keys := []APIKey{}
+	err := s.repo.FindPage(ctx, nil, page, &keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &keys, nil
+}
+
+// Revoke disables the API key immediately. Revoking a revoked key keeps its revocation date
+func (s *apiKeySvc) Revoke(ctx context.Context, ID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "auth.APIKeyService.Revoke")
+	defer span.End()
+
+	updated, err := s.repo.Update(ctx, map[string]interface{}{
+		"revoked_at": time.Now().UTC(),
+	}, sq.Eq{"id": ID, "revoked_at": nil})
+	if err != nil {
+		return err
+	}
+	if updated > 0 {
+		return nil
+	}
+
+	exists, err := s.repo.Count(ctx, sq.Eq{"id": ID})
+	if err != nil {
+		return err
+	}
+	if exists == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// Authenticate returns the principal of an active API key and records its use
+func (s *apiKeySvc) Authenticate(ctx context.Context, key string) (*Principal, error) {
+	ctx, span := tracing.Start(ctx, "auth.APIKeyService.Authenticate")
+	defer span.End()
+
+	var apiKey APIKey
+	err := s.repo.FindOne(ctx, sq.Eq{"key_hash": hashAPIKey(key)}, &apiKey)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if !apiKey.active(now) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= lastUsedResolution {
+		_, err := s.repo.Update(ctx, map[string]interface{}{"last_used_at": now}, sq.Eq{"id": apiKey.ID})
+		if err != nil {
+			// the request is still authenticated when the last use can't be recorded
+			zaplog.FromContext(ctx).Warn("Unable to record API key use", zap.Stringer("apiKeyID", apiKey.ID), zap.Error(err))
+		}
+	}
+
+	return apiKey.principal(), nil
+}
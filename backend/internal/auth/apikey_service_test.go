@@ -0,0 +1,196 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"go.uber.org/zap"
+)
+
+// apiKeyColumns are the columns of the api_key table, in the order of the rows returned by apiKeyRow
+var apiKeyColumns = []string{
+	"id", "name", "prefix", "key_hash", "permissions", "author_id", "created_by", "expires_at", "last_used_at",
+	"revoked_at", "created_at",
+}
+
+// newMockAPIKeyService creates the service on top of the real repository, backed by a mocked database whose
+// expectations must be met in order
+func newMockAPIKeyService(t *testing.T) (APIKeyService, sqlmock.Sqlmock) {
+	t.Helper()
+
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unable to create mocked database: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		conn.Close()
+	})
+
+	return NewAPIKeyService(zaplog.New(zap.NewNop()), NewAPIKeyRepository(sqlx.NewDb(conn, "postgres"))), mock
+}
+
+// apiKeyRow returns a row of the api_key table for the key
+func apiKeyRow(id uuid.UUID, key string, expiresAt *time.Time, lastUsedAt *time.Time,
+	revokedAt *time.Time) *sqlmock.Rows {
+	return sqlmock.NewRows(apiKeyColumns).AddRow(id, "ci", key[:13], hashAPIKey(key), "{posts:create}", nil, nil,
+		expiresAt, lastUsedAt, revokedAt, time.Now().UTC())
+}
+
+func TestAPIKeyAuthenticate(t *testing.T) {
+	key, _, err := generateAPIKey()
+	if err != nil {
+		t.Fatalf("generateAPIKey() error = %v", err)
+	}
+	id := uuid.New()
+	now := time.Now().UTC()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+	recent := now.Add(-time.Second)
+
+	tests := []struct {
+		name       string
+		rows       *sqlmock.Rows
+		wantUpdate bool
+		wantErr    error
+	}{
+		{name: "unknown", rows: sqlmock.NewRows(apiKeyColumns), wantErr: ErrInvalidAPIKey},
+		{name: "first use", rows: apiKeyRow(id, key, &future, nil, nil), wantUpdate: true},
+		{name: "used recently", rows: apiKeyRow(id, key, nil, &recent, nil)},
+		{name: "used long ago", rows: apiKeyRow(id, key, nil, &past, nil), wantUpdate: true},
+		{name: "expired", rows: apiKeyRow(id, key, &past, nil, nil), wantErr: ErrInvalidAPIKey},
+		{name: "revoked", rows: apiKeyRow(id, key, nil, nil, &past), wantErr: ErrInvalidAPIKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newMockAPIKeyService(t)
+
+			// keys are looked up by their hash, the plain key is never stored nor queried
+			mock.ExpectPrepare(`SELECT .+ FROM api_key WHERE key_hash = \$1`)
+			mock.ExpectQuery(`SELECT .+ FROM api_key WHERE key_hash = \$1`).
+				WithArgs(hashAPIKey(key)).WillReturnRows(tt.rows)
+			if tt.wantUpdate {
+				mock.ExpectPrepare(`UPDATE api_key SET last_used_at = \$1 WHERE id = \$2`).
+					ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+
+			principal, err := service.Authenticate(context.Background(), key)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (*principal.APIKeyID != id || !principal.Can(PermissionCreatePosts)) {
+				t.Errorf("Authenticate() = %+v, want the principal of the key", principal)
+			}
+		})
+	}
+}
+
+func TestAPIKeyRevoke(t *testing.T) {
+	tests := []struct {
+		name    string
+		updated int64
+		count   int
+		wantErr error
+	}{
+		{name: "active key", updated: 1},
+		{name: "revoked key", count: 1},
+		{name: "unknown key", count: 0, wantErr: ErrAPIKeyNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newMockAPIKeyService(t)
+
+			// the revocation date of revoked keys is kept
+			mock.ExpectPrepare(`UPDATE api_key SET revoked_at = \$1 WHERE id = \$2 AND revoked_at IS NULL`).
+				ExpectExec().WillReturnResult(sqlmock.NewResult(0, tt.updated))
+			if tt.updated == 0 {
+				mock.ExpectPrepare(`SELECT count\(\*\) as count FROM api_key WHERE id = \$1`).
+					ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.count))
+			}
+
+			if err := service.Revoke(context.Background(), uuid.New()); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Revoke() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAPIKeyCreate(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	author := &Principal{UserID: uuid.New(), Role: RoleAuthor}
+
+	tests := []struct {
+		name      string
+		newKey    NewAPIKey
+		createdBy *Principal
+		wantErr   error
+	}{
+		{
+			name:   "from the command line",
+			newKey: NewAPIKey{Name: "ci", Permissions: []Permission{PermissionManageUsers}},
+		},
+		{
+			name:      "within the permissions of the creator",
+			newKey:    NewAPIKey{Name: "ci", Permissions: []Permission{PermissionCreatePosts}},
+			createdBy: author,
+		},
+		{
+			name:      "beyond the permissions of the creator",
+			newKey:    NewAPIKey{Name: "ci", Permissions: []Permission{PermissionEditAnyPost}},
+			createdBy: author,
+			wantErr:   ErrForbidden,
+		},
+		{
+			name:    "without name",
+			newKey:  NewAPIKey{Name: " ", Permissions: []Permission{PermissionCreatePosts}},
+			wantErr: ErrInvalidAPIKeyScope,
+		},
+		{
+			name:    "without permissions",
+			newKey:  NewAPIKey{Name: "ci"},
+			wantErr: ErrInvalidAPIKeyScope,
+		},
+		{
+			name:    "unknown permission",
+			newKey:  NewAPIKey{Name: "ci", Permissions: []Permission{"posts:delete"}},
+			wantErr: ErrInvalidAPIKeyScope,
+		},
+		{
+			name:    "expired",
+			newKey:  NewAPIKey{Name: "ci", Permissions: []Permission{PermissionCreatePosts}, ExpiresAt: &past},
+			wantErr: ErrInvalidAPIKeyScope,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newMockAPIKeyService(t)
+			if tt.wantErr == nil {
+				mock.ExpectPrepare(`^INSERT INTO api_key \(.+\) VALUES \(.+\)$`).
+					ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+
+			created, err := service.Create(context.Background(), tt.newKey, tt.createdBy)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			// only the hash of the returned key is stored
+			if *created.KeyHash != hashAPIKey(created.Key) || *created.Prefix != created.Key[:13] {
+				t.Errorf("Create() = %+v, want the hash and the prefix of the key", created)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	// 32 random bytes are 43 characters of unpadded base64url
+	format := regexp.MustCompile(`^blog_[A-Za-z0-9_-]{43}$`)
+
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		key, prefix, err := generateAPIKey()
+		if err != nil {
+			t.Fatalf("generateAPIKey() error = %v", err)
+		}
+		if !format.MatchString(key) {
+			t.Fatalf("generateAPIKey() = %q, want a match of %s", key, format)
+		}
+		if prefix != key[:13] {
+			t.Fatalf("prefix = %q, want the first 13 characters of %q", prefix, key)
+		}
+		if !isAPIKey(key) {
+			t.Fatalf("isAPIKey(%q) = false", key)
+		}
+		if seen[key] {
+			t.Fatalf("generateAPIKey() repeated %q", key)
+		}
+		seen[key] = true
+	}
+}
+
+func TestHashAPIKey(t *testing.T) {
+	key := "blog_key"
+	hash := hashAPIKey(key)
+
+	if len(hash) != 64 || hash == key {
+		t.Errorf("hashAPIKey() = %q, want a hex SHA-256", hash)
+	}
+	if hashAPIKey(key) != hash {
+		t.Error("hashAPIKey() isn't deterministic, keys couldn't be looked up by their hash")
+	}
+	if hashAPIKey(key+"x") == hash {
+		t.Error("hashAPIKey() is the same for different keys")
+	}
+}
+
+func TestIsAPIKey(t *testing.T) {
+	tests := []struct {
+		credential string
+		want       bool
+	}{
+		{credential: "blog_abc", want: true},
+		{credential: "eyJhbGciOiJIUzI1NiJ9.e30.sig"},
+		{credential: "Blog_abc"},
+		{credential: ""},
+	}
+
+	for _, tt := range tests {
+		if got := isAPIKey(tt.credential); got != tt.want {
+			t.Errorf("isAPIKey(%q) = %v, want %v", tt.credential, got, tt.want)
+		}
+	}
+}
+
+func TestAPIKeyActive(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+
+	tests := []struct {
+		name string
+		key  APIKey
+		want bool
+	}{
+		{name: "without expiry", key: APIKey{}, want: true},
+		{name: "expiring later", key: APIKey{ExpiresAt: &future}, want: true},
+		{name: "expiring now", key: APIKey{ExpiresAt: &now}},
+		{name: "expired", key: APIKey{ExpiresAt: &past}},
+		{name: "revoked", key: APIKey{RevokedAt: &past, ExpiresAt: &future}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.key.active(now); got != tt.want {
+				t.Errorf("active() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIKeyPrincipal(t *testing.T) {
+	id := uuid.New()
+	authorID := uuid.New()
+	permissions := pq.StringArray{string(PermissionCreatePosts), string(PermissionEditOwnPosts)}
+
+	principal := APIKey{ID: &id, AuthorID: &authorID, Permissions: &permissions}.principal()
+	if *principal.APIKeyID != id || *principal.AuthorID != authorID || principal.Role != "" {
+		t.Errorf("principal() = %+v, want the key acting as its author", principal)
+	}
+	if !principal.Can(PermissionEditOwnPosts) || principal.Can(PermissionEditAnyPost) {
+		t.Errorf("principal() permissions = %v, want the scope of the key", principal.Permissions)
+	}
+}
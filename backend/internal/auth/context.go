@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger"
+)
+
+type contextKey struct{}
+
+// WithPrincipal returns a copy of the context carrying the authenticated principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, principal)
+}
+
+// PrincipalFromContext returns the authenticated principal of the request, or nil for anonymous requests
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(contextKey{}).(*Principal)
+	return principal
+}
+
+// LogFields returns the log fields identifying the principal of the request
+func LogFields(r *http.Request) []logger.Field {
+	principal := PrincipalFromContext(r.Context())
+	switch {
+	case principal == nil:
+		return nil
+	case principal.APIKeyID != nil:
+		return []logger.Field{logger.Stringer("api_key_id", principal.APIKeyID)}
+	default:
+		return []logger.Field{logger.Stringer("user_id", principal.UserID), logger.String("role", string(principal.Role))}
+	}
+}
@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/httphandler/request"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/httphandler/response"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"go.uber.org/zap"
+)
+
+type handler struct {
+	svc Service
+}
+
+// NewHandler creates the HTTP routes to register, log in and refresh tokens
+func NewHandler(svc Service) http.Handler {
+	h := &handler{svc: svc}
+
+	r := chi.NewRouter()
+	r.Post("/register", h.register)
+	r.Post("/login", h.login)
+	r.Post("/refresh", h.refresh)
+	r.With(RequireAuthenticated).Get("/me", h.me)
+
+	r.Route("/users", func(r chi.Router) {
+		r.Use(Require(PermissionManageUsers))
+		r.Get("/", h.getUsers)
+		r.Put("/{id}/access", h.updateAccess)
+	})
+
+	return r
+}
+
+func (h *handler) register(w http.ResponseWriter, r *http.Request) {
+	var credentials Credentials
+	if err := request.ParseBody(r, &credentials); err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	user, err := h.svc.Register(r.Context(), credentials)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusCreated, &response.HTTPResponse{Data: user})
+}
+
+func (h *handler) login(w http.ResponseWriter, r *http.Request) {
+	var credentials Credentials
+	if err := request.ParseBody(r, &credentials); err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	tokens, err := h.svc.Login(r.Context(), credentials)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: tokens})
+}
+
+func (h *handler) refresh(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+	if err := request.ParseBody(r, &body); err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	tokens, err := h.svc.Refresh(r.Context(), body.RefreshToken)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: tokens})
+}
+
+func (h *handler) me(w http.ResponseWriter, r *http.Request) {
+	user, err := h.svc.GetByID(r.Context(), PrincipalFromContext(r.Context()).UserID)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: user})
+}
+
+func (h *handler) getUsers(w http.ResponseWriter, r *http.Request) {
+	page, err := request.ParsePage(r)
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	users, err := h.svc.GetAllPaginated(r.Context(), page)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: users})
+}
+
+func (h *handler) updateAccess(w http.ResponseWriter, r *http.Request) {
+	id, err := request.UUIDParam(r, "id")
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	var access Access
+	if err := request.ParseBody(r, &access); err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	user, err := h.svc.UpdateAccess(r.Context(), id, access)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: user})
+}
+
+// withServiceError translates the errors returned by the Service into HTTP responses
+func (h *handler) withServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidCredentials), errors.Is(err, ErrInvalidToken), errors.Is(err, ErrUnauthenticated):
+		response.WithJSONError(w, r, http.StatusUnauthorized, err)
+	case errors.Is(err, ErrForbidden):
+		response.WithJSONError(w, r, http.StatusForbidden, err)
+	case errors.Is(err, ErrNotFound):
+		response.WithJSONError(w, r, http.StatusNotFound, err)
+	case errors.Is(err, ErrEmailTaken):
+		response.WithJSONError(w, r, http.StatusConflict, err)
+	case errors.Is(err, ErrInvalidEmail), errors.Is(err, ErrWeakPassword), errors.Is(err, ErrInvalidRole),
+		errors.Is(err, ErrInvalidAuthor), errors.Is(err, ErrAuthorRequired):
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+	default:
+		zaplog.FromContext(r.Context()).Error("Unable to handle auth request", zap.String("path", r.URL.Path), zap.Error(err))
+		response.WithJSONError(w, r, http.StatusInternalServerError, nil)
+	}
+}
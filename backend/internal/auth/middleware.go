@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/httphandler/response"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"go.uber.org/zap"
+)
+
+// Middleware authenticates the request by a JWT access token or an API key, and puts its principal
+// into the request context. API keys are accepted in the "X-API-Key" header or as bearer tokens.
+// Requests without credentials continue anonymously, requests with invalid ones are rejected
+func Middleware(logger zaplog.Logger, svc Service, keys APIKeyService) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			credential := r.Header.Get("X-API-Key")
+			if credential == "" {
+				credential, _ = bearerToken(r)
+			}
+			if credential == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var principal *Principal
+			var err error
+			if isAPIKey(credential) {
+				principal, err = keys.Authenticate(r.Context(), credential)
+			} else {
+				principal, err = svc.Authenticate(r.Context(), credential)
+			}
+			switch {
+			case errors.Is(err, ErrInvalidToken), errors.Is(err, ErrInvalidAPIKey):
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				response.WithJSONError(w, r, http.StatusUnauthorized, err)
+				return
+			case err != nil:
+				logger.Error("Unable to authenticate request", zap.String("path", r.URL.Path), zap.Error(err))
+				response.WithJSONError(w, r, http.StatusInternalServerError, nil)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// RequireAuthenticated rejects anonymous requests with 401 Unauthorized
+func RequireAuthenticated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if PrincipalFromContext(r.Context()) == nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			response.WithJSONError(w, r, http.StatusUnauthorized, ErrUnauthenticated)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// bearerToken extracts the token of the "Authorization: Bearer <token>" header
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if len(header) < 7 || !strings.EqualFold(header[:7], "bearer ") {
+		return "", false
+	}
+
+	token := strings.TrimSpace(header[7:])
+	return token, token != ""
+}
+
+// Require rejects requests whose principal doesn't have the permission, with 401 Unauthorized for
+// anonymous requests and 403 Forbidden for the others
+func Require(permission Permission) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err := Authorize(r.Context(), permission)
+			if errors.Is(err, ErrUnauthenticated) {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				response.WithJSONError(w, r, http.StatusUnauthorized, err)
+				return
+			}
+			if err != nil {
+				response.WithJSONError(w, r, http.StatusForbidden, err)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"go.uber.org/zap"
+)
+
+// stubAPIKeys authenticates a single API key
+type stubAPIKeys struct {
+	APIKeyService
+	key       string
+	principal *Principal
+	err       error
+}
+
+func (s stubAPIKeys) Authenticate(ctx context.Context, key string) (*Principal, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if key != s.key {
+		return nil, ErrInvalidAPIKey
+	}
+	return s.principal, nil
+}
+
+func TestMiddleware(t *testing.T) {
+	service, _ := newMockService(t)
+	user := Principal{UserID: uuid.New(), Role: RoleEditor}
+	pair, err := service.tokens.issue(user)
+	if err != nil {
+		t.Fatalf("issue() error = %v", err)
+	}
+	keyID := uuid.New()
+	keys := stubAPIKeys{key: "blog_valid", principal: &Principal{APIKeyID: &keyID}}
+
+	tests := []struct {
+		name       string
+		header     string
+		value      string
+		keys       stubAPIKeys
+		wantStatus int
+		wantUser   *uuid.UUID
+		wantKey    *uuid.UUID
+	}{
+		{name: "anonymous", wantStatus: http.StatusOK},
+		{name: "access token", header: "Authorization", value: "Bearer " + pair.AccessToken, keys: keys,
+			wantStatus: http.StatusOK, wantUser: &user.UserID},
+		{name: "refresh token", header: "Authorization", value: "Bearer " + pair.RefreshToken, keys: keys,
+			wantStatus: http.StatusUnauthorized},
+		{name: "API key header", header: "X-API-Key", value: "blog_valid", keys: keys,
+			wantStatus: http.StatusOK, wantKey: &keyID},
+		{name: "API key as bearer token", header: "Authorization", value: "bearer blog_valid", keys: keys,
+			wantStatus: http.StatusOK, wantKey: &keyID},
+		{name: "invalid API key", header: "X-API-Key", value: "blog_invalid", keys: keys,
+			wantStatus: http.StatusUnauthorized},
+		{name: "unavailable database", header: "X-API-Key", value: "blog_valid",
+			keys: stubAPIKeys{err: errors.New("connection refused")}, wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var principal *Principal
+			handler := Middleware(zaplog.New(zap.NewNop()), service, tt.keys)(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					principal = PrincipalFromContext(r.Context())
+				}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				r.Header.Set(tt.header, tt.value)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			switch {
+			case tt.wantUser != nil && (principal == nil || principal.UserID != *tt.wantUser):
+				t.Errorf("principal = %+v, want the user %s", principal, tt.wantUser)
+			case tt.wantKey != nil && (principal == nil || *principal.APIKeyID != *tt.wantKey):
+				t.Errorf("principal = %+v, want the API key %s", principal, tt.wantKey)
+			case tt.wantUser == nil && tt.wantKey == nil && principal != nil:
+				t.Errorf("principal = %+v, want an anonymous request", principal)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// User is an account that can log in. Users writing posts are linked to their author profile
+type User struct {
+	ID           *uuid.UUID `db:"id" json:"id,omitempty"`
+	Email        *string    `db:"email" json:"email,omitempty"`
+	PasswordHash *string    `db:"password_hash" json:"-"`
+	AuthorID     *uuid.UUID `db:"author_id" json:"authorId,omitempty"`
+	Role         *Role      `db:"role" json:"role,omitempty"`
+	CreatedAt    *time.Time `db:"created_at" json:"createdAt,omitempty"`
+	UpdatedAt    *time.Time `db:"updated_at" json:"updatedAt,omitempty"`
+}
+
+// Principal is the authenticated identity of a request. Principals authenticated by an API key have
+// no user, and their permissions are the scope of the key instead of a role
+type Principal struct {
+	UserID      uuid.UUID    `json:"userId"`
+	Email       string       `json:"email"`
+	AuthorID    *uuid.UUID   `json:"authorId,omitempty"`
+	Role        Role         `json:"role,omitempty"`
+	APIKeyID    *uuid.UUID   `json:"apiKeyId,omitempty"`
+	Permissions []Permission `json:"permissions,omitempty"`
+}
+
+// Access contains the data sent by admins to change the role of a user and link it to an author
+type Access struct {
+	Role     Role       `json:"role"`
+	AuthorID *uuid.UUID `json:"authorId"`
+}
+
+// Credentials contains the data sent to log in or register
+type Credentials struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// TokenPair is returned after a successful login or refresh
+type TokenPair struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	TokenType    string `json:"tokenType"`
+	ExpiresIn    int64  `json:"expiresIn"`
+}
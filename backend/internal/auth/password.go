@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// minPasswordLength is the minimum quantity of bytes of a password
+const minPasswordLength = 10
+
+// maxPasswordLength is the maximum quantity of bytes bcrypt uses from a password
+const maxPasswordLength = 72
+
+// ErrWeakPassword is returned when the password doesn't have the accepted length
+var ErrWeakPassword = errors.New("password must have between 10 and 72 characters")
+
+// hashPassword returns the bcrypt hash of the password
+func hashPassword(password string) (string, error) {
+	if len(password) < minPasswordLength || len(password) > maxPasswordLength {
+		return "", ErrWeakPassword
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+// checkPassword reports whether the password matches the bcrypt hash
+func checkPassword(hash string, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHashPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		wantErr  error
+	}{
+		{name: "minimum length", password: strings.Repeat("p", minPasswordLength)},
+		{name: "maximum length", password: strings.Repeat("p", maxPasswordLength)},
+		{name: "too short", password: strings.Repeat("p", minPasswordLength-1), wantErr: ErrWeakPassword},
+		// bcrypt ignores the bytes after the 72nd, so longer passwords would match their prefix
+		{name: "too long", password: strings.Repeat("p", maxPasswordLength+1), wantErr: ErrWeakPassword},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := hashPassword(tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("hashPassword() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if hash == tt.password || !checkPassword(hash, tt.password) {
+				t.Errorf("hashPassword() = %q doesn't match the password", hash)
+			}
+		})
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	hash, err := hashPassword("correct horse battery")
+	if err != nil {
+		t.Fatalf("hashPassword() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		hash     string
+		password string
+		want     bool
+	}{
+		{name: "matching", hash: hash, password: "correct horse battery", want: true},
+		{name: "different", hash: hash, password: "correct horse battery!"},
+		{name: "different case", hash: hash, password: "Correct horse battery"},
+		{name: "empty", hash: hash, password: ""},
+		{name: "invalid hash", hash: "not a hash", password: "correct horse battery"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkPassword(tt.hash, tt.password); got != tt.want {
+				t.Errorf("checkPassword() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
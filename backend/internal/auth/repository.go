@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database/postgres"
+)
+
+type Repository interface {
+	database.CRUDRepository
+}
+
+type repo struct {
+	postgres.Pg
+}
+
+func NewRepository(session *sqlx.DB) Repository {
+	return &repo{Pg: postgres.NewRepository("user_account", session)}
+}
+
+type APIKeyRepository interface {
+	database.CRUDRepository
+}
+
+type apiKeyRepo struct {
+	postgres.Pg
+}
+
+func NewAPIKeyRepository(session *sqlx.DB) APIKeyRepository {
+	return &apiKeyRepo{Pg: postgres.NewRepository("api_key", session)}
+}
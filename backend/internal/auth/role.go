@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrUnauthenticated is returned when an action requires an authenticated principal
+	ErrUnauthenticated = errors.New("authentication required")
+
+	// ErrForbidden is returned when the principal doesn't have the permission for an action
+	ErrForbidden = errors.New("permission denied")
+
+	// ErrInvalidRole is returned when an unknown role is informed
+	ErrInvalidRole = errors.New("role must be reader, author, editor or admin")
+)
+
+// Role groups the permissions given to a user
+type Role string
+
+const (
+	RoleReader Role = "reader"
+	RoleAuthor Role = "author"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+)
+
+// Valid checks if the role is known
+func (r Role) Valid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Permission is an action that may be granted to a role
+type Permission string
+
+const (
+	PermissionCreatePosts      Permission = "posts:create"
+	PermissionEditOwnPosts     Permission = "posts:edit:own"
+	PermissionEditAnyPost      Permission = "posts:edit:any"
+	PermissionPublishOwnPosts  Permission = "posts:publish:own"
+	PermissionPublishAnyPost   Permission = "posts:publish:any"
+	PermissionModerateComments Permission = "comments:moderate"
+	PermissionManageTags       Permission = "tags:manage"
+	PermissionManageAuthors    Permission = "authors:manage"
+	PermissionManageUsers      Permission = "users:manage"
+	PermissionManageAPIKeys    Permission = "apikeys:manage"
+	PermissionManageLogging    Permission = "logging:manage"
+	PermissionViewFeatureFlags Permission = "flags:view"
+)
+
+// Valid checks if the permission is known
+func (p Permission) Valid() bool {
+	return rolePermissions[RoleAdmin][p]
+}
+
+// rolePermissions lists the permissions of every role. Each role includes the permissions of the previous one
+var rolePermissions = func() map[Role]map[Permission]bool {
+	grants := []struct {
+		role        Role
+		permissions []Permission
+	}{
+		{RoleReader, nil},
+		{RoleAuthor, []Permission{PermissionCreatePosts, PermissionEditOwnPosts, PermissionPublishOwnPosts}},
+		{RoleEditor, []Permission{PermissionEditAnyPost, PermissionPublishAnyPost, PermissionModerateComments}},
+		{RoleAdmin, []Permission{
+			PermissionManageTags, PermissionManageAuthors, PermissionManageUsers, PermissionManageAPIKeys,
+			PermissionManageLogging, PermissionViewFeatureFlags,
+		}},
+	}
+
+	roles := make(map[Role]map[Permission]bool, len(grants))
+	inherited := map[Permission]bool{}
+	for _, grant := range grants {
+		permissions := make(map[Permission]bool, len(inherited)+len(grant.permissions))
+		for permission := range inherited {
+			permissions[permission] = true
+		}
+		for _, permission := range grant.permissions {
+			permissions[permission] = true
+		}
+		roles[grant.role] = permissions
+		inherited = permissions
+	}
+	return roles
+}()
+
+// Can checks if the principal has the permission. A nil principal has no permissions
+func (p *Principal) Can(permission Permission) bool {
+	if p == nil {
+		return false
+	}
+	if p.APIKeyID != nil {
+		for _, granted := range p.Permissions {
+			if granted == permission {
+				return true
+			}
+		}
+		return false
+	}
+
+	return rolePermissions[p.Role][permission]
+}
+
+// Owns checks if the principal is the author with the given ID
+func (p *Principal) Owns(authorID *uuid.UUID) bool {
+	return p != nil && p.AuthorID != nil && authorID != nil && *p.AuthorID == *authorID
+}
+
+// Authorize checks if the principal of the context has the permission
+func Authorize(ctx context.Context, permission Permission) error {
+	principal := PrincipalFromContext(ctx)
+	if principal == nil {
+		return ErrUnauthenticated
+	}
+	if !principal.Can(permission) {
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+// AuthorizeOwner checks if the principal of the context may act on a resource of the given author:
+// either it has the "any" permission, or it owns the resource and has the "own" permission
+func AuthorizeOwner(ctx context.Context, own Permission, any Permission, ownerID *uuid.UUID) error {
+	principal := PrincipalFromContext(ctx)
+	if principal == nil {
+		return ErrUnauthenticated
+	}
+	if principal.Can(any) || (principal.Can(own) && principal.Owns(ownerID)) {
+		return nil
+	}
+
+	return ErrForbidden
+}
@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestRolePermissions(t *testing.T) {
+	tests := []struct {
+		permission Permission
+		want       map[Role]bool
+	}{
+		{PermissionCreatePosts, map[Role]bool{RoleAuthor: true, RoleEditor: true, RoleAdmin: true}},
+		{PermissionEditOwnPosts, map[Role]bool{RoleAuthor: true, RoleEditor: true, RoleAdmin: true}},
+		{PermissionPublishOwnPosts, map[Role]bool{RoleAuthor: true, RoleEditor: true, RoleAdmin: true}},
+		{PermissionEditAnyPost, map[Role]bool{RoleEditor: true, RoleAdmin: true}},
+		{PermissionPublishAnyPost, map[Role]bool{RoleEditor: true, RoleAdmin: true}},
+		{PermissionModerateComments, map[Role]bool{RoleEditor: true, RoleAdmin: true}},
+		{PermissionManageTags, map[Role]bool{RoleAdmin: true}},
+		{PermissionManageAuthors, map[Role]bool{RoleAdmin: true}},
+		{PermissionManageUsers, map[Role]bool{RoleAdmin: true}},
+		{PermissionManageAPIKeys, map[Role]bool{RoleAdmin: true}},
+		{PermissionManageLogging, map[Role]bool{RoleAdmin: true}},
+		{PermissionViewFeatureFlags, map[Role]bool{RoleAdmin: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.permission), func(t *testing.T) {
+			for _, role := range []Role{RoleReader, RoleAuthor, RoleEditor, RoleAdmin, Role("unknown")} {
+				principal := &Principal{UserID: uuid.New(), Role: role}
+				if got := principal.Can(tt.permission); got != tt.want[role] {
+					t.Errorf("%s Can() = %v, want %v", role, got, tt.want[role])
+				}
+			}
+		})
+	}
+}
+
+func TestAPIKeyPermissions(t *testing.T) {
+	keyID := uuid.New()
+	// the role is ignored, only the scope of the key counts
+	principal := &Principal{APIKeyID: &keyID, Role: RoleAdmin, Permissions: []Permission{PermissionCreatePosts}}
+
+	if !principal.Can(PermissionCreatePosts) {
+		t.Error("Can() = false for a permission in the scope of the key")
+	}
+	if principal.Can(PermissionEditAnyPost) {
+		t.Error("Can() = true for a permission out of the scope of the key")
+	}
+	if (*Principal)(nil).Can(PermissionCreatePosts) {
+		t.Error("Can() = true for a nil principal")
+	}
+}
+
+func TestAuthorizeOwner(t *testing.T) {
+	ownerID := uuid.New()
+	otherID := uuid.New()
+	keyID := uuid.New()
+
+	tests := []struct {
+		name      string
+		principal *Principal
+		ownerID   *uuid.UUID
+		wantErr   error
+	}{
+		{name: "anonymous", ownerID: &ownerID, wantErr: ErrUnauthenticated},
+		{
+			name:      "reader",
+			principal: &Principal{Role: RoleReader, AuthorID: &ownerID},
+			ownerID:   &ownerID,
+			wantErr:   ErrForbidden,
+		},
+		{name: "author owning", principal: &Principal{Role: RoleAuthor, AuthorID: &ownerID}, ownerID: &ownerID},
+		{
+			name:      "author of another",
+			principal: &Principal{Role: RoleAuthor, AuthorID: &otherID},
+			ownerID:   &ownerID,
+			wantErr:   ErrForbidden,
+		},
+		{name: "author without profile", principal: &Principal{Role: RoleAuthor}, ownerID: &ownerID, wantErr: ErrForbidden},
+		{name: "resource without owner", principal: &Principal{Role: RoleAuthor, AuthorID: &ownerID}, wantErr: ErrForbidden},
+		{name: "editor of another", principal: &Principal{Role: RoleEditor, AuthorID: &otherID}, ownerID: &ownerID},
+		{name: "admin", principal: &Principal{Role: RoleAdmin}, ownerID: &ownerID},
+		{
+			name:      "key owning with own scope",
+			principal: &Principal{APIKeyID: &keyID, AuthorID: &ownerID, Permissions: []Permission{PermissionEditOwnPosts}},
+			ownerID:   &ownerID,
+		},
+		{
+			name:      "key of another with own scope",
+			principal: &Principal{APIKeyID: &keyID, AuthorID: &otherID, Permissions: []Permission{PermissionEditOwnPosts}},
+			ownerID:   &ownerID,
+			wantErr:   ErrForbidden,
+		},
+		{
+			name:      "key owning without scope",
+			principal: &Principal{APIKeyID: &keyID, AuthorID: &ownerID},
+			ownerID:   &ownerID,
+			wantErr:   ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = WithPrincipal(ctx, tt.principal)
+			}
+
+			err := AuthorizeOwner(ctx, PermissionEditOwnPosts, PermissionEditAnyPost, tt.ownerID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AuthorizeOwner() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRequire(t *testing.T) {
+	tests := []struct {
+		name          string
+		principal     *Principal
+		wantStatus    int
+		wantChallenge bool
+	}{
+		{name: "anonymous", wantStatus: http.StatusUnauthorized, wantChallenge: true},
+		{name: "reader", principal: &Principal{Role: RoleReader}, wantStatus: http.StatusForbidden},
+		{name: "author", principal: &Principal{Role: RoleAuthor}, wantStatus: http.StatusForbidden},
+		{name: "editor", principal: &Principal{Role: RoleEditor}, wantStatus: http.StatusOK},
+		{name: "admin", principal: &Principal{Role: RoleAdmin}, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Require(PermissionModerateComments)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.principal != nil {
+				r = r.WithContext(WithPrincipal(r.Context(), tt.principal))
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if challenge := w.Header().Get("WWW-Authenticate") != ""; challenge != tt.wantChallenge {
+				t.Errorf("WWW-Authenticate = %q, want a challenge %v", w.Header().Get("WWW-Authenticate"), tt.wantChallenge)
+			}
+		})
+	}
+}
+
+func TestRequireAuthenticated(t *testing.T) {
+	tests := []struct {
+		name       string
+		principal  *Principal
+		wantStatus int
+	}{
+		{name: "anonymous", wantStatus: http.StatusUnauthorized},
+		{name: "reader", principal: &Principal{Role: RoleReader}, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := RequireAuthenticated(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.principal != nil {
+				r = r.WithContext(WithPrincipal(r.Context(), tt.principal))
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database/postgres"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/tracing"
+)
+
+var (
+	// ErrInvalidCredentials is returned when the e-mail or password are wrong
+	ErrInvalidCredentials = errors.New("invalid e-mail or password")
+
+	// ErrInvalidEmail is returned when the e-mail is not a valid address
+	ErrInvalidEmail = errors.New("e-mail must be a valid address")
+
+	// ErrEmailTaken is returned when another user already has the e-mail
+	ErrEmailTaken = errors.New("e-mail already registered")
+
+	// ErrNotFound is returned when the user doesn't exist
+	ErrNotFound = errors.New("user not found")
+
+	// ErrInvalidAuthor is returned when the linked author doesn't exist or belongs to another user
+	ErrInvalidAuthor = errors.New("author not found or already linked to another user")
+
+	// ErrAuthorRequired is returned when a user with the author role isn't linked to an author
+	ErrAuthorRequired = errors.New("users with the author role must be linked to an author")
+)
+
+// dummyHash is compared when the user doesn't exist, so a login takes the same time either way
+var dummyHash, _ = hashPassword("dummy-password-for-timing")
+
+type Service interface {
+	Register(ctx context.Context, credentials Credentials) (*User, error)
+	Login(ctx context.Context, credentials Credentials) (*TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*TokenPair, error)
+	Authenticate(ctx context.Context, accessToken string) (*Principal, error)
+	GetByID(ctx context.Context, ID uuid.UUID) (*User, error)
+	GetAllPaginated(ctx context.Context, page database.Page) (*[]User, error)
+	UpdateAccess(ctx context.Context, ID uuid.UUID, access Access) (*User, error)
+}
+
+type svc struct {
+	logger zaplog.Logger
+	repo   Repository
+	tokens *tokenIssuer
+}
+
+// NewService creates the authentication service. It fails when the token key material is not secure
+func NewService(logger zaplog.Logger, config Config, repo Repository) (Service, error) {
+	tokens, err := newTokenIssuer(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &svc{
+		logger: logger,
+		repo:   repo,
+		tokens: tokens,
+	}, nil
+}
+
+// Register creates a new user account
+func (s *svc) Register(ctx context.Context, credentials Credentials) (*User, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.Register")
+	defer span.End()
+
+	email, err := normalizeEmail(credentials.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := hashPassword(credentials.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	taken, err := s.repo.Count(ctx, sq.Eq{"email": email})
+	if err != nil {
+		return nil, err
+	}
+	if taken > 0 {
+		return nil, ErrEmailTaken
+	}
+
+	// new users can only read, other roles are given by admins
+	id := uuid.New()
+	now := time.Now().UTC()
+	role := RoleReader
+	user := User{
+		ID:           &id,
+		Email:        &email,
+		PasswordHash: &hash,
+		Role:         &role,
+		CreatedAt:    &now,
+		UpdatedAt:    &now,
+	}
+	if err := s.repo.Insert(ctx, user, nil); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// Login verifies the credentials and issues a new token pair
+func (s *svc) Login(ctx context.Context, credentials Credentials) (*TokenPair, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.Login")
+	defer span.End()
+
+	email, err := normalizeEmail(credentials.Email)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	var user User
+	err = s.repo.FindOne(ctx, sq.Eq{"email": email}, &user)
+	if errors.Is(err, sql.ErrNoRows) {
+		checkPassword(dummyHash, credentials.Password)
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !checkPassword(*user.PasswordHash, credentials.Password) {
+		return nil, ErrInvalidCredentials
+	}
+
+	return s.tokens.issue(principalOf(user))
+}
+
+// Refresh issues a new token pair from a valid refresh token. The user is loaded again, so
+// deleted accounts can't refresh their tokens
+func (s *svc) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.Refresh")
+	defer span.End()
+
+	principal, err := s.tokens.verifyRefresh(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.GetByID(ctx, principal.UserID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.tokens.issue(principalOf(*user))
+}
+
+// Authenticate returns the principal of a valid access token
+func (s *svc) Authenticate(ctx context.Context, accessToken string) (*Principal, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.Authenticate")
+	defer span.End()
+
+	return s.tokens.verifyAccess(accessToken)
+}
+
+func (s *svc) GetByID(ctx context.Context, ID uuid.UUID) (*User, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.GetByID")
+	defer span.End()
+
+	var user User
+	err := s.repo.FindOne(ctx, sq.Eq{"id": ID}, &user)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// GetAllPaginated returns the users ordered by e-mail. Only admins may list users
+func (s *svc) GetAllPaginated(ctx context.Context, page database.Page) (*[]User, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.GetAllPaginated")
+	defer span.End()
+
+	if err := Authorize(ctx, PermissionManageUsers); err != nil {
+		return nil, err
+	}
+	page.OrderBy = []string{"email"}
+
+	users := []User{}
+	err := s.repo.FindPage(ctx, nil, page, &users)
+	if err != nil {
+		return nil, err
+	}
+
+	return &users, nil
+}
+
+// UpdateAccess changes the role of the user and the author it is linked to. Only admins may change
+// the access of users, and it applies to the tokens issued from the next login or refresh
+func (s *svc) UpdateAccess(ctx context.Context, ID uuid.UUID, access Access) (*User, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.UpdateAccess")
+	defer span.End()
+
+	if err := Authorize(ctx, PermissionManageUsers); err != nil {
+		return nil, err
+	}
+	if !access.Role.Valid() {
+		return nil, ErrInvalidRole
+	}
+	if access.Role == RoleAuthor && access.AuthorID == nil {
+		return nil, ErrAuthorRequired
+	}
+
+	updated, err := s.repo.Update(ctx, map[string]interface{}{
+		"role":       access.Role,
+		"author_id":  access.AuthorID,
+		"updated_at": time.Now().UTC(),
+	}, sq.Eq{"id": ID})
+	if postgres.IsForeignKeyViolation(err) || postgres.IsUniqueViolation(err) {
+		return nil, ErrInvalidAuthor
+	}
+	if err != nil {
+		return nil, err
+	}
+	if updated == 0 {
+		return nil, ErrNotFound
+	}
+
+	return s.GetByID(ctx, ID)
+}
+
+func principalOf(user User) Principal {
+	role := RoleReader
+	if user.Role != nil {
+		role = *user.Role
+	}
+
+	return Principal{
+		UserID:   *user.ID,
+		Email:    *user.Email,
+		AuthorID: user.AuthorID,
+		Role:     role,
+	}
+}
+
+// normalizeEmail validates the e-mail and returns it in lower case
+func normalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return "", ErrInvalidEmail
+	}
+
+	return email, nil
+}
@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"go.uber.org/zap"
+)
+
+// userColumns are the columns of the user_account table, in the order of the rows returned by userRow
+var userColumns = []string{"id", "email", "password_hash", "author_id", "role", "created_at", "updated_at"}
+
+// newMockService creates the service on top of the real repository, backed by a mocked database whose
+// expectations must be met in order
+func newMockService(t *testing.T) (*svc, sqlmock.Sqlmock) {
+	t.Helper()
+
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unable to create mocked database: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		conn.Close()
+	})
+
+	service, err := NewService(zaplog.New(zap.NewNop()), testConfig(), NewRepository(sqlx.NewDb(conn, "postgres")))
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	return service.(*svc), mock
+}
+
+// userRow returns a row of the user_account table
+func userRow(id uuid.UUID, email string, hash string, role Role) *sqlmock.Rows {
+	now := time.Now().UTC()
+	return sqlmock.NewRows(userColumns).AddRow(id, email, hash, nil, role, now, now)
+}
+
+// expectUser expects a lookup of a single user returning the rows
+func expectUser(mock sqlmock.Sqlmock, rows *sqlmock.Rows) {
+	mock.ExpectPrepare(`SELECT .+ FROM user_account WHERE`)
+	mock.ExpectQuery(`SELECT .+ FROM user_account WHERE`).WillReturnRows(rows)
+}
+
+func TestLogin(t *testing.T) {
+	hash, err := hashPassword("correct horse battery")
+	if err != nil {
+		t.Fatalf("hashPassword() error = %v", err)
+	}
+	id := uuid.New()
+
+	tests := []struct {
+		name        string
+		credentials Credentials
+		rows        *sqlmock.Rows
+		wantErr     error
+	}{
+		{
+			name:        "valid credentials",
+			credentials: Credentials{Email: " Reader@Blog.com ", Password: "correct horse battery"},
+			rows:        userRow(id, "reader@blog.com", hash, RoleReader),
+		},
+		{
+			name:        "wrong password",
+			credentials: Credentials{Email: "reader@blog.com", Password: "wrong horse battery"},
+			rows:        userRow(id, "reader@blog.com", hash, RoleReader),
+			wantErr:     ErrInvalidCredentials,
+		},
+		{
+			name:        "unknown e-mail",
+			credentials: Credentials{Email: "nobody@blog.com", Password: "correct horse battery"},
+			rows:        sqlmock.NewRows(userColumns),
+			wantErr:     ErrInvalidCredentials,
+		},
+		{
+			name:        "invalid e-mail",
+			credentials: Credentials{Email: "not an e-mail", Password: "correct horse battery"},
+			wantErr:     ErrInvalidCredentials,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newMockService(t)
+			if tt.rows != nil {
+				expectUser(mock, tt.rows)
+			}
+
+			pair, err := service.Login(context.Background(), tt.credentials)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Login() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			principal, err := service.Authenticate(context.Background(), pair.AccessToken)
+			if err != nil || principal.UserID != id || principal.Role != RoleReader {
+				t.Errorf("Authenticate() = %+v, %v, want the logged in user", principal, err)
+			}
+		})
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	id := uuid.New()
+	service, mock := newMockService(t)
+
+	pair, err := service.tokens.issue(Principal{UserID: id, Email: "reader@blog.com", Role: RoleReader})
+	if err != nil {
+		t.Fatalf("issue() error = %v", err)
+	}
+
+	// the role changed since the login, and the refreshed tokens carry the new one
+	expectUser(mock, userRow(id, "reader@blog.com", "hash", RoleEditor))
+	refreshed, err := service.Refresh(context.Background(), pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if refreshed.AccessToken == pair.AccessToken || refreshed.RefreshToken == pair.RefreshToken {
+		t.Error("Refresh() returned the same tokens, want a new pair")
+	}
+
+	principal, err := service.Authenticate(context.Background(), refreshed.AccessToken)
+	if err != nil || principal.Role != RoleEditor {
+		t.Errorf("Authenticate() = %+v, %v, want an editor", principal, err)
+	}
+	if _, err := service.tokens.verifyRefresh(refreshed.RefreshToken); err != nil {
+		t.Errorf("verifyRefresh() of the new refresh token error = %v", err)
+	}
+}
+
+func TestRefreshRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		token func(pair *TokenPair) string
+		rows  *sqlmock.Rows
+	}{
+		{
+			name:  "access token",
+			token: func(pair *TokenPair) string { return pair.AccessToken },
+		},
+		{
+			name:  "deleted user",
+			token: func(pair *TokenPair) string { return pair.RefreshToken },
+			rows:  sqlmock.NewRows(userColumns),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newMockService(t)
+			pair, err := service.tokens.issue(Principal{UserID: uuid.New(), Role: RoleReader})
+			if err != nil {
+				t.Fatalf("issue() error = %v", err)
+			}
+			if tt.rows != nil {
+				expectUser(mock, tt.rows)
+			}
+
+			if _, err := service.Refresh(context.Background(), tt.token(pair)); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Refresh() error = %v, want %v", err, ErrInvalidToken)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+// minSecretLength is the minimum quantity of bytes of the HMAC keys
+const minSecretLength = 32
+
+const (
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+)
+
+// ErrInvalidToken is returned when a token is malformed, expired or signed with another key
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// Config contains the key material and lifetimes of the issued tokens
+type Config struct {
+	Issuer             string        `validate:"required"`
+	AccessTokenSecret  string        `validate:"required,min=32" secret:"true"`
+	RefreshTokenSecret string        `validate:"required,min=32" secret:"true"`
+	AccessTokenTTL     time.Duration `default:"15m" validate:"min=1s"`
+	RefreshTokenTTL    time.Duration `default:"168h" validate:"min=1s"`
+}
+
+// Validate checks that the access and refresh tokens are signed with different secrets
+func (c *Config) Validate() error {
+	if c.AccessTokenSecret != "" && c.AccessTokenSecret == c.RefreshTokenSecret {
+		return errors.New("access and refresh token secrets must be different")
+	}
+	return nil
+}
+
+// claims are the JWT claims of the access and refresh tokens
+type claims struct {
+	jwt.RegisteredClaims
+	Type     string     `json:"typ"`
+	Email    string     `json:"email,omitempty"`
+	AuthorID *uuid.UUID `json:"author_id,omitempty"`
+	Role     Role       `json:"role,omitempty"`
+}
+
+// tokenIssuer signs and verifies HS256 tokens. Access and refresh tokens use different keys,
+// so one can never be accepted as the other
+type tokenIssuer struct {
+	config Config
+}
+
+func newTokenIssuer(config Config) (*tokenIssuer, error) {
+	if len(config.AccessTokenSecret) < minSecretLength || len(config.RefreshTokenSecret) < minSecretLength {
+		return nil, fmt.Errorf("auth token secrets must have at least %d bytes", minSecretLength)
+	}
+	if config.AccessTokenSecret == config.RefreshTokenSecret {
+		return nil, errors.New("auth access and refresh token secrets must be different")
+	}
+	if config.AccessTokenTTL <= 0 {
+		config.AccessTokenTTL = 15 * time.Minute
+	}
+	if config.RefreshTokenTTL <= 0 {
+		config.RefreshTokenTTL = 7 * 24 * time.Hour
+	}
+
+	return &tokenIssuer{config: config}, nil
+}
+
+// issue creates a new access and refresh token pair for the principal
+func (t *tokenIssuer) issue(principal Principal) (*TokenPair, error) {
+	now := time.Now()
+
+	access, err := t.sign(principal, tokenTypeAccess, now, t.config.AccessTokenTTL, t.config.AccessTokenSecret)
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := t.sign(principal, tokenTypeRefresh, now, t.config.RefreshTokenTTL, t.config.RefreshTokenSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(t.config.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+func (t *tokenIssuer) sign(principal Principal, tokenType string, now time.Time, ttl time.Duration, secret string) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Issuer:    t.config.Issuer,
+			Subject:   principal.UserID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+		Type:     tokenType,
+		Email:    principal.Email,
+		AuthorID: principal.AuthorID,
+		Role:     principal.Role,
+	})
+
+	return token.SignedString([]byte(secret))
+}
+
+// verifyAccess returns the principal of a valid access token
+func (t *tokenIssuer) verifyAccess(token string) (*Principal, error) {
+	return t.verify(token, tokenTypeAccess, t.config.AccessTokenSecret)
+}
+
+// verifyRefresh returns the principal of a valid refresh token
+func (t *tokenIssuer) verifyRefresh(token string) (*Principal, error) {
+	return t.verify(token, tokenTypeRefresh, t.config.RefreshTokenSecret)
+}
+
+func (t *tokenIssuer) verify(token string, tokenType string, secret string) (*Principal, error) {
+	var c claims
+	_, err := jwt.ParseWithClaims(token, &c, func(parsed *jwt.Token) (interface{}, error) {
+		// only the expected algorithm is accepted, which prevents "alg: none" and key confusion
+		if parsed.Method != jwt.SigningMethodHS256 {
+			return nil, ErrInvalidToken
+		}
+		return []byte(secret), nil
+	})
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if c.Type != tokenType || (t.config.Issuer != "" && c.Issuer != t.config.Issuer) {
+		return nil, ErrInvalidToken
+	}
+
+	userID, err := uuid.Parse(c.Subject)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return &Principal{
+		UserID:   userID,
+		Email:    c.Email,
+		AuthorID: c.AuthorID,
+		Role:     c.Role,
+	}, nil
+}
@@ -0,0 +1,219 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+// testConfig returns a valid token configuration
+func testConfig() Config {
+	return Config{
+		Issuer:             "blog",
+		AccessTokenSecret:  strings.Repeat("a", minSecretLength),
+		RefreshTokenSecret: strings.Repeat("r", minSecretLength),
+		AccessTokenTTL:     time.Minute,
+		RefreshTokenTTL:    time.Hour,
+	}
+}
+
+// newTestIssuer returns an issuer with the test configuration
+func newTestIssuer(t *testing.T) *tokenIssuer {
+	t.Helper()
+
+	issuer, err := newTokenIssuer(testConfig())
+	if err != nil {
+		t.Fatalf("newTokenIssuer() error = %v", err)
+	}
+	return issuer
+}
+
+func TestNewTokenIssuer(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(c *Config)
+		wantErr bool
+	}{
+		{name: "valid", change: func(c *Config) {}},
+		{name: "short access secret", change: func(c *Config) { c.AccessTokenSecret = "short" }, wantErr: true},
+		{name: "short refresh secret", change: func(c *Config) { c.RefreshTokenSecret = "short" }, wantErr: true},
+		{name: "same secrets", change: func(c *Config) { c.RefreshTokenSecret = c.AccessTokenSecret }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig()
+			tt.change(&config)
+
+			_, err := newTokenIssuer(config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("newTokenIssuer() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIssueAndVerify(t *testing.T) {
+	issuer := newTestIssuer(t)
+	authorID := uuid.New()
+	principal := Principal{UserID: uuid.New(), Email: "author@blog.com", AuthorID: &authorID, Role: RoleAuthor}
+
+	pair, err := issuer.issue(principal)
+	if err != nil {
+		t.Fatalf("issue() error = %v", err)
+	}
+	if pair.TokenType != "Bearer" || pair.ExpiresIn != 60 {
+		t.Errorf("issue() = %+v, want a bearer token expiring in 60 seconds", pair)
+	}
+
+	access, err := issuer.verifyAccess(pair.AccessToken)
+	if err != nil {
+		t.Fatalf("verifyAccess() error = %v", err)
+	}
+	if access.UserID != principal.UserID || access.Email != principal.Email || *access.AuthorID != authorID ||
+		access.Role != principal.Role {
+		t.Errorf("verifyAccess() = %+v, want %+v", access, principal)
+	}
+
+	refresh, err := issuer.verifyRefresh(pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("verifyRefresh() error = %v", err)
+	}
+	if refresh.UserID != principal.UserID {
+		t.Errorf("verifyRefresh() user = %s, want %s", refresh.UserID, principal.UserID)
+	}
+}
+
+func TestVerifyRejectsInvalidTokens(t *testing.T) {
+	issuer := newTestIssuer(t)
+	principal := Principal{UserID: uuid.New(), Role: RoleReader}
+	config := testConfig()
+	now := time.Now()
+
+	sign := func(t *testing.T, tokenType string, now time.Time, ttl time.Duration, secret string) string {
+		token, err := issuer.sign(principal, tokenType, now, ttl, secret)
+		if err != nil {
+			t.Fatalf("sign() error = %v", err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name   string
+		token  func(t *testing.T) string
+		verify func(token string) (*Principal, error)
+	}{
+		{
+			name:   "malformed",
+			token:  func(t *testing.T) string { return "not.a.token" },
+			verify: issuer.verifyAccess,
+		},
+		{
+			name: "expired",
+			token: func(t *testing.T) string {
+				return sign(t, tokenTypeAccess, now.Add(-2*time.Hour), time.Hour, config.AccessTokenSecret)
+			},
+			verify: issuer.verifyAccess,
+		},
+		{
+			name: "not valid yet",
+			token: func(t *testing.T) string {
+				return sign(t, tokenTypeAccess, now.Add(time.Hour), time.Hour, config.AccessTokenSecret)
+			},
+			verify: issuer.verifyAccess,
+		},
+		{
+			name: "tampered claims",
+			token: func(t *testing.T) string {
+				token := sign(t, tokenTypeAccess, now, time.Hour, config.AccessTokenSecret)
+				parts := strings.Split(token, ".")
+				parts[1] = jwt.EncodeSegment([]byte(`{"typ":"access","role":"admin","sub":"` + principal.UserID.String() +
+					`","iss":"blog","exp":` + jwt.NewNumericDate(now.Add(time.Hour)).String() + `}`))
+				return strings.Join(parts, ".")
+			},
+			verify: issuer.verifyAccess,
+		},
+		{
+			name: "tampered signature",
+			token: func(t *testing.T) string {
+				token := sign(t, tokenTypeAccess, now, time.Hour, config.AccessTokenSecret)
+				return token[:len(token)-4] + "AAAA"
+			},
+			verify: issuer.verifyAccess,
+		},
+		{
+			name: "signed with another secret",
+			token: func(t *testing.T) string {
+				return sign(t, tokenTypeAccess, now, time.Hour, strings.Repeat("x", minSecretLength))
+			},
+			verify: issuer.verifyAccess,
+		},
+		{
+			name: "unsigned",
+			token: func(t *testing.T) string {
+				token, err := jwt.NewWithClaims(jwt.SigningMethodNone, claims{
+					RegisteredClaims: jwt.RegisteredClaims{Subject: principal.UserID.String(), Issuer: "blog"},
+					Type:             tokenTypeAccess,
+				}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+				if err != nil {
+					t.Fatalf("SignedString() error = %v", err)
+				}
+				return token
+			},
+			verify: issuer.verifyAccess,
+		},
+		{
+			name: "refresh token used as access token",
+			token: func(t *testing.T) string {
+				return sign(t, tokenTypeRefresh, now, time.Hour, config.RefreshTokenSecret)
+			},
+			verify: issuer.verifyAccess,
+		},
+		{
+			name: "access token used as refresh token",
+			token: func(t *testing.T) string {
+				return sign(t, tokenTypeAccess, now, time.Hour, config.AccessTokenSecret)
+			},
+			verify: issuer.verifyRefresh,
+		},
+		{
+			name: "refresh type signed with the access secret",
+			token: func(t *testing.T) string {
+				return sign(t, tokenTypeRefresh, now, time.Hour, config.AccessTokenSecret)
+			},
+			verify: issuer.verifyRefresh,
+		},
+		{
+			name: "another issuer",
+			token: func(t *testing.T) string {
+				other, err := newTokenIssuer(Config{
+					Issuer:             "other",
+					AccessTokenSecret:  config.AccessTokenSecret,
+					RefreshTokenSecret: config.RefreshTokenSecret,
+				})
+				if err != nil {
+					t.Fatalf("newTokenIssuer() error = %v", err)
+				}
+				pair, err := other.issue(principal)
+				if err != nil {
+					t.Fatalf("issue() error = %v", err)
+				}
+				return pair.AccessToken
+			},
+			verify: issuer.verifyAccess,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := tt.verify(tt.token(t))
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("verify() = %+v, %v, want %v", principal, err, ErrInvalidToken)
+			}
+		})
+	}
+}
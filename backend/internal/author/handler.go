@@ -0,0 +1,134 @@
+package author
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/httphandler/request"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/httphandler/response"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/auth"
+	"go.uber.org/zap"
+)
+
+type handler struct {
+	svc Service
+}
+
+// NewHandler creates the HTTP routes for authors. Only admins may manage authors
+func NewHandler(svc Service) http.Handler {
+	h := &handler{svc: svc}
+
+	r := chi.NewRouter()
+	r.Get("/", h.getAll)
+	r.Get("/{id}", h.getByID)
+	r.Group(func(r chi.Router) {
+		r.Use(auth.Require(auth.PermissionManageAuthors))
+		r.Post("/", h.create)
+		r.Put("/{id}", h.update)
+		r.Delete("/{id}", h.delete)
+	})
+
+	return r
+}
+
+func (h *handler) create(w http.ResponseWriter, r *http.Request) {
+	var author Author
+	if err := request.ParseBody(r, &author); err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	created, err := h.svc.Create(r.Context(), author)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusCreated, &response.HTTPResponse{Data: created})
+}
+
+func (h *handler) getAll(w http.ResponseWriter, r *http.Request) {
+	page, err := request.ParsePage(r)
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	authors, err := h.svc.GetAllPaginated(r.Context(), page)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: authors})
+}
+
+func (h *handler) getByID(w http.ResponseWriter, r *http.Request) {
+	id, err := request.UUIDParam(r, "id")
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	author, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: author})
+}
+
+func (h *handler) update(w http.ResponseWriter, r *http.Request) {
+	id, err := request.UUIDParam(r, "id")
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	var author Author
+	if err := request.ParseBody(r, &author); err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	updated, err := h.svc.UpdateByID(r.Context(), id, author)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: updated})
+}
+
+func (h *handler) delete(w http.ResponseWriter, r *http.Request) {
+	id, err := request.UUIDParam(r, "id")
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.svc.DeleteByID(r.Context(), id); err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusNoContent, nil)
+}
+
+// withServiceError translates the errors returned by the Service into HTTP responses
+func (h *handler) withServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		response.WithJSONError(w, r, http.StatusNotFound, err)
+	case errors.Is(err, ErrInvalidAuthor):
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+	case errors.Is(err, ErrHasPosts):
+		response.WithJSONError(w, r, http.StatusConflict, err)
+	default:
+		zaplog.FromContext(r.Context()).Error("Unable to handle author request", zap.String("path", r.URL.Path), zap.Error(err))
+		response.WithJSONError(w, r, http.StatusInternalServerError, nil)
+	}
+}
@@ -1,10 +1,32 @@
 package author
 
-import "github.com/google/uuid"
+import (
+	"strings"
+
+	"github.com/google/uuid"
+)
 
 type Author struct {
-	ID        *uuid.UUID
-	FirstName *string
-	LastName  *string
-	Score     *float64
+	ID        *uuid.UUID `db:"id" json:"id,omitempty"`
+	FirstName *string    `db:"first_name" json:"firstName,omitempty"`
+	LastName  *string    `db:"last_name" json:"lastName,omitempty"`
+	Score     *float64   `db:"score" json:"score,omitempty"`
+}
+
+// FullName returns the first and last names of the author
+func (a Author) FullName() string {
+	var names []string
+	if a.FirstName != nil && *a.FirstName != "" {
+		names = append(names, *a.FirstName)
+	}
+	if a.LastName != nil && *a.LastName != "" {
+		names = append(names, *a.LastName)
+	}
+	return strings.Join(names, " ")
+}
+
+// valid checks if the required names are informed
+func (a Author) valid() bool {
+	return a.FirstName != nil && strings.TrimSpace(*a.FirstName) != "" &&
+		a.LastName != nil && strings.TrimSpace(*a.LastName) != ""
 }
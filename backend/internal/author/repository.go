@@ -1,6 +1,7 @@
 package author
 
 import (
+	"github.com/jmoiron/sqlx"
 	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database"
 	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database/postgres"
 )
@@ -10,9 +11,9 @@ type Repository interface {
 }
 
 type repo struct {
-	*postgres.PgTxRepository
+	postgres.Pg
 }
 
-func NewRepository() Repository {
-	return &repo{}
+func NewRepository(session *sqlx.DB) Repository {
+	return &repo{Pg: postgres.NewRepository("author", session)}
 }
@@ -2,43 +2,138 @@ package author
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 
+	sq "github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database/postgres"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/tracing"
+)
+
+var (
+	// ErrNotFound is returned when the author doesn't exist
+	ErrNotFound = errors.New("author not found")
+
+	// ErrInvalidAuthor is returned when required author data is missing
+	ErrInvalidAuthor = errors.New("author first and last names are required")
+
+	// ErrHasPosts is returned when deleting an author that still has posts
+	ErrHasPosts = errors.New("author still has posts")
 )
 
 type Service interface {
-	Create(ctx context.Context, author Author) error
-	GetAllPaginated(ctx context.Context) (*[]Author, error)
+	Create(ctx context.Context, author Author) (*Author, error)
+	GetAllPaginated(ctx context.Context, page database.Page) (*[]Author, error)
 	GetByID(ctx context.Context, ID uuid.UUID) (*Author, error)
-	UpdateByID(ctx context.Context, ID uuid.UUID) (*Author, error)
+	UpdateByID(ctx context.Context, ID uuid.UUID, author Author) (*Author, error)
 	DeleteByID(ctx context.Context, ID uuid.UUID) error
 }
 
 type svc struct {
-	repo Repository
+	logger zaplog.Logger
+	repo   Repository
 }
 
-func NewService() Service {
-	return &svc{}
+func NewService(logger zaplog.Logger, repo Repository) Service {
+	return &svc{
+		logger: logger,
+		repo:   repo,
+	}
 }
 
-func (s *svc) Create(ctx context.Context, author Author) error {
+func (s *svc) Create(ctx context.Context, author Author) (*Author, error) {
+	ctx, span := tracing.Start(ctx, "author.Service.Create")
+	defer span.End()
+
+	if !author.valid() {
+		return nil, ErrInvalidAuthor
+	}
+
+	id := uuid.New()
+	author.ID = &id
+
 	err := s.repo.Insert(ctx, author, nil)
-	return err
+	if err != nil {
+		return nil, err
+	}
+
+	return &author, nil
 }
 
-func (s *svc) GetAllPaginated(ctx context.Context) (*[]Author, error) {
-	panic("implement me")
+func (s *svc) GetAllPaginated(ctx context.Context, page database.Page) (*[]Author, error) {
+	ctx, span := tracing.Start(ctx, "author.Service.GetAllPaginated")
+	defer span.End()
+
+	page.OrderBy = []string{"last_name", "first_name", "id"}
+
+	authors := []Author{}
+	err := s.repo.FindPage(ctx, nil, page, &authors)
+	if err != nil {
+		return nil, err
+	}
+
+	return &authors, nil
 }
 
 func (s *svc) GetByID(ctx context.Context, ID uuid.UUID) (*Author, error) {
-	panic("implement me")
+	ctx, span := tracing.Start(ctx, "author.Service.GetByID")
+	defer span.End()
+
+	var author Author
+	err := s.repo.FindOne(ctx, sq.Eq{"id": ID}, &author)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &author, nil
 }
 
-func (s *svc) UpdateByID(ctx context.Context, ID uuid.UUID) (*Author, error) {
-	panic("implement me")
+func (s *svc) UpdateByID(ctx context.Context, ID uuid.UUID, author Author) (*Author, error) {
+	ctx, span := tracing.Start(ctx, "author.Service.UpdateByID")
+	defer span.End()
+
+	if !author.valid() {
+		return nil, ErrInvalidAuthor
+	}
+
+	updated, err := s.repo.Update(ctx, map[string]interface{}{
+		"first_name": author.FirstName,
+		"last_name":  author.LastName,
+		"score":      author.Score,
+	}, sq.Eq{"id": ID})
+	if err != nil {
+		return nil, err
+	}
+	if updated == 0 {
+		return nil, ErrNotFound
+	}
+
+	author.ID = &ID
+	return &author, nil
 }
 
+// DeleteByID removes the author. Authors with posts can't be removed, their posts must be deleted
+// or moved to another author first
 func (s *svc) DeleteByID(ctx context.Context, ID uuid.UUID) error {
-	panic("implement me")
+	ctx, span := tracing.Start(ctx, "author.Service.DeleteByID")
+	defer span.End()
+
+	deleted, err := s.repo.Remove(ctx, sq.Eq{"id": ID}, true)
+	if postgres.IsForeignKeyViolation(err) {
+		return ErrHasPosts
+	}
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrNotFound
+	}
+
+	return nil
 }
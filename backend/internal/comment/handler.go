@@ -0,0 +1,230 @@
+package comment
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/httphandler/request"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/httphandler/response"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/auth"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/post"
+	"go.uber.org/zap"
+)
+
+// maxBodySize is the maximum quantity of bytes read from the body of a request, which leaves room for the JSON
+// escaping of the content
+const maxBodySize = 4 * maxContentLength
+
+type handler struct {
+	svc Service
+}
+
+// NewHandler creates the public HTTP routes for the comments of a post. It must be mounted on a
+// pattern containing the {postID} URL param
+func NewHandler(svc Service) http.Handler {
+	h := &handler{svc: svc}
+
+	r := chi.NewRouter()
+	r.Get("/", h.getThreads)
+	r.Post("/", h.create)
+	r.Get("/count", h.count)
+
+	return r
+}
+
+// NewModerationHandler creates the HTTP routes used by moderators to review comments
+func NewModerationHandler(svc Service) http.Handler {
+	h := &handler{svc: svc}
+
+	r := chi.NewRouter()
+	r.Use(auth.Require(auth.PermissionModerateComments))
+	r.Get("/", h.getByStatus)
+	r.Get("/counts", h.counts)
+	r.Put("/{id}/status", h.setStatus)
+	r.Delete("/{id}", h.delete)
+
+	return r
+}
+
+func (h *handler) getThreads(w http.ResponseWriter, r *http.Request) {
+	postID, err := request.UUIDParam(r, "postID")
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	page, err := request.ParsePage(r)
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	threads, err := h.svc.GetThreadsPaginated(r.Context(), postID, page)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: threads})
+}
+
+func (h *handler) create(w http.ResponseWriter, r *http.Request) {
+	postID, err := request.UUIDParam(r, "postID")
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	var comment Comment
+	if !h.parseBody(w, r, &comment) {
+		return
+	}
+
+	created, err := h.svc.Create(r.Context(), postID, comment)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	// the comment is only published after moderation
+	response.WithJSON(w, r, http.StatusAccepted, &response.HTTPResponse{Data: created})
+}
+
+func (h *handler) count(w http.ResponseWriter, r *http.Request) {
+	postID, err := request.UUIDParam(r, "postID")
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	counts, err := h.svc.CountApproved(r.Context(), []uuid.UUID{postID})
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: (*counts)[0]})
+}
+
+// counts returns the approved comment counts of the posts informed by the "postId" query params
+func (h *handler) counts(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()["postId"]
+	if len(values) == 0 || len(values) > request.MaxPageSize {
+		response.WithJSONError(w, r, http.StatusBadRequest, errors.New("between 1 and 100 postId query params are required"))
+		return
+	}
+
+	postIDs := make([]uuid.UUID, len(values))
+	for i, value := range values {
+		postID, err := uuid.Parse(value)
+		if err != nil {
+			response.WithJSONError(w, r, http.StatusBadRequest, errors.New("query param postId must be a valid uuid"))
+			return
+		}
+		postIDs[i] = postID
+	}
+
+	counts, err := h.svc.CountApproved(r.Context(), postIDs)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: counts})
+}
+
+func (h *handler) getByStatus(w http.ResponseWriter, r *http.Request) {
+	page, err := request.ParsePage(r)
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	// the moderation queue is listed by default
+	status := Status(r.URL.Query().Get("status"))
+	if status == "" {
+		status = StatusPending
+	}
+
+	comments, err := h.svc.GetByStatusPaginated(r.Context(), status, page)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: comments})
+}
+
+func (h *handler) setStatus(w http.ResponseWriter, r *http.Request) {
+	id, err := request.UUIDParam(r, "id")
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	var body struct {
+		Status Status `json:"status"`
+	}
+	if !h.parseBody(w, r, &body) {
+		return
+	}
+
+	comment, err := h.svc.SetStatus(r.Context(), id, body.Status)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: comment})
+}
+
+func (h *handler) delete(w http.ResponseWriter, r *http.Request) {
+	id, err := request.UUIDParam(r, "id")
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.svc.DeleteByID(r.Context(), id); err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusNoContent, nil)
+}
+
+// parseBody parses the request body, which can't be larger than maxBodySize. It writes the error response and
+// returns false when the body is invalid
+func (h *handler) parseBody(w http.ResponseWriter, r *http.Request, output interface{}) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+	err := request.ParseBody(r, output)
+
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		response.WithJSONError(w, r, http.StatusRequestEntityTooLarge, err)
+		return false
+	case err != nil:
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return false
+	}
+
+	return true
+}
+
+// withServiceError translates the errors returned by the Service into HTTP responses
+func (h *handler) withServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound), errors.Is(err, post.ErrNotFound):
+		response.WithJSONError(w, r, http.StatusNotFound, err)
+	case errors.Is(err, ErrInvalidComment), errors.Is(err, ErrInvalidParent), errors.Is(err, ErrInvalidStatus):
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+	case errors.Is(err, ErrUnpublishedPost):
+		response.WithJSONError(w, r, http.StatusConflict, err)
+	default:
+		zaplog.FromContext(r.Context()).Error("Unable to handle comment request", zap.String("path", r.URL.Path), zap.Error(err))
+		response.WithJSONError(w, r, http.StatusInternalServerError, nil)
+	}
+}
@@ -0,0 +1,33 @@
+package comment
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"go.uber.org/zap"
+)
+
+func TestCreateRejectsLargeBodies(t *testing.T) {
+	// the body is rejected before reaching the repository
+	service := NewService(zaplog.New(zap.NewNop()), nil, stubPosts{})
+	handler := NewHandler(service)
+
+	body := `{"authorName":"Reader","content":"` + strings.Repeat("a", maxBodySize) + `"}`
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	routeContext := chi.NewRouteContext()
+	routeContext.URLParams.Add("postID", uuid.New().String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeContext))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+}
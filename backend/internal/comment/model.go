@@ -0,0 +1,47 @@
+package comment
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status is the moderation state of a comment
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusApproved Status = "approved"
+	StatusRejected Status = "rejected"
+	StatusSpam     Status = "spam"
+)
+
+// Valid reports whether the status is one of the known moderation states
+func (s Status) Valid() bool {
+	switch s {
+	case StatusPending, StatusApproved, StatusRejected, StatusSpam:
+		return true
+	}
+	return false
+}
+
+type Comment struct {
+	ID          *uuid.UUID `db:"id" json:"id,omitempty"`
+	PostID      *uuid.UUID `db:"post_id" json:"postId,omitempty"`
+	ParentID    *uuid.UUID `db:"parent_id" json:"parentId,omitempty"`
+	RootID      *uuid.UUID `db:"root_id" json:"-"`
+	AuthorName  *string    `db:"author_name" json:"authorName,omitempty"`
+	AuthorEmail *string    `db:"author_email" json:"authorEmail,omitempty"`
+	Content     *string    `db:"content" json:"content,omitempty"`
+	Status      *Status    `db:"status" json:"status,omitempty"`
+	CreatedAt   *time.Time `db:"created_at" json:"createdAt,omitempty"`
+	UpdatedAt   *time.Time `db:"updated_at" json:"updatedAt,omitempty"`
+
+	Replies []*Comment `db:"-" json:"replies,omitempty"`
+}
+
+// Count is the quantity of approved comments of a post
+type Count struct {
+	PostID uuid.UUID `db:"post_id" json:"postId"`
+	Count  int64     `db:"count" json:"count"`
+}
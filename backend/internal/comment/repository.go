@@ -0,0 +1,39 @@
+package comment
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database/postgres"
+)
+
+type Repository interface {
+	postgres.Pg
+	CountByPosts(ctx context.Context, postIDs []uuid.UUID, status Status) ([]Count, error)
+}
+
+type repo struct {
+	postgres.Pg
+}
+
+func NewRepository(session *sqlx.DB) Repository {
+	return &repo{Pg: postgres.NewRepository("comment", session)}
+}
+
+// CountByPosts returns how many comments with the given status every post has. Posts without
+// comments are not returned
+func (r *repo) CountByPosts(ctx context.Context, postIDs []uuid.UUID, status Status) ([]Count, error) {
+	qb := sq.Select("post_id", "count(*) as count").
+		From("comment").
+		Where(sq.Eq{"post_id": postIDs, "status": status}).
+		GroupBy("post_id")
+
+	counts := []Count{}
+	if err := r.Select(ctx, "count_by_posts", qb, &counts); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
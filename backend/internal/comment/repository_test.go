@@ -0,0 +1,71 @@
+package comment
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database/postgres"
+)
+
+func TestCountByPostsIsMeasured(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unable to create mocked database: %v", err)
+	}
+	defer conn.Close()
+	db := sqlx.NewDb(conn, "postgres")
+
+	registry := prometheus.NewRegistry()
+	if err := postgres.RegisterMetrics(registry, db, "blog"); err != nil {
+		t.Fatal(err)
+	}
+	before := countQueries(t, registry)
+
+	postID := uuid.New()
+	mock.ExpectQuery(`^SELECT post_id, count\(\*\) as count FROM comment WHERE .+ GROUP BY post_id$`).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "count"}).AddRow(postID, 3))
+
+	counts, err := NewRepository(db).CountByPosts(context.Background(), []uuid.UUID{postID}, StatusApproved)
+	if err != nil {
+		t.Fatalf("CountByPosts() error = %v", err)
+	}
+	if len(counts) != 1 || counts[0].PostID != postID || counts[0].Count != 3 {
+		t.Errorf("CountByPosts() = %+v, want 3 comments of %s", counts, postID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	if got := countQueries(t, registry); got != before+1 {
+		t.Errorf("measured count_by_posts queries = %d, want %d", got, before+1)
+	}
+}
+
+// countQueries returns how many count_by_posts queries of the comment table were measured
+func countQueries(t *testing.T, registry *prometheus.Registry) uint64 {
+	t.Helper()
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != "db_query_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["table"] == "comment" && labels["operation"] == "count_by_posts" {
+				return metric.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	return 0
+}
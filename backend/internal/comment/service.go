@@ -0,0 +1,260 @@
+package comment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/tracing"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/post"
+)
+
+// maxContentLength is the maximum quantity of bytes of a comment
+const maxContentLength = 10000
+
+var (
+	// ErrNotFound is returned when the comment doesn't exist
+	ErrNotFound = errors.New("comment not found")
+
+	// ErrInvalidComment is returned when the comment data is missing or invalid
+	ErrInvalidComment = errors.New("comment author name and content are required")
+
+	// ErrInvalidParent is returned when the replied comment doesn't belong to the same post
+	ErrInvalidParent = errors.New("parent comment not found in this post")
+
+	// ErrInvalidStatus is returned when an unknown moderation status is informed
+	ErrInvalidStatus = errors.New("status must be pending, approved, rejected or spam")
+
+	// ErrUnpublishedPost is returned when commenting a post that is still a draft
+	ErrUnpublishedPost = errors.New("only published posts accept comments")
+)
+
+type Service interface {
+	Create(ctx context.Context, postID uuid.UUID, comment Comment) (*Comment, error)
+	GetThreadsPaginated(ctx context.Context, postID uuid.UUID, page database.Page) (*[]*Comment, error)
+	CountApproved(ctx context.Context, postIDs []uuid.UUID) (*[]Count, error)
+	GetByStatusPaginated(ctx context.Context, status Status, page database.Page) (*[]Comment, error)
+	SetStatus(ctx context.Context, ID uuid.UUID, status Status) (*Comment, error)
+	DeleteByID(ctx context.Context, ID uuid.UUID) error
+}
+
+type svc struct {
+	logger zaplog.Logger
+	repo   Repository
+	posts  post.Service
+}
+
+func NewService(logger zaplog.Logger, repo Repository, posts post.Service) Service {
+	return &svc{
+		logger: logger,
+		repo:   repo,
+		posts:  posts,
+	}
+}
+
+// Create stores a new comment or reply to a published post, waiting for moderation
+func (s *svc) Create(ctx context.Context, postID uuid.UUID, comment Comment) (*Comment, error) {
+	ctx, span := tracing.Start(ctx, "comment.Service.Create")
+	defer span.End()
+
+	if comment.AuthorName == nil || strings.TrimSpace(*comment.AuthorName) == "" ||
+		comment.Content == nil || strings.TrimSpace(*comment.Content) == "" || len(*comment.Content) > maxContentLength {
+		return nil, ErrInvalidComment
+	}
+
+	// drafts are visible to their editors, but only published posts are open to comments
+	commented, err := s.posts.GetByID(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if !commented.IsPublished(time.Now()) {
+		return nil, ErrUnpublishedPost
+	}
+
+	// replies belong to the thread of the replied comment
+	if comment.ParentID != nil {
+		var parent Comment
+		err := s.repo.FindOne(ctx, sq.Eq{"id": comment.ParentID, "post_id": postID}, &parent)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidParent
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		comment.RootID = parent.RootID
+		if comment.RootID == nil {
+			comment.RootID = parent.ID
+		}
+	}
+
+	id := uuid.New()
+	now := time.Now().UTC()
+	status := StatusPending
+	comment.ID = &id
+	comment.PostID = &postID
+	comment.Status = &status
+	comment.CreatedAt = &now
+	comment.UpdatedAt = &now
+
+	if err := s.repo.Insert(ctx, comment, nil); err != nil {
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+// GetThreadsPaginated returns a page of approved top level comments of the post, each one with
+// its approved replies nested. Replies of comments that are not approved are hidden, and so are the
+// comments of posts that aren't published, which are reported as not found
+func (s *svc) GetThreadsPaginated(ctx context.Context, postID uuid.UUID, page database.Page) (*[]*Comment, error) {
+	ctx, span := tracing.Start(ctx, "comment.Service.GetThreadsPaginated")
+	defer span.End()
+
+	commented, err := s.posts.GetByID(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if !commented.IsPublished(time.Now()) {
+		return nil, post.ErrNotFound
+	}
+
+	page.OrderBy = []string{"created_at", "id"}
+
+	roots := []*Comment{}
+	err = s.repo.FindPage(ctx, sq.Eq{
+		"post_id":   postID,
+		"parent_id": nil,
+		"status":    StatusApproved,
+	}, page, &roots)
+	if err != nil {
+		return nil, err
+	}
+	if len(roots) == 0 {
+		return &roots, nil
+	}
+
+	rootIDs := make([]uuid.UUID, len(roots))
+	byID := make(map[uuid.UUID]*Comment, len(roots))
+	for i, root := range roots {
+		rootIDs[i] = *root.ID
+		byID[*root.ID] = root
+	}
+
+	// all replies of the page threads are loaded at once, oldest first
+	var replies []*Comment
+	err = s.repo.FindPage(ctx, sq.Eq{
+		"root_id": rootIDs,
+		"status":  StatusApproved,
+	}, database.Page{OrderBy: []string{"created_at", "id"}}, &replies)
+	if err != nil {
+		return nil, err
+	}
+	for _, reply := range replies {
+		byID[*reply.ID] = reply
+	}
+	for _, reply := range replies {
+		if parent, ok := byID[*reply.ParentID]; ok {
+			parent.Replies = append(parent.Replies, reply)
+		}
+	}
+
+	// e-mails are only visible to moderators
+	for _, comment := range byID {
+		comment.AuthorEmail = nil
+	}
+
+	return &roots, nil
+}
+
+// CountApproved returns the quantity of approved comments of every post, including posts without comments
+func (s *svc) CountApproved(ctx context.Context, postIDs []uuid.UUID) (*[]Count, error) {
+	ctx, span := tracing.Start(ctx, "comment.Service.CountApproved")
+	defer span.End()
+
+	found, err := s.repo.CountByPosts(ctx, postIDs, StatusApproved)
+	if err != nil {
+		return nil, err
+	}
+
+	byPost := make(map[uuid.UUID]int64, len(found))
+	for _, count := range found {
+		byPost[count.PostID] = count.Count
+	}
+
+	counts := make([]Count, len(postIDs))
+	for i, postID := range postIDs {
+		counts[i] = Count{PostID: postID, Count: byPost[postID]}
+	}
+
+	return &counts, nil
+}
+
+// GetByStatusPaginated returns the comments of all posts in a moderation state, oldest first
+func (s *svc) GetByStatusPaginated(ctx context.Context, status Status, page database.Page) (*[]Comment, error) {
+	ctx, span := tracing.Start(ctx, "comment.Service.GetByStatusPaginated")
+	defer span.End()
+
+	if !status.Valid() {
+		return nil, ErrInvalidStatus
+	}
+	page.OrderBy = []string{"created_at", "id"}
+
+	comments := []Comment{}
+	err := s.repo.FindPage(ctx, sq.Eq{"status": status}, page, &comments)
+	if err != nil {
+		return nil, err
+	}
+
+	return &comments, nil
+}
+
+// SetStatus moves the comment to another moderation state
+func (s *svc) SetStatus(ctx context.Context, ID uuid.UUID, status Status) (*Comment, error) {
+	ctx, span := tracing.Start(ctx, "comment.Service.SetStatus")
+	defer span.End()
+
+	if !status.Valid() {
+		return nil, ErrInvalidStatus
+	}
+
+	updated, err := s.repo.Update(ctx, map[string]interface{}{
+		"status":     status,
+		"updated_at": time.Now().UTC(),
+	}, sq.Eq{"id": ID})
+	if err != nil {
+		return nil, err
+	}
+	if updated == 0 {
+		return nil, ErrNotFound
+	}
+
+	var comment Comment
+	if err := s.repo.FindOne(ctx, sq.Eq{"id": ID}, &comment); err != nil {
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+func (s *svc) DeleteByID(ctx context.Context, ID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "comment.Service.DeleteByID")
+	defer span.End()
+
+	// replies are removed by the foreign key cascade
+	deleted, err := s.repo.Remove(ctx, sq.Eq{"id": ID}, true)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
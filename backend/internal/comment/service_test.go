@@ -0,0 +1,76 @@
+package comment
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/post"
+	"go.uber.org/zap"
+)
+
+// stubPosts returns the same post for any id, the other methods are not used by the comments
+type stubPosts struct {
+	post.Service
+	post post.Post
+}
+
+func (s stubPosts) GetByID(_ context.Context, ID uuid.UUID) (*post.Post, error) {
+	p := s.post
+	p.ID = &ID
+	return &p, nil
+}
+
+func TestCreateRejectsUnpublishedPosts(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name        string
+		publishedAt *time.Time
+	}{
+		{name: "draft"},
+		{name: "scheduled", publishedAt: &future},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the repository is never reached
+			service := NewService(zaplog.New(zap.NewNop()), nil, stubPosts{post: post.Post{PublishedAt: tt.publishedAt}})
+			name := "Reader"
+			content := "First!"
+
+			_, err := service.Create(context.Background(), uuid.New(), Comment{AuthorName: &name, Content: &content})
+			if !errors.Is(err, ErrUnpublishedPost) {
+				t.Fatalf("Create() error = %v, want %v", err, ErrUnpublishedPost)
+			}
+		})
+	}
+}
+
+func TestGetThreadsHidesUnpublishedPosts(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name        string
+		publishedAt *time.Time
+	}{
+		{name: "draft"},
+		{name: "scheduled", publishedAt: &future},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the repository is never reached
+			service := NewService(zaplog.New(zap.NewNop()), nil, stubPosts{post: post.Post{PublishedAt: tt.publishedAt}})
+
+			_, err := service.GetThreadsPaginated(context.Background(), uuid.New(), database.Page{})
+			if !errors.Is(err, post.ErrNotFound) {
+				t.Fatalf("GetThreadsPaginated() error = %v, want %v", err, post.ErrNotFound)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+// Package config contains the environment configuration shared by all commands
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database/postgres"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/environment"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/featureflag"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/health"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/httphandler/middleware"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/lifecycle"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/ratelimit"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/tracing"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/auth"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/feed"
+)
+
+// environmentPath is the default directory with the env.<name>.yaml files
+const environmentPath = "configs/environment"
+
+// EnvPrefix is the prefix of the environment variables overriding the configuration, e.g. BLOG_SERVER_HTTP_LISTENADDR
+const EnvPrefix = "BLOG"
+
+// Version of the application, set at build time with -ldflags "-X <module>/internal/config.Version=<version>"
+var Version = "dev"
+
+// Configuration contains the data structure for the environment configuration.
+type Configuration struct {
+	AppName string `default:"golang-blog-backend" validate:"required"`
+
+	EnvironmentName string
+
+	LogLevel string `default:"info" validate:"oneof=debug info warn error dpanic panic fatal"`
+
+	Log struct {
+		Outputs  []logger.Output
+		Sampling *logger.Sampling
+		Hostname bool
+		Fields   map[string]string
+	}
+
+	// HealthCheckEndpoint answers like /livez, for load balancers configured before the probes existed
+	HealthCheckEndpoint string `default:"/health-check" validate:"required,prefix=/"`
+
+	// Health configures the checks of the /livez and /readyz probes
+	Health health.Config
+
+	Site feed.Site
+
+	Server struct {
+		HTTP struct {
+			// Network of the listener: tcp, tcp4, tcp6 or unix, whose ListenAddr is the socket path
+			Network    string `default:"tcp" validate:"oneof=tcp tcp4 tcp6 unix"`
+			ListenAddr string `default:":8080" validate:"required"`
+			AccessLog  middleware.AccessLogConfig
+		}
+
+		// Admin serves the operational endpoints, such as /metrics, apart from the public API
+		Admin struct {
+			Disabled   bool
+			Network    string `default:"tcp" validate:"oneof=tcp tcp4 tcp6 unix"`
+			ListenAddr string `default:":9090" validate:"required"`
+		}
+	}
+
+	Database postgres.Config
+
+	// Lifecycle bounds how long the components take to start and to stop
+	Lifecycle lifecycle.Config
+
+	// Tracing configures where the spans of the requests, services and queries are exported
+	Tracing tracing.Config
+
+	Feed feed.Config
+
+	Auth auth.Config
+
+	FeatureFlags featureflag.Config
+
+	// Reload watches the configuration files and applies the changes of the log level, rate limits and
+	// feature flags while the server runs
+	Reload struct {
+		Disabled bool
+		Interval time.Duration `default:"5s" validate:"min=100ms"`
+	}
+
+	RateLimit struct {
+		// TrustedProxies are the addresses and CIDR ranges of the proxies whose forwarded headers are honored
+		TrustedProxies []string
+
+		// Groups are the limits of every route group, by group name. The "auth_failures" group limits the
+		// requests answered with 401 Unauthorized by client IP address
+		Groups map[string]ratelimit.Limit
+	}
+}
+
+// Source tells where the configuration is loaded from
+type Source struct {
+	// Environment is the name of the env.<name>.yaml file
+	Environment string
+
+	// Path is the directory with the configuration files. BLOG_CONFIG_PATH or configs/environment is used
+	// when it is empty
+	Path string
+
+	// Overrides are written as <field path>=<value>, e.g. Server.HTTP.ListenAddr=:8081
+	Overrides []string
+}
+
+// SourceFromFlags reads the source from the "environment", "config-path" and "set" flags
+func SourceFromFlags(flags *pflag.FlagSet) (Source, error) {
+	var source Source
+	var err error
+	if source.Environment, err = flags.GetString("environment"); err != nil {
+		return source, err
+	}
+	if source.Path, err = flags.GetString("config-path"); err != nil {
+		return source, err
+	}
+	if source.Overrides, err = flags.GetStringArray("set"); err != nil {
+		return source, err
+	}
+	return source, nil
+}
+
+// Load reads the configuration in layers and validates it: the default values of the fields, the YAML
+// file of the environment, its optional env.<name>.local.yaml, the BLOG_* environment variables and
+// finally the overrides of the source. A zero value set by any layer is kept over the default
+func Load(source Source) (*Configuration, error) {
+	envconfig, err := Read(source)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := environment.Validate(envconfig); err != nil {
+		return nil, err
+	}
+
+	return envconfig, nil
+}
+
+// Read reads the configuration in layers like Load, without validating it
+func Read(source Source) (*Configuration, error) {
+	envconfig := &Configuration{}
+	if err := loader(source).Load(envconfig); err != nil {
+		return nil, fmt.Errorf("failed to load environment config: %w", err)
+	}
+
+	// forces EnvironmentName to be always equal to the environment received
+	envconfig.EnvironmentName = source.Environment
+
+	return envconfig, nil
+}
+
+// CheckStructure loads and validates the configuration like Load, but tolerates the required references
+// without a value, usually secrets that only exist where the application runs. The fields of those
+// references aren't validated, and their paths are returned
+func CheckStructure(source Source) (unresolved []string, err error) {
+	envconfig := &Configuration{}
+	unresolved, err = loader(source).LoadStructure(envconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load environment config: %w", err)
+	}
+	envconfig.EnvironmentName = source.Environment
+
+	var errs environment.ValidationErrors
+	if err := environment.Validate(envconfig); errors.As(err, &errs) {
+		if errs = errs.Except(unresolved); len(errs) > 0 {
+			return unresolved, errs
+		}
+	} else if err != nil {
+		return unresolved, err
+	}
+
+	return unresolved, nil
+}
+
+// loader returns the loader of the layers of the source
+func loader(source Source) environment.Loader {
+	return environment.Loader{
+		Path:      Path(source),
+		Name:      source.Environment,
+		EnvPrefix: EnvPrefix,
+		Overrides: source.Overrides,
+	}
+}
+
+// Path returns the directory with the configuration files of the source
+func Path(source Source) string {
+	path := source.Path
+	if path == "" {
+		path = os.Getenv(EnvPrefix + "_CONFIG_PATH")
+	}
+	if path == "" {
+		path = environmentPath
+	}
+	return environment.ResolvePath(path)
+}
+
+// LoggerConfig returns the logger configuration for this environment
+func (c *Configuration) LoggerConfig() logger.Config {
+	return logger.Config{
+		LogLevel:   c.LogLevel,
+		AppName:    c.AppName,
+		Production: c.EnvironmentName == "production",
+		Outputs:    c.Log.Outputs,
+		Sampling:   c.Log.Sampling,
+		Version:    Version,
+		Hostname:   c.Log.Hostname,
+		Fields:     c.Log.Fields,
+	}
+}
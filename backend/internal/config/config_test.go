@@ -0,0 +1,104 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/environment"
+)
+
+// committedPath is the directory with the committed configuration files, from this package
+const committedPath = "../../configs/environment"
+
+func TestCommittedEnvironmentsStructure(t *testing.T) {
+	names, err := environment.Names(committedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) == 0 {
+		t.Fatalf("no environment found in %s", committedPath)
+	}
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			// the secrets of the deployments aren't available here
+			if _, err := CheckStructure(Source{Environment: name, Path: committedPath}); err != nil {
+				t.Errorf("CheckStructure() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckStructure(t *testing.T) {
+	tests := []struct {
+		name           string
+		yaml           string
+		wantUnresolved []string
+		wantErr        bool
+	}{
+		{
+			name:           "unresolved secret",
+			yaml:           "auth: {issuer: blog, accessTokenSecret: '${FILE:/run/secrets/missing:?mount it}'}\n",
+			wantUnresolved: []string{"Auth.AccessTokenSecret"},
+			wantErr:        true,
+		},
+		{
+			name: "unresolved secrets",
+			yaml: "site: {title: Blog, baseURL: 'https://blog.example.com'}\ndatabase: {dsn: 'postgres://blog'}\n" +
+				"auth:\n  issuer: blog\n  accessTokenSecret: '${FILE:/run/secrets/missing:?mount it}'\n" +
+				"  refreshTokenSecret: '${FILE:/run/secrets/missing-too:?mount it}'\n",
+			wantUnresolved: []string{"Auth.AccessTokenSecret", "Auth.RefreshTokenSecret"},
+		},
+		{
+			name:    "invalid value",
+			yaml:    "logLevel: verbose\nauth: {issuer: blog, accessTokenSecret: '${ENV:MISSING:?}', refreshTokenSecret: '${ENV:MISSING:?}'}\n",
+			wantErr: true,
+		},
+		{
+			name:    "malformed YAML",
+			yaml:    "auth: [\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "env.test.yaml"), []byte(tt.yaml), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			unresolved, err := CheckStructure(Source{Environment: "test", Path: dir})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckStructure() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantUnresolved != nil && strings.Join(unresolved, ",") != strings.Join(tt.wantUnresolved, ",") {
+				t.Errorf("CheckStructure() unresolved = %v, want %v", unresolved, tt.wantUnresolved)
+			}
+		})
+	}
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	envconfig, err := Read(Source{Environment: "development", Path: committedPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := environment.WriteYAML(&out, envconfig); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, value := range []string{envconfig.Auth.AccessTokenSecret, envconfig.Auth.RefreshTokenSecret} {
+		if strings.Contains(out.String(), value) {
+			t.Errorf("the printed configuration contains the secret %q", value)
+		}
+	}
+	if !strings.Contains(out.String(), "AccessTokenSecret: '[REDACTED]'") {
+		t.Errorf("the printed configuration doesn't redact the token secrets:\n%s", out.String())
+	}
+}
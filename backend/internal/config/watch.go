@@ -0,0 +1,76 @@
+package config
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/environment"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+)
+
+// Watcher publishes the configuration of the source every time its files change, or when Reload is
+// called. Invalid configurations are logged and the previous one stays active
+type Watcher struct {
+	watcher *environment.Watcher
+	logger  zaplog.Logger
+}
+
+// NewWatcher creates a watcher whose current configuration is the initial one, loaded from the source
+func NewWatcher(logger zaplog.Logger, source Source, initial *Configuration) *Watcher {
+	files := environment.Loader{Path: Path(source), Name: source.Environment}.Files()
+	load := func() (interface{}, error) {
+		return Load(source)
+	}
+
+	w := &Watcher{
+		watcher: environment.NewWatcher(zaplog.Adapt(logger), load, initial, files...),
+		logger:  logger,
+	}
+	w.warnRestartRequired()
+	return w
+}
+
+// Current returns the active configuration, which must not be changed
+func (w *Watcher) Current() *Configuration {
+	return w.watcher.Current().(*Configuration)
+}
+
+// Subscribe calls fn with every new configuration, until the returned function is called
+func (w *Watcher) Subscribe(fn func(envconfig *Configuration)) (unsubscribe func()) {
+	return w.watcher.Subscribe(func(snapshot interface{}) {
+		fn(snapshot.(*Configuration))
+	})
+}
+
+// Run watches the files until the context is done, unless reloading is disabled in the configuration
+func (w *Watcher) Run(ctx context.Context) {
+	if w.Current().Reload.Disabled {
+		return
+	}
+	w.watcher.Run(ctx, w.Current().Reload.Interval)
+}
+
+// Reload loads the configuration now, e.g. when the process receives SIGHUP
+func (w *Watcher) Reload() error {
+	return w.watcher.Reload()
+}
+
+// warnRestartRequired logs the changes of settings that are only read when the application starts
+func (w *Watcher) warnRestartRequired() {
+	previous := w.Current()
+	w.Subscribe(func(envconfig *Configuration) {
+		if !reflect.DeepEqual(previous.Server, envconfig.Server) {
+			w.logger.Warn("Server configuration changed, it is applied on the next restart")
+		}
+		if !reflect.DeepEqual(previous.Database, envconfig.Database) {
+			w.logger.Warn("Database configuration changed, it is applied on the next restart")
+		}
+		if !reflect.DeepEqual(previous.Auth, envconfig.Auth) {
+			w.logger.Warn("Auth configuration changed, it is applied on the next restart")
+		}
+		if !reflect.DeepEqual(previous.Tracing, envconfig.Tracing) {
+			w.logger.Warn("Tracing configuration changed, it is applied on the next restart")
+		}
+		previous = envconfig
+	})
+}
@@ -0,0 +1,58 @@
+package feed
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/httphandler/response"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"go.uber.org/zap"
+)
+
+type handler struct {
+	svc Service
+}
+
+// RegisterRoutes adds the RSS and Atom routes. Feeds live next to the resources they describe
+// (e.g. /tags/{slug}/feed.xml), so they are registered on the given router instead of mounted
+func RegisterRoutes(r chi.Router, svc Service) {
+	h := &handler{svc: svc}
+
+	r.Get("/feed.xml", h.serve(FormatRSS, func(r *http.Request) Scope { return Scope{} }))
+	r.Get("/atom.xml", h.serve(FormatAtom, func(r *http.Request) Scope { return Scope{} }))
+
+	authorScope := func(r *http.Request) Scope { return Scope{AuthorID: chi.URLParam(r, "authorID")} }
+	r.Get("/authors/{authorID}/feed.xml", h.serve(FormatRSS, authorScope))
+	r.Get("/authors/{authorID}/atom.xml", h.serve(FormatAtom, authorScope))
+
+	tagScope := func(r *http.Request) Scope { return Scope{TagSlug: chi.URLParam(r, "tagSlug")} }
+	r.Get("/tags/{tagSlug}/feed.xml", h.serve(FormatRSS, tagScope))
+	r.Get("/tags/{tagSlug}/atom.xml", h.serve(FormatAtom, tagScope))
+}
+
+// serve writes the feed, answering conditional requests with 304 Not Modified
+func (h *handler) serve(format Format, scope func(r *http.Request) Scope) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		document, err := h.svc.Build(r.Context(), format, scope(r))
+		if errors.Is(err, ErrNotFound) {
+			response.WithJSONError(w, r, http.StatusNotFound, err)
+			return
+		}
+		if err != nil {
+			zaplog.FromContext(r.Context()).Error("Unable to build feed", zap.String("path", r.URL.Path), zap.Error(err))
+			response.WithJSONError(w, r, http.StatusInternalServerError, nil)
+			return
+		}
+
+		if response.WithCacheValidators(w, r, document.ETag, document.LastModified) {
+			return
+		}
+
+		w.Header().Set("Content-Type", format.ContentType())
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(document.Body); err != nil {
+			zaplog.FromContext(r.Context()).Warn("Unable to write feed", zap.Error(err))
+		}
+	}
+}
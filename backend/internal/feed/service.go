@@ -0,0 +1,313 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/cache"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/httphandler/response"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/tracing"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/author"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/post"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/tag"
+)
+
+// cacheSize is the quantity of generated feeds kept in memory
+const cacheSize = 500
+
+// ErrNotFound is returned when the author or tag of the feed doesn't exist
+var ErrNotFound = errors.New("feed not found")
+
+// Config contains the configuration of the feeds
+type Config struct {
+	// Size is the quantity of newest published posts in every feed
+	Size int `default:"20" validate:"min=1,max=100"`
+
+	// CacheTTL is how long a generated feed is served before the database is queried again
+	CacheTTL time.Duration `validate:"min=0s"`
+}
+
+// Site describes the blog for which the feeds are generated
+type Site struct {
+	Title       string `validate:"required"`
+	Description string
+	BaseURL     string `validate:"required,url"`
+}
+
+type Service interface {
+	Build(ctx context.Context, format Format, scope Scope) (*Document, error)
+}
+
+type svc struct {
+	logger  zaplog.Logger
+	config  Config
+	site    Site
+	posts   post.Service
+	authors author.Service
+	tags    tag.Service
+	cache   *cache.LRU
+}
+
+type cachedDocument struct {
+	document  *Document
+	expiresAt time.Time
+}
+
+func NewService(logger zaplog.Logger, config Config, site Site, posts post.Service, authors author.Service, tags tag.Service) Service {
+	if config.Size <= 0 {
+		config.Size = 20
+	}
+	site.BaseURL = strings.TrimSuffix(site.BaseURL, "/")
+
+	return &svc{
+		logger:  logger,
+		config:  config,
+		site:    site,
+		posts:   posts,
+		authors: authors,
+		tags:    tags,
+		cache:   cache.NewLRU(cacheSize),
+	}
+}
+
+// channel contains the data shared by the RSS and Atom representations of a feed
+type channel struct {
+	title       string
+	description string
+	link        string
+	selfLink    string
+	authorName  string
+	posts       []post.Post
+	views       []*post.View
+	authors     map[uuid.UUID]string
+}
+
+// Build returns the feed for the scope, generating it when the cached version is missing or expired
+func (s *svc) Build(ctx context.Context, format Format, scope Scope) (*Document, error) {
+	ctx, span := tracing.Start(ctx, "feed.Service.Build")
+	defer span.End()
+
+	key := fmt.Sprintf("%s|%s|%s", format, scope.AuthorID, scope.TagSlug)
+	if cached, ok := s.cache.Get(key); ok && time.Now().Before(cached.(*cachedDocument).expiresAt) {
+		return cached.(*cachedDocument).document, nil
+	}
+
+	ch, err := s.load(ctx, format, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	var out interface{}
+	if format == FormatAtom {
+		out = s.atom(ch)
+	} else {
+		out = s.rss(ch)
+	}
+
+	var body bytes.Buffer
+	body.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&body)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(out); err != nil {
+		return nil, fmt.Errorf("unable to encode feed: %w", err)
+	}
+
+	document := &Document{
+		Body:         body.Bytes(),
+		ETag:         response.ETag(body.Bytes()),
+		LastModified: lastModified(ch.posts),
+	}
+	s.cache.Add(key, &cachedDocument{document: document, expiresAt: time.Now().Add(s.config.CacheTTL)})
+
+	return document, nil
+}
+
+// load queries the posts of the scope and everything needed to describe them
+func (s *svc) load(ctx context.Context, format Format, scope Scope) (*channel, error) {
+	ch := &channel{
+		title:       s.site.Title,
+		description: s.site.Description,
+		link:        s.site.BaseURL + "/",
+		authors:     map[uuid.UUID]string{},
+	}
+
+	var filter post.PublishedFilter
+	var scopePath string
+	switch {
+	case scope.AuthorID != "":
+		authorID, err := uuid.Parse(scope.AuthorID)
+		if err != nil {
+			return nil, ErrNotFound
+		}
+		a, err := s.authors.GetByID(ctx, authorID)
+		if errors.Is(err, author.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		filter.AuthorID = &authorID
+		scopePath = "/authors/" + authorID.String()
+		ch.title = fmt.Sprintf("%s - %s", s.site.Title, a.FullName())
+		ch.description = fmt.Sprintf("Posts by %s", a.FullName())
+		ch.authorName = a.FullName()
+		ch.authors[authorID] = a.FullName()
+	case scope.TagSlug != "":
+		t, err := s.tags.GetBySlug(ctx, scope.TagSlug)
+		if errors.Is(err, tag.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		filter.TagID = t.ID
+		scopePath = "/tags/" + *t.Slug
+		ch.title = fmt.Sprintf("%s - %s", s.site.Title, *t.Name)
+		ch.description = fmt.Sprintf("Posts tagged %s", *t.Name)
+	}
+
+	ch.link = s.site.BaseURL + scopePath + "/"
+	if format == FormatAtom {
+		ch.selfLink = s.site.BaseURL + scopePath + "/atom.xml"
+	} else {
+		ch.selfLink = s.site.BaseURL + scopePath + "/feed.xml"
+	}
+
+	posts, err := s.posts.GetPublishedPaginated(ctx, filter, database.Page{Limit: uint64(s.config.Size)})
+	if err != nil {
+		return nil, err
+	}
+	ch.posts = *posts
+
+	for _, p := range ch.posts {
+		view, err := s.posts.View(p, post.FormatHTML)
+		if err != nil {
+			return nil, err
+		}
+		ch.views = append(ch.views, view)
+
+		// author names are only needed by Atom entries
+		if format == FormatAtom && p.AuthorID != nil {
+			if _, ok := ch.authors[*p.AuthorID]; !ok {
+				a, err := s.authors.GetByID(ctx, *p.AuthorID)
+				if err != nil && !errors.Is(err, author.ErrNotFound) {
+					return nil, err
+				}
+				if a != nil {
+					ch.authors[*p.AuthorID] = a.FullName()
+				}
+			}
+		}
+	}
+
+	return ch, nil
+}
+
+func (s *svc) rss(ch *channel) *rss {
+	feed := &rss{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:       ch.title,
+			Link:        ch.link,
+			Description: ch.description,
+			Self:        atomLink{Href: ch.selfLink, Rel: "self", Type: FormatRSS.ContentType()},
+		},
+	}
+	if modified := lastModified(ch.posts); !modified.IsZero() {
+		feed.Channel.LastBuildDate = modified.Format(time.RFC1123Z)
+	}
+
+	for _, view := range ch.views {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       *view.Title,
+			Link:        s.postLink(view.Post),
+			GUID:        rssGUID{IsPermaLink: false, Value: "urn:uuid:" + view.ID.String()},
+			PubDate:     view.PublishedAt.Format(time.RFC1123Z),
+			Description: view.Excerpt,
+		})
+	}
+
+	return feed
+}
+
+func (s *svc) atom(ch *channel) *atomFeed {
+	updated := lastModified(ch.posts)
+	if updated.IsZero() {
+		updated = time.Unix(0, 0).UTC()
+	}
+
+	feedAuthor := ch.authorName
+	if feedAuthor == "" {
+		feedAuthor = s.site.Title
+	}
+
+	feed := &atomFeed{
+		Title:   ch.title,
+		ID:      ch.selfLink,
+		Updated: updated.Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: ch.selfLink, Rel: "self", Type: FormatAtom.ContentType()},
+			{Href: ch.link, Rel: "alternate", Type: "text/html"},
+		},
+		Author: atomAuthor{Name: feedAuthor},
+	}
+
+	for _, view := range ch.views {
+		entry := atomEntry{
+			Title:     *view.Title,
+			ID:        "urn:uuid:" + view.ID.String(),
+			Link:      atomLink{Href: s.postLink(view.Post), Rel: "alternate", Type: "text/html"},
+			Published: view.PublishedAt.Format(time.RFC3339),
+			Updated:   entryUpdated(view.Post).Format(time.RFC3339),
+			Summary:   view.Excerpt,
+			Content:   atomContent{Type: "html", Value: *view.Content},
+		}
+		if view.AuthorID != nil {
+			if name, ok := ch.authors[*view.AuthorID]; ok {
+				entry.Author = &atomAuthor{Name: name}
+			}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	return feed
+}
+
+// postLink returns the public URL of the post
+func (s *svc) postLink(p post.Post) string {
+	if p.Slug != nil {
+		return s.site.BaseURL + "/posts/" + *p.Slug
+	}
+	return s.site.BaseURL + "/posts/" + p.ID.String()
+}
+
+// entryUpdated returns the newest date between the publication and the last update of the post
+func entryUpdated(p post.Post) time.Time {
+	updated := *p.PublishedAt
+	if p.UpdateAt != nil && p.UpdateAt.After(updated) {
+		updated = *p.UpdateAt
+	}
+	return updated.UTC()
+}
+
+// lastModified returns the newest change among the posts
+func lastModified(posts []post.Post) time.Time {
+	var newest time.Time
+	for _, p := range posts {
+		if updated := entryUpdated(p); updated.After(newest) {
+			newest = updated
+		}
+	}
+	return newest
+}
@@ -0,0 +1,348 @@
+package post
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/httphandler/request"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/httphandler/response"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/auth"
+	"go.uber.org/zap"
+)
+
+// maxBodySize is the maximum quantity of bytes read from the body of a request, which leaves room for the JSON
+// escaping of the content
+const maxBodySize = 4 * maxContentLength
+
+type handler struct {
+	svc Service
+}
+
+// NewHandler creates the HTTP routes for posts and their revisions
+func NewHandler(svc Service) http.Handler {
+	h := &handler{svc: svc}
+
+	r := chi.NewRouter()
+	r.With(auth.Require(auth.PermissionCreatePosts)).Post("/", h.create)
+	r.Get("/", h.getAll)
+	r.Get("/by-slug/{slug}", h.getBySlug)
+	r.Route("/{id}", func(r chi.Router) {
+		r.Get("/", h.getByID)
+		r.Get("/revisions", h.getRevisions)
+		r.Get("/revisions/diff", h.diffRevisions)
+		r.Get("/revisions/{number}", h.getRevision)
+
+		// ownership of the post is checked by the service
+		r.Group(func(r chi.Router) {
+			r.Use(auth.RequireAuthenticated)
+			r.Put("/", h.update)
+			r.Delete("/", h.delete)
+			r.Post("/publish", h.publish)
+			r.Post("/unpublish", h.unpublish)
+			r.Post("/revisions/{number}/restore", h.restoreRevision)
+		})
+	})
+
+	return r
+}
+
+func (h *handler) create(w http.ResponseWriter, r *http.Request) {
+	var post Post
+	if !h.parseBody(w, r, &post) {
+		return
+	}
+
+	created, err := h.svc.Create(r.Context(), post)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusCreated, &response.HTTPResponse{Data: created})
+}
+
+func (h *handler) getAll(w http.ResponseWriter, r *http.Request) {
+	page, err := request.ParsePage(r)
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	format, err := ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	posts, err := h.svc.GetAllPaginated(r.Context(), page)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	views := make([]*View, 0, len(*posts))
+	for _, post := range *posts {
+		view, err := h.svc.View(post, format)
+		if err != nil {
+			h.withServiceError(w, r, err)
+			return
+		}
+		views = append(views, view)
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: views})
+}
+
+func (h *handler) getByID(w http.ResponseWriter, r *http.Request) {
+	id, err := request.UUIDParam(r, "id")
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	format, err := ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	post, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	view, err := h.svc.View(*post, format)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: view})
+}
+
+func (h *handler) getBySlug(w http.ResponseWriter, r *http.Request) {
+	requested := chi.URLParam(r, "slug")
+	format, err := ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	post, err := h.svc.GetBySlug(r.Context(), requested)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	// old slugs are permanently redirected to the current one
+	if post.Slug != nil && *post.Slug != requested {
+		location := *r.URL
+		location.Path = strings.TrimSuffix(r.URL.Path, requested) + *post.Slug
+		http.Redirect(w, r, location.String(), http.StatusMovedPermanently)
+		return
+	}
+
+	view, err := h.svc.View(*post, format)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: view})
+}
+
+func (h *handler) update(w http.ResponseWriter, r *http.Request) {
+	id, err := request.UUIDParam(r, "id")
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	var edit Edit
+	if !h.parseBody(w, r, &edit) {
+		return
+	}
+
+	// the editor is the authenticated user, never the one informed in the body
+	edit.EditorID = auth.PrincipalFromContext(r.Context()).AuthorID
+
+	post, err := h.svc.UpdateByID(r.Context(), id, edit)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: post})
+}
+
+func (h *handler) delete(w http.ResponseWriter, r *http.Request) {
+	id, err := request.UUIDParam(r, "id")
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.svc.DeleteByID(r.Context(), id); err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusNoContent, nil)
+}
+
+func (h *handler) publish(w http.ResponseWriter, r *http.Request) {
+	id, err := request.UUIDParam(r, "id")
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	post, err := h.svc.Publish(r.Context(), id)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: post})
+}
+
+func (h *handler) unpublish(w http.ResponseWriter, r *http.Request) {
+	id, err := request.UUIDParam(r, "id")
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	post, err := h.svc.Unpublish(r.Context(), id)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: post})
+}
+
+func (h *handler) getRevisions(w http.ResponseWriter, r *http.Request) {
+	id, err := request.UUIDParam(r, "id")
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	revisions, err := h.svc.GetRevisions(r.Context(), id)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: revisions})
+}
+
+func (h *handler) getRevision(w http.ResponseWriter, r *http.Request) {
+	id, err := request.UUIDParam(r, "id")
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	number, err := request.IntParam(r, "number")
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	revision, err := h.svc.GetRevision(r.Context(), id, number)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: revision})
+}
+
+func (h *handler) diffRevisions(w http.ResponseWriter, r *http.Request) {
+	id, err := request.UUIDParam(r, "id")
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	from, err := request.IntQuery(r, "from")
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	to, err := request.IntQuery(r, "to")
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	diff, err := h.svc.DiffRevisions(r.Context(), id, from, to)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: diff})
+}
+
+func (h *handler) restoreRevision(w http.ResponseWriter, r *http.Request) {
+	id, err := request.UUIDParam(r, "id")
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	number, err := request.IntParam(r, "number")
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	editorID := auth.PrincipalFromContext(r.Context()).AuthorID
+	post, err := h.svc.RestoreRevision(r.Context(), id, number, editorID)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: post})
+}
+
+// parseBody parses the request body, which can't be larger than maxBodySize. It writes the error response and
+// returns false when the body is invalid
+func (h *handler) parseBody(w http.ResponseWriter, r *http.Request, output interface{}) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+	err := request.ParseBody(r, output)
+
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		response.WithJSONError(w, r, http.StatusRequestEntityTooLarge, err)
+		return false
+	case err != nil:
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return false
+	}
+
+	return true
+}
+
+// withServiceError translates the errors returned by the Service into HTTP responses
+func (h *handler) withServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		response.WithJSONError(w, r, http.StatusNotFound, err)
+	case errors.Is(err, ErrInvalidPost), errors.Is(err, ErrContentTooLong):
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+	case errors.Is(err, ErrConflict):
+		response.WithJSONError(w, r, http.StatusConflict, err)
+	case errors.Is(err, auth.ErrUnauthenticated):
+		response.WithJSONError(w, r, http.StatusUnauthorized, err)
+	case errors.Is(err, auth.ErrForbidden):
+		response.WithJSONError(w, r, http.StatusForbidden, err)
+	default:
+		zaplog.FromContext(r.Context()).Error("Unable to handle post request", zap.String("path", r.URL.Path), zap.Error(err))
+		response.WithJSONError(w, r, http.StatusInternalServerError, nil)
+	}
+}
@@ -0,0 +1,71 @@
+package post
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestCreateRejectsLargeBodies(t *testing.T) {
+	service, _ := newMockService(t)
+	handler := NewHandler(service)
+
+	body := `{"title":"Title","content":"` + strings.Repeat("a", maxBodySize) + `"}`
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)).WithContext(editorContext())
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+// notEqual matches the arguments of a query different from the value
+type notEqual struct {
+	value driver.Value
+}
+
+func (n notEqual) Match(v driver.Value) bool {
+	return v != n.value
+}
+
+func TestCreateIgnoresPublishedAt(t *testing.T) {
+	published := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	service, mock := newMockService(t)
+	handler := NewHandler(service)
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare(`SELECT count\(\*\) as count FROM post WHERE`).
+		ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectPrepare(`SELECT count\(\*\) as count FROM post_slug_history WHERE`).
+		ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	// the order of the columns isn't fixed, so none of the values may be the publication date of the body
+	args := make([]driver.Value, len(postColumns))
+	for i := range args {
+		args[i] = notEqual{published}
+	}
+	mock.ExpectPrepare(`^INSERT INTO post \(.+\) VALUES \(.+\)$`).
+		ExpectExec().WithArgs(args...).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare(`^INSERT INTO post_revision \(.+\) VALUES \(.+\)$`).
+		ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	body := `{"title":"Title","content":"Content","publishedAt":"` + published.Format(time.RFC3339) + `"}`
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)).WithContext(editorContext())
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+	}
+	if strings.Contains(w.Body.String(), "publishedAt") {
+		t.Errorf("created post is published: %s", w.Body)
+	}
+}
@@ -4,14 +4,83 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/slug"
 )
 
+// Post is a blog post. Content holds the Markdown source of the post
 type Post struct {
-	ID       *uuid.UUID
-	Title    *string
-	Content  *string
-	AuthorID *int
-	TagsID   *[]int
-	CreateAt *time.Time
-	UpdateAt *time.Time
+	ID       *uuid.UUID   `db:"id" json:"id,omitempty"`
+	Title    *string      `db:"title" json:"title,omitempty"`
+	Slug     *string      `db:"slug" json:"slug,omitempty"`
+	Content  *string      `db:"content" json:"content,omitempty"`
+	AuthorID *uuid.UUID   `db:"author_id" json:"authorId,omitempty"`
+	TagsID   *[]uuid.UUID `db:"-" json:"tagsId,omitempty"`
+	Revision *int         `db:"revision" json:"revision,omitempty"`
+	CreateAt *time.Time   `db:"created_at" json:"createdAt,omitempty"`
+	UpdateAt *time.Time   `db:"updated_at" json:"updatedAt,omitempty"`
+
+	// PublishedAt is nil while the post is a draft
+	PublishedAt *time.Time `db:"published_at" json:"publishedAt,omitempty"`
+}
+
+// IsPublished checks if the post is visible to readers at the given time
+func (p Post) IsPublished(now time.Time) bool {
+	return p.PublishedAt != nil && !p.PublishedAt.After(now)
+}
+
+// PostTag associates a post with one of its tags
+type PostTag struct {
+	PostID *uuid.UUID `db:"post_id"`
+	TagID  *uuid.UUID `db:"tag_id"`
+}
+
+// PublishedFilter narrows down the published posts to a single author or tag
+type PublishedFilter struct {
+	AuthorID *uuid.UUID
+	TagID    *uuid.UUID
+}
+
+// Revision is a snapshot of a post stored every time the post is created or updated
+type Revision struct {
+	ID        *uuid.UUID `db:"id" json:"id,omitempty"`
+	PostID    *uuid.UUID `db:"post_id" json:"postId,omitempty"`
+	Number    *int       `db:"number" json:"number,omitempty"`
+	Title     *string    `db:"title" json:"title,omitempty"`
+	Content   *string    `db:"content" json:"content,omitempty"`
+	EditorID  *uuid.UUID `db:"editor_id" json:"editorId,omitempty"`
+	CreatedAt *time.Time `db:"created_at" json:"createdAt,omitempty"`
+}
+
+// SlugHistory keeps a slug previously used by a post, so old URLs can be redirected
+type SlugHistory struct {
+	Slug      *string    `db:"slug" json:"slug,omitempty"`
+	PostID    *uuid.UUID `db:"post_id" json:"postId,omitempty"`
+	CreatedAt *time.Time `db:"created_at" json:"createdAt,omitempty"`
+}
+
+// newSlugHistory creates the history entry of a slug no longer used by the post
+func newSlugHistory(oldSlug string, postID uuid.UUID, createdAt time.Time) slug.Entry {
+	return &SlugHistory{Slug: &oldSlug, PostID: &postID, CreatedAt: &createdAt}
+}
+
+// RecordID returns the id of the post that used the slug
+func (h *SlugHistory) RecordID() *uuid.UUID {
+	return h.PostID
+}
+
+// RevisionDiff contains the changes between two revisions of the same post
+type RevisionDiff struct {
+	From      int    `json:"from"`
+	To        int    `json:"to"`
+	FromTitle string `json:"fromTitle"`
+	ToTitle   string `json:"toTitle"`
+	Diff      string `json:"diff"`
+}
+
+// Edit contains the data sent to update a post
+type Edit struct {
+	Title    *string      `json:"title"`
+	Content  *string      `json:"content"`
+	TagsID   *[]uuid.UUID `json:"tagsId"`
+	EditorID *uuid.UUID   `json:"editorId"`
 }
@@ -0,0 +1,118 @@
+package post
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/tracing"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/auth"
+)
+
+// Published matches the posts visible to readers: published, and not scheduled for later
+var Published = sq.Expr("published_at IS NOT NULL AND published_at <= now()")
+
+// visible restricts the posts matched by where to the ones the principal of the context may read. Drafts are
+// only visible to their authors and to the principals allowed to edit any post
+func visible(ctx context.Context, where sq.Sqlizer) sq.Sqlizer {
+	principal := auth.PrincipalFromContext(ctx)
+	if principal.Can(auth.PermissionEditAnyPost) {
+		return where
+	}
+
+	var restriction sq.Sqlizer = Published
+	if principal.Can(auth.PermissionEditOwnPosts) && principal.AuthorID != nil {
+		restriction = sq.Or{Published, sq.Eq{"author_id": principal.AuthorID}}
+	}
+	if where == nil {
+		return restriction
+	}
+	return sq.And{where, restriction}
+}
+
+// Publish makes the post visible to readers. Authors publish their own posts, editors publish anyone's.
+// Publishing an already published post keeps its publication date
+func (s *svc) Publish(ctx context.Context, ID uuid.UUID) (*Post, error) {
+	ctx, span := tracing.Start(ctx, "post.Service.Publish")
+	defer span.End()
+
+	post, err := s.GetByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.AuthorizeOwner(ctx, auth.PermissionPublishOwnPosts, auth.PermissionPublishAnyPost, post.AuthorID); err != nil {
+		return nil, err
+	}
+	if post.PublishedAt != nil {
+		return post, nil
+	}
+
+	now := time.Now().UTC()
+	_, err = s.repo.Update(ctx, map[string]interface{}{
+		"published_at": now,
+		"updated_at":   now,
+	}, sq.Eq{"id": ID})
+	if err != nil {
+		return nil, err
+	}
+
+	post.PublishedAt = &now
+	post.UpdateAt = &now
+	return post, nil
+}
+
+// Unpublish turns the post back into a draft
+func (s *svc) Unpublish(ctx context.Context, ID uuid.UUID) (*Post, error) {
+	ctx, span := tracing.Start(ctx, "post.Service.Unpublish")
+	defer span.End()
+
+	post, err := s.GetByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.AuthorizeOwner(ctx, auth.PermissionPublishOwnPosts, auth.PermissionPublishAnyPost, post.AuthorID); err != nil {
+		return nil, err
+	}
+	if post.PublishedAt == nil {
+		return post, nil
+	}
+
+	now := time.Now().UTC()
+	_, err = s.repo.Update(ctx, map[string]interface{}{
+		"published_at": nil,
+		"updated_at":   now,
+	}, sq.Eq{"id": ID})
+	if err != nil {
+		return nil, err
+	}
+
+	post.PublishedAt = nil
+	post.UpdateAt = &now
+	return post, nil
+}
+
+// GetPublishedPaginated returns the published posts, newest first
+func (s *svc) GetPublishedPaginated(ctx context.Context, filter PublishedFilter, page database.Page) (*[]Post, error) {
+	ctx, span := tracing.Start(ctx, "post.Service.GetPublishedPaginated")
+	defer span.End()
+
+	page.OrderBy = []string{"published_at desc", "id"}
+
+	where := sq.And{Published}
+	if filter.AuthorID != nil {
+		where = append(where, sq.Eq{"author_id": filter.AuthorID})
+	}
+	if filter.TagID != nil {
+		where = append(where, sq.Expr("id IN (SELECT post_id FROM post_tag WHERE tag_id = ?)", filter.TagID))
+	}
+
+	posts := []Post{}
+	err := s.repo.FindPage(ctx, where, page, &posts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &posts, nil
+}
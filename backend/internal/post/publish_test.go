@@ -0,0 +1,54 @@
+package post
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/auth"
+)
+
+func TestGetByIDHidesDrafts(t *testing.T) {
+	authorID := uuid.New()
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		where     string
+	}{
+		{
+			name:  "anonymous reader",
+			where: `WHERE \(id = \$1 AND published_at IS NOT NULL AND published_at <= now\(\)\) LIMIT 1`,
+		},
+		{
+			name:      "author",
+			principal: &auth.Principal{UserID: uuid.New(), Role: auth.RoleAuthor, AuthorID: &authorID},
+			where:     `WHERE \(id = \$1 AND \(published_at IS NOT NULL AND published_at <= now\(\) OR author_id = \$2\)\) LIMIT 1`,
+		},
+		{
+			name:      "editor",
+			principal: &auth.Principal{UserID: uuid.New(), Role: auth.RoleEditor},
+			where:     `WHERE id = \$1 LIMIT 1`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newMockService(t)
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = auth.WithPrincipal(ctx, tt.principal)
+			}
+
+			mock.ExpectPrepare(`SELECT .+ FROM post ` + tt.where)
+			mock.ExpectQuery(`SELECT .+ FROM post ` + tt.where).WillReturnRows(sqlmock.NewRows(postColumns))
+
+			_, err := service.GetByID(ctx, uuid.New())
+			if !errors.Is(err, ErrNotFound) {
+				t.Fatalf("GetByID() error = %v, want %v", err, ErrNotFound)
+			}
+		})
+	}
+}
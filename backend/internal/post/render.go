@@ -0,0 +1,178 @@
+package post
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/cache"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+const (
+	// excerptLength is the maximum quantity of characters of an excerpt
+	excerptLength = 200
+
+	// wordsPerMinute is the average reading speed used to estimate the reading time
+	wordsPerMinute = 200
+
+	// renderCacheSize is the quantity of rendered revisions kept in memory
+	renderCacheSize = 1000
+)
+
+// Format is the representation of Post.Content returned by the API
+type Format string
+
+const (
+	// FormatMarkdown returns the Markdown source stored in the post
+	FormatMarkdown Format = "markdown"
+
+	// FormatHTML returns the rendered and sanitized HTML of the post
+	FormatHTML Format = "html"
+)
+
+// ParseFormat validates the format requested by the client. An empty value defaults to FormatHTML
+func ParseFormat(value string) (Format, error) {
+	switch Format(value) {
+	case "", FormatHTML:
+		return FormatHTML, nil
+	case FormatMarkdown:
+		return FormatMarkdown, nil
+	default:
+		return "", fmt.Errorf("format must be %s or %s", FormatMarkdown, FormatHTML)
+	}
+}
+
+// View is the representation of a post returned by the API
+type View struct {
+	Post
+	Format             Format `json:"format"`
+	Excerpt            string `json:"excerpt"`
+	ReadingTimeMinutes int    `json:"readingTimeMinutes"`
+}
+
+// rendered contains everything derived from the Markdown source of a revision
+type rendered struct {
+	html               string
+	excerpt            string
+	readingTimeMinutes int
+}
+
+// Renderer converts the Markdown content of posts into sanitized HTML
+type Renderer struct {
+	markdown  goldmark.Markdown
+	sanitizer *bluemonday.Policy
+	stripper  *bluemonday.Policy
+	cache     *cache.LRU
+}
+
+// NewRenderer creates a Renderer that caches the output of every rendered revision
+func NewRenderer() *Renderer {
+	// UGCPolicy only allows safe tags and http, https and mailto URLs.
+	// Its nofollow rule is narrowed to external links so internal navigation stays followable
+	sanitizer := bluemonday.UGCPolicy()
+	sanitizer.RequireNoFollowOnLinks(false)
+	sanitizer.RequireNoFollowOnFullyQualifiedLinks(true)
+
+	return &Renderer{
+		markdown:  goldmark.New(goldmark.WithExtensions(extension.GFM)),
+		sanitizer: sanitizer,
+		stripper:  bluemonday.StrictPolicy(),
+		cache:     cache.NewLRU(renderCacheSize),
+	}
+}
+
+// View renders the post in the requested format
+func (r *Renderer) View(post Post, format Format) (*View, error) {
+	out, err := r.render(post)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &View{
+		Post:               post,
+		Format:             format,
+		Excerpt:            out.excerpt,
+		ReadingTimeMinutes: out.readingTimeMinutes,
+	}
+	if format == FormatHTML {
+		view.Content = &out.html
+	}
+
+	return view, nil
+}
+
+// render converts the post content, reusing the cached output of the same revision
+func (r *Renderer) render(post Post) (*rendered, error) {
+	var content string
+	if post.Content != nil {
+		content = *post.Content
+	}
+
+	// a revision never changes, so it identifies the rendered output
+	var key string
+	if post.ID != nil && post.Revision != nil {
+		key = fmt.Sprintf("%s:%d", post.ID, *post.Revision)
+		if cached, ok := r.cache.Get(key); ok {
+			return cached.(*rendered), nil
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := r.markdown.Convert([]byte(content), &buf); err != nil {
+		return nil, fmt.Errorf("unable to render post content: %w", err)
+	}
+	safeHTML := r.sanitizer.SanitizeBytes(buf.Bytes())
+
+	// the text without tags is used for the excerpt and reading time
+	text := strings.Fields(html.UnescapeString(r.stripper.Sanitize(string(safeHTML))))
+
+	out := &rendered{
+		html:               string(safeHTML),
+		excerpt:            excerpt(text),
+		readingTimeMinutes: readingTime(len(text)),
+	}
+	if key != "" {
+		r.cache.Add(key, out)
+	}
+
+	return out, nil
+}
+
+// excerpt joins the first words of the text until excerptLength is reached
+func excerpt(words []string) string {
+	var b strings.Builder
+	length := 0
+	for _, word := range words {
+		wordLength := utf8.RuneCountInString(word)
+		if length == 0 && wordLength > excerptLength {
+			// a single word longer than the excerpt, e.g. an URL, is cut between two characters
+			return string([]rune(word)[:excerptLength]) + "…"
+		}
+		if length > 0 && length+1+wordLength > excerptLength {
+			return b.String() + "…"
+		}
+		if length > 0 {
+			b.WriteString(" ")
+			length++
+		}
+		b.WriteString(word)
+		length += wordLength
+	}
+
+	return b.String()
+}
+
+// readingTime estimates how many minutes are needed to read the given quantity of words
+func readingTime(words int) int {
+	minutes := (words + wordsPerMinute - 1) / wordsPerMinute
+	if minutes < 1 {
+		return 1
+	}
+
+	return minutes
+}
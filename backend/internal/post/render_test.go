@@ -0,0 +1,129 @@
+package post
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExcerpt(t *testing.T) {
+	tests := []struct {
+		name  string
+		words []string
+		want  string
+	}{
+		{
+			name:  "short text",
+			words: []string{"Hello", "world"},
+			want:  "Hello world",
+		},
+		{
+			name:  "no words",
+			words: nil,
+			want:  "",
+		},
+		{
+			name:  "cut between words",
+			words: []string{strings.Repeat("a", excerptLength-2), "bcd"},
+			want:  strings.Repeat("a", excerptLength-2) + "…",
+		},
+		{
+			name:  "exactly the excerpt length",
+			words: []string{strings.Repeat("a", excerptLength-2), "b"},
+			want:  strings.Repeat("a", excerptLength-2) + " b",
+		},
+		{
+			name:  "first word longer than the excerpt",
+			words: []string{strings.Repeat("a", excerptLength+50), "b"},
+			want:  strings.Repeat("a", excerptLength) + "…",
+		},
+		{
+			name:  "multibyte characters are never split",
+			words: []string{strings.Repeat("é", excerptLength+1)},
+			want:  strings.Repeat("é", excerptLength) + "…",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := excerpt(tt.words); got != tt.want {
+				t.Errorf("excerpt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizer(t *testing.T) {
+	sanitizer := NewRenderer().sanitizer
+
+	tests := []struct {
+		name    string
+		in      string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "scripts are removed",
+			in:      `<p>Hello</p><script>alert(1)</script>`,
+			want:    []string{"<p>Hello</p>"},
+			notWant: []string{"<script", "alert(1)"},
+		},
+		{
+			name:    "javascript URLs are removed",
+			in:      `<a href="javascript:alert(1)">click</a>`,
+			want:    []string{"click"},
+			notWant: []string{"javascript:", "href"},
+		},
+		{
+			name:    "event handler attributes are removed",
+			in:      `<p onclick="alert(1)">text</p><img src="https://example.com/a.png" onerror="alert(1)">`,
+			want:    []string{"<p>text</p>", `src="https://example.com/a.png"`},
+			notWant: []string{"onclick", "onerror", "alert(1)"},
+		},
+		{
+			name: "external links are nofollow",
+			in:   `<a href="https://example.com/post">external</a>`,
+			want: []string{`href="https://example.com/post"`, `rel="nofollow"`},
+		},
+		{
+			name:    "internal links are followed",
+			in:      `<a href="/posts/other">internal</a>`,
+			want:    []string{`href="/posts/other"`},
+			notWant: []string{"nofollow"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sanitizer.Sanitize(tt.in)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Sanitize(%q) = %q, want it to contain %q", tt.in, got, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("Sanitize(%q) = %q, want it without %q", tt.in, got, notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestViewSanitizesMarkdown(t *testing.T) {
+	content := "[click](javascript:alert(1)) and [external](https://example.com)\n\n<script>alert(2)</script>\n"
+
+	view, err := NewRenderer().View(Post{Content: &content}, FormatHTML)
+	if err != nil {
+		t.Fatalf("View() error = %v", err)
+	}
+
+	got := *view.Content
+	for _, notWant := range []string{"javascript:", "<script", "alert(2)"} {
+		if strings.Contains(got, notWant) {
+			t.Errorf("View() content = %q, want it without %q", got, notWant)
+		}
+	}
+	if !strings.Contains(got, `<a href="https://example.com" rel="nofollow">external</a>`) {
+		t.Errorf("View() content = %q, want the external link with rel=\"nofollow\"", got)
+	}
+}
@@ -1 +1,57 @@
 package post
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database/postgres"
+)
+
+type Repository interface {
+	postgres.Pg
+}
+
+type repo struct {
+	postgres.Pg
+}
+
+func NewRepository(session *sqlx.DB) Repository {
+	return &repo{Pg: postgres.NewRepository("post", session)}
+}
+
+// RevisionRepository stores the revision history of the posts
+type RevisionRepository interface {
+	postgres.Pg
+}
+
+type revisionRepo struct {
+	postgres.Pg
+}
+
+func NewRevisionRepository(session *sqlx.DB) RevisionRepository {
+	return &revisionRepo{Pg: postgres.NewRepository("post_revision", session)}
+}
+
+// SlugHistoryRepository stores the slugs previously used by the posts
+type SlugHistoryRepository interface {
+	postgres.Pg
+}
+
+type slugHistoryRepo struct {
+	postgres.Pg
+}
+
+func NewSlugHistoryRepository(session *sqlx.DB) SlugHistoryRepository {
+	return &slugHistoryRepo{Pg: postgres.NewRepository("post_slug_history", session)}
+}
+
+// TagRepository stores the association between posts and tags
+type TagRepository interface {
+	postgres.Pg
+}
+
+type tagRepo struct {
+	postgres.Pg
+}
+
+func NewTagRepository(session *sqlx.DB) TagRepository {
+	return &tagRepo{Pg: postgres.NewRepository("post_tag", session)}
+}
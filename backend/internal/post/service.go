@@ -1 +1,400 @@
 package post
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database/postgres"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/slug"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/textdiff"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/tracing"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/auth"
+	"go.uber.org/zap"
+)
+
+// maxContentLength is the maximum quantity of bytes of the Markdown content of a post, which bounds the cost
+// of rendering it and of diffing its revisions
+const maxContentLength = 200000
+
+var (
+	// ErrNotFound is returned when the post or the revision doesn't exist
+	ErrNotFound = errors.New("post not found")
+
+	// ErrInvalidPost is returned when required post data is missing
+	ErrInvalidPost = errors.New("post title and content are required")
+
+	// ErrConflict is returned when a concurrent change of the post took the same slug or revision number
+	ErrConflict = errors.New("the post was changed concurrently, try again")
+
+	// ErrContentTooLong is returned when the content of the post exceeds maxContentLength
+	ErrContentTooLong = fmt.Errorf("post content must have at most %d bytes", maxContentLength)
+)
+
+type Service interface {
+	Create(ctx context.Context, post Post) (*Post, error)
+	GetAllPaginated(ctx context.Context, page database.Page) (*[]Post, error)
+	GetByID(ctx context.Context, ID uuid.UUID) (*Post, error)
+	UpdateByID(ctx context.Context, ID uuid.UUID, edit Edit) (*Post, error)
+	DeleteByID(ctx context.Context, ID uuid.UUID) error
+	GetRevisions(ctx context.Context, ID uuid.UUID) (*[]Revision, error)
+	GetRevision(ctx context.Context, ID uuid.UUID, number int) (*Revision, error)
+	DiffRevisions(ctx context.Context, ID uuid.UUID, from int, to int) (*RevisionDiff, error)
+	RestoreRevision(ctx context.Context, ID uuid.UUID, number int, editorID *uuid.UUID) (*Post, error)
+	View(post Post, format Format) (*View, error)
+	GetBySlug(ctx context.Context, slug string) (*Post, error)
+	Publish(ctx context.Context, ID uuid.UUID) (*Post, error)
+	Unpublish(ctx context.Context, ID uuid.UUID) (*Post, error)
+	GetPublishedPaginated(ctx context.Context, filter PublishedFilter, page database.Page) (*[]Post, error)
+}
+
+type svc struct {
+	logger       zaplog.Logger
+	repo         Repository
+	revisionRepo RevisionRepository
+	slugs        *slug.History
+	tagRepo      TagRepository
+	renderer     *Renderer
+}
+
+func NewService(logger zaplog.Logger, repo Repository, revisionRepo RevisionRepository, slugRepo SlugHistoryRepository,
+	tagRepo TagRepository, renderer *Renderer) Service {
+	return &svc{
+		logger:       logger,
+		repo:         repo,
+		revisionRepo: revisionRepo,
+		slugs:        slug.NewHistory(repo, slugRepo, "post_id", newSlugHistory),
+		tagRepo:      tagRepo,
+		renderer:     renderer,
+	}
+}
+
+// Create stores a new post together with its first revision. Posts are always created as drafts, see
+// Publish. Authors always create their own posts, only principals allowed to edit any post may attribute
+// it to another author
+func (s *svc) Create(ctx context.Context, post Post) (*Post, error) {
+	ctx, span := tracing.Start(ctx, "post.Service.Create")
+	defer span.End()
+
+	if err := auth.Authorize(ctx, auth.PermissionCreatePosts); err != nil {
+		return nil, err
+	}
+	if principal := auth.PrincipalFromContext(ctx); !principal.Can(auth.PermissionEditAnyPost) {
+		if principal.AuthorID == nil {
+			return nil, auth.ErrForbidden
+		}
+		post.AuthorID = principal.AuthorID
+	}
+
+	if post.Title == nil || post.Content == nil {
+		return nil, ErrInvalidPost
+	}
+	if len(*post.Content) > maxContentLength {
+		return nil, ErrContentTooLong
+	}
+
+	id := uuid.New()
+	now := time.Now().UTC()
+	number := 1
+	post.ID = &id
+	post.Revision = &number
+	post.CreateAt = &now
+	post.UpdateAt = &now
+	post.PublishedAt = nil
+
+	err := s.inTx(ctx, func(tx *postgres.Tx) error {
+		postSlug, err := s.uniqueSlug(ctx, tx, post)
+		if err != nil {
+			return err
+		}
+		post.Slug = &postSlug
+
+		err = s.repo.WithTx(tx).Insert(ctx, post, nil)
+		if err != nil {
+			return err
+		}
+		if post.TagsID != nil {
+			if err := s.replaceTags(ctx, tx, id, *post.TagsID); err != nil {
+				return err
+			}
+		}
+		return s.insertRevision(ctx, tx, post, post.AuthorID)
+	})
+	if postgres.IsUniqueViolation(err) {
+		return nil, ErrConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &post, nil
+}
+
+// GetAllPaginated returns the posts visible to the principal of the context, newest first
+func (s *svc) GetAllPaginated(ctx context.Context, page database.Page) (*[]Post, error) {
+	ctx, span := tracing.Start(ctx, "post.Service.GetAllPaginated")
+	defer span.End()
+
+	page.OrderBy = []string{"created_at desc"}
+
+	posts := []Post{}
+	err := s.repo.FindPage(ctx, visible(ctx, nil), page, &posts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &posts, nil
+}
+
+// GetByID returns the post when it is visible to the principal of the context, drafts are not found otherwise
+func (s *svc) GetByID(ctx context.Context, ID uuid.UUID) (*Post, error) {
+	ctx, span := tracing.Start(ctx, "post.Service.GetByID")
+	defer span.End()
+
+	var post Post
+	err := s.repo.FindOne(ctx, visible(ctx, sq.Eq{"id": ID}), &post)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.loadTags(ctx, &post); err != nil {
+		return nil, err
+	}
+
+	return &post, nil
+}
+
+// UpdateByID updates the post and stores the result as a new revision in the same transaction. Concurrent
+// updates of the same post are serialized
+func (s *svc) UpdateByID(ctx context.Context, ID uuid.UUID, edit Edit) (*Post, error) {
+	ctx, span := tracing.Start(ctx, "post.Service.UpdateByID")
+	defer span.End()
+
+	if edit.Content != nil && len(*edit.Content) > maxContentLength {
+		return nil, ErrContentTooLong
+	}
+
+	var updated Post
+	err := s.inTx(ctx, func(tx *postgres.Tx) error {
+		postTx := s.repo.WithTx(tx)
+
+		// the row stays locked until the end of the transaction, so concurrent updates take the next revision
+		var current Post
+		err := postTx.FindOneForUpdate(ctx, sq.Eq{"id": ID}, &current)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := auth.AuthorizeOwner(ctx, auth.PermissionEditOwnPosts, auth.PermissionEditAnyPost, current.AuthorID); err != nil {
+			return err
+		}
+
+		// only the informed fields are changed
+		if edit.Title != nil && *edit.Title != *current.Title {
+			current.Title = edit.Title
+			if err := s.changeSlug(ctx, tx, &current); err != nil {
+				return err
+			}
+		}
+		if edit.Content != nil {
+			current.Content = edit.Content
+		}
+		if edit.TagsID != nil {
+			current.TagsID = edit.TagsID
+			if err := s.replaceTags(ctx, tx, ID, *edit.TagsID); err != nil {
+				return err
+			}
+		}
+		now := time.Now().UTC()
+		number := *current.Revision + 1
+		current.Revision = &number
+		current.UpdateAt = &now
+
+		_, err = postTx.Update(ctx, map[string]interface{}{
+			"title":      current.Title,
+			"slug":       current.Slug,
+			"content":    current.Content,
+			"revision":   current.Revision,
+			"updated_at": current.UpdateAt,
+		}, sq.Eq{"id": ID})
+		if err != nil {
+			return err
+		}
+
+		updated = current
+		return s.insertRevision(ctx, tx, current, edit.EditorID)
+	})
+	if postgres.IsUniqueViolation(err) {
+		return nil, ErrConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+func (s *svc) DeleteByID(ctx context.Context, ID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "post.Service.DeleteByID")
+	defer span.End()
+
+	post, err := s.GetByID(ctx, ID)
+	if err != nil {
+		return err
+	}
+	if err := auth.AuthorizeOwner(ctx, auth.PermissionEditOwnPosts, auth.PermissionEditAnyPost, post.AuthorID); err != nil {
+		return err
+	}
+
+	// revisions are removed by the foreign key cascade
+	deleted, err := s.repo.Remove(ctx, sq.Eq{"id": ID}, true)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// GetRevisions returns all revisions of the post, newest first
+func (s *svc) GetRevisions(ctx context.Context, ID uuid.UUID) (*[]Revision, error) {
+	ctx, span := tracing.Start(ctx, "post.Service.GetRevisions")
+	defer span.End()
+
+	if _, err := s.GetByID(ctx, ID); err != nil {
+		return nil, err
+	}
+
+	revisions := []Revision{}
+	err := s.revisionRepo.FindPage(ctx, sq.Eq{"post_id": ID}, database.Page{
+		OrderBy: []string{"number desc"},
+	}, &revisions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &revisions, nil
+}
+
+// GetRevision returns a single revision of the post
+func (s *svc) GetRevision(ctx context.Context, ID uuid.UUID, number int) (*Revision, error) {
+	ctx, span := tracing.Start(ctx, "post.Service.GetRevision")
+	defer span.End()
+
+	if _, err := s.GetByID(ctx, ID); err != nil {
+		return nil, err
+	}
+
+	return s.findRevision(ctx, ID, number)
+}
+
+// findRevision returns a revision without checking if the post is visible
+func (s *svc) findRevision(ctx context.Context, ID uuid.UUID, number int) (*Revision, error) {
+	var revision Revision
+	err := s.revisionRepo.FindOne(ctx, sq.Eq{"post_id": ID, "number": number}, &revision)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &revision, nil
+}
+
+// DiffRevisions returns the unified diff of the content between two revisions
+func (s *svc) DiffRevisions(ctx context.Context, ID uuid.UUID, from int, to int) (*RevisionDiff, error) {
+	ctx, span := tracing.Start(ctx, "post.Service.DiffRevisions")
+	defer span.End()
+
+	if _, err := s.GetByID(ctx, ID); err != nil {
+		return nil, err
+	}
+
+	fromRevision, err := s.findRevision(ctx, ID, from)
+	if err != nil {
+		return nil, err
+	}
+	toRevision, err := s.findRevision(ctx, ID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RevisionDiff{
+		From:      from,
+		To:        to,
+		FromTitle: *fromRevision.Title,
+		ToTitle:   *toRevision.Title,
+		Diff: textdiff.Unified(
+			fmt.Sprintf("revision %d", from), fmt.Sprintf("revision %d", to),
+			*fromRevision.Content, *toRevision.Content,
+		),
+	}, nil
+}
+
+// RestoreRevision copies an older revision into the post, which creates a new revision
+func (s *svc) RestoreRevision(ctx context.Context, ID uuid.UUID, number int, editorID *uuid.UUID) (*Post, error) {
+	ctx, span := tracing.Start(ctx, "post.Service.RestoreRevision")
+	defer span.End()
+
+	revision, err := s.GetRevision(ctx, ID, number)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.UpdateByID(ctx, ID, Edit{
+		Title:    revision.Title,
+		Content:  revision.Content,
+		EditorID: editorID,
+	})
+}
+
+// View renders the Markdown content of the post in the requested format
+func (s *svc) View(post Post, format Format) (*View, error) {
+	return s.renderer.View(post, format)
+}
+
+// insertRevision stores a snapshot of the post inside the given transaction
+func (s *svc) insertRevision(ctx context.Context, tx *postgres.Tx, post Post, editorID *uuid.UUID) error {
+	id := uuid.New()
+	return s.revisionRepo.WithTx(tx).Insert(ctx, Revision{
+		ID:        &id,
+		PostID:    post.ID,
+		Number:    post.Revision,
+		Title:     post.Title,
+		Content:   post.Content,
+		EditorID:  editorID,
+		CreatedAt: post.UpdateAt,
+	}, nil)
+}
+
+// inTx runs fn inside a transaction, committing it when fn succeeds and rolling it back otherwise
+func (s *svc) inTx(ctx context.Context, fn func(tx *postgres.Tx) error) error {
+	tx, err := s.repo.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	err = fn(tx)
+	if err != nil {
+		if errRollback := tx.Rollback(); errRollback != nil {
+			zaplog.FromContext(ctx).Error("Unable to rollback post transaction", zap.Error(errRollback))
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
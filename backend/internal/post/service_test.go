@@ -0,0 +1,111 @@
+package post
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/auth"
+	"go.uber.org/zap"
+)
+
+// postColumns are the columns of the post table, in the order of the rows returned by postRow
+var postColumns = []string{
+	"id", "title", "slug", "content", "author_id", "revision", "created_at", "updated_at", "published_at",
+}
+
+// newMockService creates the service on top of the real repositories, backed by a mocked database whose
+// expectations must be met in order
+func newMockService(t *testing.T) (Service, sqlmock.Sqlmock) {
+	t.Helper()
+
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unable to create mocked database: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		conn.Close()
+	})
+
+	db := sqlx.NewDb(conn, "postgres")
+	service := NewService(zaplog.New(zap.NewNop()), NewRepository(db), NewRevisionRepository(db),
+		NewSlugHistoryRepository(db), NewTagRepository(db), NewRenderer())
+	return service, mock
+}
+
+// editorContext returns a context authenticated as an editor, who may edit any post
+func editorContext() context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{UserID: uuid.New(), Role: auth.RoleEditor})
+}
+
+// postRow returns a row of the post table with a single revision
+func postRow(id uuid.UUID, title string, slug string) *sqlmock.Rows {
+	now := time.Now().UTC()
+	return sqlmock.NewRows(postColumns).
+		AddRow(id, title, slug, "Content", uuid.New(), 1, now, now, now)
+}
+
+func TestContentLength(t *testing.T) {
+	title := "Title"
+	long := strings.Repeat("a", maxContentLength+1)
+
+	tests := []struct {
+		name string
+		call func(service Service) error
+	}{
+		{
+			name: "create",
+			call: func(service Service) error {
+				_, err := service.Create(editorContext(), Post{Title: &title, Content: &long})
+				return err
+			},
+		},
+		{
+			name: "update",
+			call: func(service Service) error {
+				_, err := service.UpdateByID(editorContext(), uuid.New(), Edit{Content: &long})
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the content is rejected before reaching the database
+			service, _ := newMockService(t)
+			if err := tt.call(service); !errors.Is(err, ErrContentTooLong) {
+				t.Fatalf("error = %v, want %v", err, ErrContentTooLong)
+			}
+		})
+	}
+}
+
+func TestUpdateConflict(t *testing.T) {
+	service, mock := newMockService(t)
+	id := uuid.New()
+	content := "New content"
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare(`SELECT .+ FROM post WHERE id = \$1 LIMIT 1 FOR UPDATE`).
+		ExpectQuery().WillReturnRows(postRow(id, "Title", "title"))
+	mock.ExpectPrepare(`UPDATE post SET`).
+		ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare(`^INSERT INTO post_revision \(.+\) VALUES \(.+\)$`).
+		ExpectExec().WillReturnError(&pq.Error{Code: "23505"})
+	mock.ExpectRollback()
+
+	_, err := service.UpdateByID(editorContext(), id, Edit{Content: &content})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("UpdateByID() error = %v, want %v", err, ErrConflict)
+	}
+}
@@ -0,0 +1,54 @@
+package post
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database/postgres"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/tracing"
+)
+
+// GetBySlug returns the post that uses the slug now or used it in the past, when it is visible to the principal
+// of the context. When the returned post has a different slug, the requested one is outdated
+func (s *svc) GetBySlug(ctx context.Context, postSlug string) (*Post, error) {
+	ctx, span := tracing.Start(ctx, "post.Service.GetBySlug")
+	defer span.End()
+
+	var post Post
+	err := s.repo.FindOne(ctx, visible(ctx, sq.Eq{"slug": postSlug}), &post)
+	if err == nil {
+		return &post, s.loadTags(ctx, &post)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	// the post may have been renamed
+	postID, err := s.slugs.Resolve(ctx, postSlug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, postID)
+}
+
+// changeSlug generates a new slug for the renamed post and keeps the previous one in the history
+func (s *svc) changeSlug(ctx context.Context, tx *postgres.Tx, post *Post) error {
+	newSlug, err := s.slugs.Change(ctx, tx, *post.Title, *post.ID, post.Slug)
+	if err != nil {
+		return err
+	}
+
+	post.Slug = &newSlug
+	return nil
+}
+
+// uniqueSlug derives a slug from the post title that isn't used, now or in the past, by other posts
+func (s *svc) uniqueSlug(ctx context.Context, tx *postgres.Tx, post Post) (string, error) {
+	return s.slugs.Unique(ctx, tx, *post.Title, *post.ID)
+}
@@ -0,0 +1,64 @@
+package post
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestRenameKeepsOldSlugResolvable(t *testing.T) {
+	service, mock := newMockService(t)
+	ctx := editorContext()
+	id := uuid.New()
+	title := "New title"
+
+	// rename: the old slug moves to the history, which has no id to return
+	mock.ExpectBegin()
+	mock.ExpectPrepare(`SELECT .+ FROM post WHERE id = \$1 LIMIT 1 FOR UPDATE`).
+		ExpectQuery().WillReturnRows(postRow(id, "Old title", "old-title"))
+	mock.ExpectPrepare(`SELECT count\(\*\) as count FROM post WHERE`).
+		ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectPrepare(`SELECT count\(\*\) as count FROM post_slug_history WHERE`).
+		ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectPrepare(`DELETE FROM post_slug_history WHERE`).
+		ExpectExec().WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare(`^INSERT INTO post_slug_history \(.+\) VALUES \(\$1,\$2,\$3\)$`).
+		ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare(`UPDATE post SET`).
+		ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare(`^INSERT INTO post_revision \(.+\) VALUES \(.+\)$`).
+		ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	updated, err := service.UpdateByID(ctx, id, Edit{Title: &title})
+	if err != nil {
+		t.Fatalf("UpdateByID() error = %v", err)
+	}
+	if *updated.Slug != "new-title" {
+		t.Fatalf("UpdateByID() slug = %q, want %q", *updated.Slug, "new-title")
+	}
+
+	// resolve: the old slug isn't used by any post, so it's looked up in the history
+	mock.ExpectPrepare(`SELECT .+ FROM post WHERE slug = \$1 LIMIT 1`)
+	mock.ExpectQuery(`SELECT .+ FROM post WHERE slug = \$1 LIMIT 1`).
+		WithArgs("old-title").WillReturnRows(sqlmock.NewRows(postColumns))
+	mock.ExpectPrepare(`SELECT .+ FROM post_slug_history WHERE slug = \$1 LIMIT 1`)
+	mock.ExpectQuery(`SELECT .+ FROM post_slug_history WHERE slug = \$1 LIMIT 1`).
+		WithArgs("old-title").
+		WillReturnRows(sqlmock.NewRows([]string{"slug", "post_id", "created_at"}).AddRow("old-title", id, time.Now()))
+	mock.ExpectPrepare(`SELECT .+ FROM post WHERE id = \$1 LIMIT 1`)
+	mock.ExpectQuery(`SELECT .+ FROM post WHERE id = \$1 LIMIT 1`).
+		WithArgs(id).WillReturnRows(postRow(id, title, "new-title"))
+	mock.ExpectPrepare(`SELECT \* FROM post_tag WHERE post_id = \$1`).
+		ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"post_id", "tag_id"}))
+
+	post, err := service.GetBySlug(ctx, "old-title")
+	if err != nil {
+		t.Fatalf("GetBySlug() error = %v", err)
+	}
+	if *post.ID != id || *post.Slug != "new-title" {
+		t.Fatalf("GetBySlug() = post %s with slug %q, want post %s with slug %q", post.ID, *post.Slug, id, "new-title")
+	}
+}
@@ -0,0 +1,52 @@
+package post
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database/postgres"
+)
+
+// loadTags fills the ids of the tags associated with the post
+func (s *svc) loadTags(ctx context.Context, post *Post) error {
+	postTags := []PostTag{}
+	err := s.tagRepo.Find(ctx, sq.Eq{"post_id": post.ID}, &postTags)
+	if err != nil {
+		return err
+	}
+
+	tagsID := make([]uuid.UUID, len(postTags))
+	for i, postTag := range postTags {
+		tagsID[i] = *postTag.TagID
+	}
+	post.TagsID = &tagsID
+
+	return nil
+}
+
+// replaceTags associates the post with exactly the given tags inside the transaction
+func (s *svc) replaceTags(ctx context.Context, tx *postgres.Tx, postID uuid.UUID, tagsID []uuid.UUID) error {
+	tagTx := s.tagRepo.WithTx(tx)
+
+	_, err := tagTx.Remove(ctx, sq.Eq{"post_id": postID}, true)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[uuid.UUID]bool, len(tagsID))
+	for _, tagID := range tagsID {
+		if seen[tagID] {
+			continue
+		}
+		seen[tagID] = true
+
+		tagID := tagID
+		err := tagTx.Insert(ctx, PostTag{PostID: &postID, TagID: &tagID}, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package post
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestCreateAssociatesTags(t *testing.T) {
+	service, mock := newMockService(t)
+	ctx := editorContext()
+	title := "Tagged post"
+	content := "Content"
+	authorID := uuid.New()
+	tagsID := []uuid.UUID{uuid.New(), uuid.New()}
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare(`SELECT count\(\*\) as count FROM post WHERE`).
+		ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectPrepare(`SELECT count\(\*\) as count FROM post_slug_history WHERE`).
+		ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectPrepare(`^INSERT INTO post \(.+\) VALUES \(.+\)$`).
+		ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare(`DELETE FROM post_tag WHERE post_id = \$1`).
+		ExpectExec().WillReturnResult(sqlmock.NewResult(0, 0))
+	// post_tag has no id to return, so a RETURNING clause would fail the insert
+	for range tagsID {
+		mock.ExpectPrepare(`^INSERT INTO post_tag \(.+\) VALUES \(\$1,\$2\)$`).
+			ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectPrepare(`^INSERT INTO post_revision \(.+\) VALUES \(.+\)$`).
+		ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	created, err := service.Create(ctx, Post{Title: &title, Content: &content, AuthorID: &authorID, TagsID: &tagsID})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if len(*created.TagsID) != len(tagsID) {
+		t.Fatalf("Create() tags = %v, want %v", *created.TagsID, tagsID)
+	}
+}
@@ -0,0 +1,72 @@
+package sitemap
+
+import (
+	"bufio"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/httphandler/request"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/httphandler/response"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"go.uber.org/zap"
+)
+
+type handler struct {
+	svc Service
+}
+
+// RegisterRoutes adds the sitemap index on /sitemap.xml and the sitemap files on /sitemap-{n}.xml
+func RegisterRoutes(r chi.Router, svc Service) {
+	h := &handler{svc: svc}
+
+	r.Get("/sitemap.xml", h.index)
+	r.Get("/sitemap-{file}.xml", h.file)
+}
+
+func (h *handler) index(w http.ResponseWriter, r *http.Request) {
+	// the quantity of files is checked before writing, so database errors still become a 500
+	if _, err := h.svc.Files(r.Context()); err != nil {
+		zaplog.FromContext(r.Context()).Error("Unable to count sitemap files", zap.Error(err))
+		response.WithJSONError(w, r, http.StatusInternalServerError, nil)
+		return
+	}
+
+	h.stream(w, r, func(bw *bufio.Writer) error { return h.svc.WriteIndex(r.Context(), bw) })
+}
+
+func (h *handler) file(w http.ResponseWriter, r *http.Request) {
+	file, err := request.IntParam(r, "file")
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	files, err := h.svc.Files(r.Context())
+	if err != nil {
+		zaplog.FromContext(r.Context()).Error("Unable to count sitemap files", zap.Error(err))
+		response.WithJSONError(w, r, http.StatusInternalServerError, nil)
+		return
+	}
+	if file < 1 || file > files {
+		response.WithJSONError(w, r, http.StatusNotFound, ErrNotFound)
+		return
+	}
+
+	h.stream(w, r, func(bw *bufio.Writer) error { return h.svc.Write(r.Context(), bw, file) })
+}
+
+// stream writes the XML directly into the response. Errors after the first byte can only be logged
+func (h *handler) stream(w http.ResponseWriter, r *http.Request, write func(bw *bufio.Writer) error) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	bw := bufio.NewWriter(w)
+	err := write(bw)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		zaplog.FromContext(r.Context()).Error("Unable to write sitemap", zap.Error(err))
+	}
+}
@@ -0,0 +1,226 @@
+// Package sitemap generates the sitemaps of the published content for search engines
+package sitemap
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/tracing"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/author"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/post"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/tag"
+)
+
+// MaxURLs is the maximum quantity of URLs of a single sitemap file
+const MaxURLs = 50000
+
+const namespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// ErrNotFound is returned when the requested sitemap file doesn't exist
+var ErrNotFound = errors.New("sitemap not found")
+
+type Service interface {
+	Files(ctx context.Context) (int, error)
+	WriteIndex(ctx context.Context, w io.Writer) error
+	Write(ctx context.Context, w io.Writer, file int) error
+}
+
+// source lists the URLs of one kind of content. Sources are concatenated and split into files of MaxURLs
+type source struct {
+	count func(ctx context.Context) (int64, error)
+	each  func(ctx context.Context, page database.Page, fn func(entry) error) error
+}
+
+type entry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type svc struct {
+	baseURL string
+	sources []source
+}
+
+func NewService(baseURL string, posts post.Repository, authors author.Repository, tags tag.Repository) Service {
+	s := &svc{baseURL: strings.TrimSuffix(baseURL, "/")}
+	published := post.Published
+
+	s.sources = []source{
+		{
+			count: func(ctx context.Context) (int64, error) { return posts.Count(ctx, published) },
+			each: func(ctx context.Context, page database.Page, fn func(entry) error) error {
+				page.OrderBy = []string{"published_at", "id"}
+				var p post.Post
+				return posts.Each(ctx, published, page, &p, func() error {
+					ref := p.ID.String()
+					if p.Slug != nil {
+						ref = *p.Slug
+					}
+					return fn(entry{Loc: s.baseURL + "/posts/" + ref, LastMod: lastMod(p.UpdateAt)})
+				})
+			},
+		},
+		{
+			count: func(ctx context.Context) (int64, error) { return authors.Count(ctx, nil) },
+			each: func(ctx context.Context, page database.Page, fn func(entry) error) error {
+				page.OrderBy = []string{"id"}
+				var a author.Author
+				return authors.Each(ctx, nil, page, &a, func() error {
+					return fn(entry{Loc: s.baseURL + "/authors/" + a.ID.String()})
+				})
+			},
+		},
+		{
+			count: func(ctx context.Context) (int64, error) { return tags.Count(ctx, nil) },
+			each: func(ctx context.Context, page database.Page, fn func(entry) error) error {
+				page.OrderBy = []string{"id"}
+				var t tag.Tag
+				return tags.Each(ctx, nil, page, &t, func() error {
+					ref := t.ID.String()
+					if t.Slug != nil {
+						ref = *t.Slug
+					}
+					return fn(entry{Loc: s.baseURL + "/tags/" + ref, LastMod: lastMod(t.UpdatedAt)})
+				})
+			},
+		},
+	}
+
+	return s
+}
+
+// Files returns how many sitemap files are needed for all URLs. At least one file always exists
+func (s *svc) Files(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "sitemap.Service.Files")
+	defer span.End()
+
+	var total int64
+	for _, src := range s.sources {
+		count, err := src.count(ctx)
+		if err != nil {
+			return 0, err
+		}
+		total += count
+	}
+
+	files := int((total + MaxURLs - 1) / MaxURLs)
+	if files == 0 {
+		files = 1
+	}
+
+	return files, nil
+}
+
+// WriteIndex writes the sitemap index referencing every sitemap file
+func (s *svc) WriteIndex(ctx context.Context, w io.Writer) error {
+	ctx, span := tracing.Start(ctx, "sitemap.Service.WriteIndex")
+	defer span.End()
+
+	files, err := s.Files(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	start := xml.StartElement{
+		Name: xml.Name{Local: "sitemapindex"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: namespace}},
+	}
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+	for file := 1; file <= files; file++ {
+		err := encoder.EncodeElement(struct {
+			Loc string `xml:"loc"`
+		}{Loc: s.baseURL + FileName(file)}, xml.StartElement{Name: xml.Name{Local: "sitemap"}})
+		if err != nil {
+			return err
+		}
+	}
+	if err := encoder.EncodeToken(start.End()); err != nil {
+		return err
+	}
+
+	return encoder.Flush()
+}
+
+// Write streams the URLs of the given sitemap file (starting at 1)
+func (s *svc) Write(ctx context.Context, w io.Writer, file int) error {
+	ctx, span := tracing.Start(ctx, "sitemap.Service.Write")
+	defer span.End()
+
+	files, err := s.Files(ctx)
+	if err != nil {
+		return err
+	}
+	if file < 1 || file > files {
+		return ErrNotFound
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	start := xml.StartElement{
+		Name: xml.Name{Local: "urlset"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: namespace}},
+	}
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+
+	// skip the URLs of the previous files, source by source
+	skip := int64(file-1) * MaxURLs
+	remaining := int64(MaxURLs)
+	for _, src := range s.sources {
+		if remaining == 0 {
+			break
+		}
+
+		count, err := src.count(ctx)
+		if err != nil {
+			return err
+		}
+		if skip >= count {
+			skip -= count
+			continue
+		}
+
+		page := database.Page{Offset: uint64(skip), Limit: uint64(remaining)}
+		err = src.each(ctx, page, func(e entry) error {
+			remaining--
+			return encoder.EncodeElement(e, xml.StartElement{Name: xml.Name{Local: "url"}})
+		})
+		if err != nil {
+			return err
+		}
+		skip = 0
+	}
+
+	if err := encoder.EncodeToken(start.End()); err != nil {
+		return err
+	}
+
+	return encoder.Flush()
+}
+
+// FileName returns the path of the given sitemap file
+func FileName(file int) string {
+	return fmt.Sprintf("/sitemap-%d.xml", file)
+}
+
+func lastMod(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
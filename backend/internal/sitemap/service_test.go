@@ -0,0 +1,202 @@
+package sitemap
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/author"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/post"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/tag"
+)
+
+const baseURL = "https://blog.example.com"
+
+// published is the condition that keeps the drafts and the scheduled posts out of the sitemap
+var published = regexp.QuoteMeta("WHERE published_at IS NOT NULL AND published_at <= now()")
+
+func newMockService(t *testing.T) (Service, sqlmock.Sqlmock) {
+	t.Helper()
+
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unable to create mocked database: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		conn.Close()
+	})
+
+	db := sqlx.NewDb(conn, "postgres")
+	service := NewService(baseURL+"/", post.NewRepository(db), author.NewRepository(db), tag.NewRepository(db))
+	return service, mock
+}
+
+// expectCounts expects the count of the posts, authors and tags, in the order of the sources
+func expectCounts(mock sqlmock.Sqlmock, posts, authors, tags int64) {
+	expectCount(mock, `FROM post `+published+`$`, posts)
+	expectCount(mock, `FROM author$`, authors)
+	expectCount(mock, `FROM tag$`, tags)
+}
+
+func expectCount(mock sqlmock.Sqlmock, from string, count int64) {
+	mock.ExpectPrepare(`^SELECT count\(\*\) as count ` + from).
+		ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+}
+
+// urls decodes the locations and modification dates of a sitemap file
+func urls(t *testing.T, sitemap []byte) []entry {
+	t.Helper()
+
+	var urlset struct {
+		URLs []entry `xml:"url"`
+	}
+	if err := xml.Unmarshal(sitemap, &urlset); err != nil {
+		t.Fatalf("invalid sitemap %s: %v", sitemap, err)
+	}
+	return urlset.URLs
+}
+
+func TestFiles(t *testing.T) {
+	tests := []struct {
+		name                 string
+		posts, authors, tags int64
+		want                 int
+	}{
+		{name: "no content", want: 1},
+		{name: "a single file", posts: 10, authors: 2, tags: 3, want: 1},
+		{name: "exactly full", posts: MaxURLs - 2, authors: 1, tags: 1, want: 1},
+		{name: "split into two files", posts: MaxURLs - 2, authors: 1, tags: 2, want: 2},
+		{name: "split into many files", posts: 2 * MaxURLs, authors: MaxURLs, tags: 1, want: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newMockService(t)
+			expectCounts(mock, tt.posts, tt.authors, tt.tags)
+
+			got, err := service.Files(context.Background())
+			if err != nil {
+				t.Fatalf("Files() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Files() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteIndex(t *testing.T) {
+	service, mock := newMockService(t)
+	expectCounts(mock, MaxURLs, 1, 0)
+
+	var buf bytes.Buffer
+	if err := service.WriteIndex(context.Background(), &buf); err != nil {
+		t.Fatalf("WriteIndex() error = %v", err)
+	}
+
+	var index struct {
+		XMLName  xml.Name
+		Sitemaps []struct {
+			Loc string `xml:"loc"`
+		} `xml:"sitemap"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &index); err != nil {
+		t.Fatalf("invalid sitemap index %s: %v", buf.Bytes(), err)
+	}
+	if index.XMLName.Space != namespace || index.XMLName.Local != "sitemapindex" {
+		t.Errorf("root element = %+v, want sitemapindex of %s", index.XMLName, namespace)
+	}
+	var got []string
+	for _, sitemap := range index.Sitemaps {
+		got = append(got, sitemap.Loc)
+	}
+	want := []string{baseURL + "/sitemap-1.xml", baseURL + "/sitemap-2.xml"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sitemaps = %v, want %v", got, want)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	postID, authorID, tagID := uuid.New(), uuid.New(), uuid.New()
+	updated := time.Date(2021, 3, 4, 15, 4, 5, 0, time.FixedZone("BRT", -3*60*60))
+
+	service, mock := newMockService(t)
+	expectCounts(mock, 2, 1, 1)
+	// drafts are filtered by the query, not after reading them
+	expectCount(mock, `FROM post `+published+`$`, 2)
+	mock.ExpectQuery(`^SELECT \* FROM post ` + published + ` ORDER BY published_at, id LIMIT 50000$`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "updated_at"}).
+			AddRow(postID, nil, updated).
+			AddRow(uuid.New(), "hello-world", nil))
+	expectCount(mock, `FROM author$`, 1)
+	mock.ExpectQuery(`^SELECT \* FROM author ORDER BY id LIMIT \d+$`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(authorID))
+	expectCount(mock, `FROM tag$`, 1)
+	mock.ExpectQuery(`^SELECT \* FROM tag ORDER BY id LIMIT \d+$`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "updated_at"}).AddRow(tagID, "go", updated))
+
+	var buf bytes.Buffer
+	if err := service.Write(context.Background(), &buf, 1); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	// the modification dates are in UTC, and omitted when unknown
+	want := []entry{
+		{Loc: baseURL + "/posts/" + postID.String(), LastMod: "2021-03-04T18:04:05Z"},
+		{Loc: baseURL + "/posts/hello-world"},
+		{Loc: baseURL + "/authors/" + authorID.String()},
+		{Loc: baseURL + "/tags/go", LastMod: "2021-03-04T18:04:05Z"},
+	}
+	if got := urls(t, buf.Bytes()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Write() = %+v, want %+v", got, want)
+	}
+}
+
+func TestWriteContinuesFromThePreviousFile(t *testing.T) {
+	authorID := uuid.New()
+
+	service, mock := newMockService(t)
+	expectCounts(mock, MaxURLs-1, 2, 0)
+	// the posts fill the first file but one URL, which is the first author
+	expectCount(mock, `FROM post `+published+`$`, MaxURLs-1)
+	expectCount(mock, `FROM author$`, 2)
+	mock.ExpectQuery(`^SELECT \* FROM author ORDER BY id LIMIT 50000 OFFSET 1$`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(authorID))
+	expectCount(mock, `FROM tag$`, 0)
+
+	var buf bytes.Buffer
+	if err := service.Write(context.Background(), &buf, 2); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	want := []entry{{Loc: baseURL + "/authors/" + authorID.String()}}
+	if got := urls(t, buf.Bytes()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Write() = %+v, want %+v", got, want)
+	}
+}
+
+func TestWriteMissingFile(t *testing.T) {
+	for _, file := range []int{0, 2} {
+		service, mock := newMockService(t)
+		expectCounts(mock, 1, 0, 0)
+
+		var buf bytes.Buffer
+		if err := service.Write(context.Background(), &buf, file); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Write(%d) error = %v, want %v", file, err, ErrNotFound)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("Write(%d) wrote %q, want nothing", file, buf.String())
+		}
+	}
+}
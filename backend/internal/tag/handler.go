@@ -0,0 +1,157 @@
+package tag
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/httphandler/request"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/httphandler/response"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"github.com/thiagoretondar/golang-blog-example/backend/internal/auth"
+	"go.uber.org/zap"
+)
+
+type handler struct {
+	svc Service
+}
+
+// NewHandler creates the HTTP routes for tags
+func NewHandler(svc Service) http.Handler {
+	h := &handler{svc: svc}
+
+	r := chi.NewRouter()
+	r.With(auth.Require(auth.PermissionManageTags)).Post("/", h.create)
+	r.Get("/", h.getAll)
+	r.Get("/by-slug/{slug}", h.getBySlug)
+	r.Route("/{id}", func(r chi.Router) {
+		r.Get("/", h.getByID)
+		r.With(auth.Require(auth.PermissionManageTags)).Put("/", h.update)
+		r.With(auth.Require(auth.PermissionManageTags)).Delete("/", h.delete)
+	})
+
+	return r
+}
+
+func (h *handler) create(w http.ResponseWriter, r *http.Request) {
+	var tag Tag
+	if err := request.ParseBody(r, &tag); err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	created, err := h.svc.Create(r.Context(), tag)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusCreated, &response.HTTPResponse{Data: created})
+}
+
+func (h *handler) getAll(w http.ResponseWriter, r *http.Request) {
+	page, err := request.ParsePage(r)
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	tags, err := h.svc.GetAllPaginated(r.Context(), page)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: tags})
+}
+
+func (h *handler) getBySlug(w http.ResponseWriter, r *http.Request) {
+	requested := chi.URLParam(r, "slug")
+
+	tag, err := h.svc.GetBySlug(r.Context(), requested)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	// old slugs are permanently redirected to the current one
+	if tag.Slug != nil && *tag.Slug != requested {
+		location := *r.URL
+		location.Path = strings.TrimSuffix(r.URL.Path, requested) + *tag.Slug
+		http.Redirect(w, r, location.String(), http.StatusMovedPermanently)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: tag})
+}
+
+func (h *handler) getByID(w http.ResponseWriter, r *http.Request) {
+	id, err := request.UUIDParam(r, "id")
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	tag, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: tag})
+}
+
+func (h *handler) update(w http.ResponseWriter, r *http.Request) {
+	id, err := request.UUIDParam(r, "id")
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	var body Tag
+	if err := request.ParseBody(r, &body); err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if body.Name == nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, ErrInvalidTag)
+		return
+	}
+
+	tag, err := h.svc.UpdateByID(r.Context(), id, *body.Name)
+	if err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusOK, &response.HTTPResponse{Data: tag})
+}
+
+func (h *handler) delete(w http.ResponseWriter, r *http.Request) {
+	id, err := request.UUIDParam(r, "id")
+	if err != nil {
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.svc.DeleteByID(r.Context(), id); err != nil {
+		h.withServiceError(w, r, err)
+		return
+	}
+
+	response.WithJSON(w, r, http.StatusNoContent, nil)
+}
+
+// withServiceError translates the errors returned by the Service into HTTP responses
+func (h *handler) withServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		response.WithJSONError(w, r, http.StatusNotFound, err)
+	case errors.Is(err, ErrInvalidTag):
+		response.WithJSONError(w, r, http.StatusBadRequest, err)
+	default:
+		zaplog.FromContext(r.Context()).Error("Unable to handle tag request", zap.String("path", r.URL.Path), zap.Error(err))
+		response.WithJSONError(w, r, http.StatusInternalServerError, nil)
+	}
+}
@@ -1,10 +1,33 @@
 package tag
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/slug"
+)
 
 type Tag struct {
-	ID        *uuid.UUID
-	Name      *string
-	CreatedAt *string
-	UpdatedAt *string
+	ID        *uuid.UUID `db:"id" json:"id,omitempty"`
+	Name      *string    `db:"name" json:"name,omitempty"`
+	Slug      *string    `db:"slug" json:"slug,omitempty"`
+	CreatedAt *time.Time `db:"created_at" json:"createdAt,omitempty"`
+	UpdatedAt *time.Time `db:"updated_at" json:"updatedAt,omitempty"`
+}
+
+// SlugHistory keeps a slug previously used by a tag, so old URLs can be redirected
+type SlugHistory struct {
+	Slug      *string    `db:"slug" json:"slug,omitempty"`
+	TagID     *uuid.UUID `db:"tag_id" json:"tagId,omitempty"`
+	CreatedAt *time.Time `db:"created_at" json:"createdAt,omitempty"`
+}
+
+// newSlugHistory creates the history entry of a slug no longer used by the tag
+func newSlugHistory(oldSlug string, tagID uuid.UUID, createdAt time.Time) slug.Entry {
+	return &SlugHistory{Slug: &oldSlug, TagID: &tagID, CreatedAt: &createdAt}
+}
+
+// RecordID returns the id of the tag that used the slug
+func (h *SlugHistory) RecordID() *uuid.UUID {
+	return h.TagID
 }
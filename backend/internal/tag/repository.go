@@ -1 +1,31 @@
 package tag
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database/postgres"
+)
+
+type Repository interface {
+	postgres.Pg
+}
+
+type repo struct {
+	postgres.Pg
+}
+
+func NewRepository(session *sqlx.DB) Repository {
+	return &repo{Pg: postgres.NewRepository("tag", session)}
+}
+
+// SlugHistoryRepository stores the slugs previously used by the tags
+type SlugHistoryRepository interface {
+	postgres.Pg
+}
+
+type slugHistoryRepo struct {
+	postgres.Pg
+}
+
+func NewSlugHistoryRepository(session *sqlx.DB) SlugHistoryRepository {
+	return &slugHistoryRepo{Pg: postgres.NewRepository("tag_slug_history", session)}
+}
@@ -1 +1,191 @@
 package tag
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database/postgres"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/logger/zaplog"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/slug"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/tracing"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrNotFound is returned when the tag doesn't exist
+	ErrNotFound = errors.New("tag not found")
+
+	// ErrInvalidTag is returned when required tag data is missing
+	ErrInvalidTag = errors.New("tag name is required")
+)
+
+type Service interface {
+	Create(ctx context.Context, tag Tag) (*Tag, error)
+	GetAllPaginated(ctx context.Context, page database.Page) (*[]Tag, error)
+	GetByID(ctx context.Context, ID uuid.UUID) (*Tag, error)
+	GetBySlug(ctx context.Context, slug string) (*Tag, error)
+	UpdateByID(ctx context.Context, ID uuid.UUID, name string) (*Tag, error)
+	DeleteByID(ctx context.Context, ID uuid.UUID) error
+}
+
+type svc struct {
+	logger zaplog.Logger
+	repo   Repository
+	slugs  *slug.History
+}
+
+func NewService(logger zaplog.Logger, repo Repository, slugRepo SlugHistoryRepository) Service {
+	return &svc{
+		logger: logger,
+		repo:   repo,
+		slugs:  slug.NewHistory(repo, slugRepo, "tag_id", newSlugHistory),
+	}
+}
+
+func (s *svc) Create(ctx context.Context, tag Tag) (*Tag, error) {
+	ctx, span := tracing.Start(ctx, "tag.Service.Create")
+	defer span.End()
+
+	if tag.Name == nil || *tag.Name == "" {
+		return nil, ErrInvalidTag
+	}
+
+	id := uuid.New()
+	now := time.Now().UTC()
+	tag.ID = &id
+	tag.CreatedAt = &now
+	tag.UpdatedAt = &now
+
+	err := s.inTx(ctx, func(tx *postgres.Tx) error {
+		tagSlug, err := s.uniqueSlug(ctx, tx, tag)
+		if err != nil {
+			return err
+		}
+		tag.Slug = &tagSlug
+
+		return s.repo.WithTx(tx).Insert(ctx, tag, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+func (s *svc) GetAllPaginated(ctx context.Context, page database.Page) (*[]Tag, error) {
+	ctx, span := tracing.Start(ctx, "tag.Service.GetAllPaginated")
+	defer span.End()
+
+	page.OrderBy = []string{"name"}
+
+	tags := []Tag{}
+	err := s.repo.FindPage(ctx, nil, page, &tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tags, nil
+}
+
+func (s *svc) GetByID(ctx context.Context, ID uuid.UUID) (*Tag, error) {
+	ctx, span := tracing.Start(ctx, "tag.Service.GetByID")
+	defer span.End()
+
+	var tag Tag
+	err := s.repo.FindOne(ctx, sq.Eq{"id": ID}, &tag)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+// UpdateByID renames the tag, keeping its previous slug in the history
+func (s *svc) UpdateByID(ctx context.Context, ID uuid.UUID, name string) (*Tag, error) {
+	ctx, span := tracing.Start(ctx, "tag.Service.UpdateByID")
+	defer span.End()
+
+	if name == "" {
+		return nil, ErrInvalidTag
+	}
+
+	var updated Tag
+	err := s.inTx(ctx, func(tx *postgres.Tx) error {
+		tagTx := s.repo.WithTx(tx)
+
+		var current Tag
+		err := tagTx.FindOne(ctx, sq.Eq{"id": ID}, &current)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		current.Name = &name
+		current.UpdatedAt = &now
+		if err := s.changeSlug(ctx, tx, &current); err != nil {
+			return err
+		}
+
+		_, err = tagTx.Update(ctx, map[string]interface{}{
+			"name":       current.Name,
+			"slug":       current.Slug,
+			"updated_at": current.UpdatedAt,
+		}, sq.Eq{"id": ID})
+		if err != nil {
+			return err
+		}
+
+		updated = current
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+func (s *svc) DeleteByID(ctx context.Context, ID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "tag.Service.DeleteByID")
+	defer span.End()
+
+	// slug history and post associations are removed by the foreign key cascade
+	deleted, err := s.repo.Remove(ctx, sq.Eq{"id": ID}, true)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// inTx runs fn inside a transaction, committing it when fn succeeds and rolling it back otherwise
+func (s *svc) inTx(ctx context.Context, fn func(tx *postgres.Tx) error) error {
+	tx, err := s.repo.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	err = fn(tx)
+	if err != nil {
+		if errRollback := tx.Rollback(); errRollback != nil {
+			zaplog.FromContext(ctx).Error("Unable to rollback tag transaction", zap.Error(errRollback))
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
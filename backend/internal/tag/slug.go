@@ -0,0 +1,54 @@
+package tag
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/database/postgres"
+	"github.com/thiagoretondar/golang-blog-example/backend/go-lego/tracing"
+)
+
+// GetBySlug returns the tag that uses the slug now or used it in the past. When the returned
+// tag has a different slug, the requested one is outdated
+func (s *svc) GetBySlug(ctx context.Context, tagSlug string) (*Tag, error) {
+	ctx, span := tracing.Start(ctx, "tag.Service.GetBySlug")
+	defer span.End()
+
+	var tag Tag
+	err := s.repo.FindOne(ctx, sq.Eq{"slug": tagSlug}, &tag)
+	if err == nil {
+		return &tag, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	// the tag may have been renamed
+	tagID, err := s.slugs.Resolve(ctx, tagSlug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, tagID)
+}
+
+// changeSlug generates a new slug for the renamed tag and keeps the previous one in the history
+func (s *svc) changeSlug(ctx context.Context, tx *postgres.Tx, tag *Tag) error {
+	newSlug, err := s.slugs.Change(ctx, tx, *tag.Name, *tag.ID, tag.Slug)
+	if err != nil {
+		return err
+	}
+
+	tag.Slug = &newSlug
+	return nil
+}
+
+// uniqueSlug derives a slug from the tag name that isn't used, now or in the past, by other tags
+func (s *svc) uniqueSlug(ctx context.Context, tx *postgres.Tx, tag Tag) (string, error) {
+	return s.slugs.Unique(ctx, tx, *tag.Name, *tag.ID)
+}
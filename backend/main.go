@@ -2,7 +2,13 @@ package main
 
 import (
 	"context"
+	"github.com/thiagoretondar/golang-blog-example/backend/cmd/apikey"
+	"github.com/thiagoretondar/golang-blog-example/backend/cmd/config"
 	"github.com/thiagoretondar/golang-blog-example/backend/cmd/httpserver"
+	"github.com/thiagoretondar/golang-blog-example/backend/cmd/sitemap"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/spf13/cobra"
 )
@@ -13,14 +19,46 @@ var rootCMD = &cobra.Command{
 }
 
 func main() {
-	ctx, cancel := context.WithCancel(context.Background())
+	// the context is canceled by Ctrl+C (os.Interrupt) or the SIGTERM sent by Kubernetes, so the commands stop
+	// gracefully
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
+	// flags for the configuration of every command
+	rootCMD.PersistentFlags().String("config-path", "", "Directory with the env.<environment>.yaml files (default BLOG_CONFIG_PATH or configs/environment)")
+	rootCMD.PersistentFlags().StringArray("set", nil, "Override a configuration value, e.g. --set Server.HTTP.ListenAddr=:9090 (repeatable)")
+
 	// flags for "httpserver" command
 	httpserver.HTTPServerCMD.Flags().String("environment", "", "Define environment")
 	httpserver.HTTPServerCMD.MarkFlagRequired("environment")
 	rootCMD.AddCommand(httpserver.HTTPServerCMD)
 
+	// flags for "sitemap" command
+	sitemap.SitemapCMD.Flags().String("environment", "", "Define environment")
+	sitemap.SitemapCMD.MarkFlagRequired("environment")
+	sitemap.SitemapCMD.Flags().String("output", "public", "Directory where the sitemap files are written")
+	rootCMD.AddCommand(sitemap.SitemapCMD)
+
+	// flags for "apikey" commands
+	apikey.APIKeyCMD.PersistentFlags().String("environment", "", "Define environment")
+	apikey.APIKeyCMD.MarkPersistentFlagRequired("environment")
+	apikey.CreateCMD.Flags().String("name", "", "Name describing who uses the key")
+	apikey.CreateCMD.MarkFlagRequired("name")
+	apikey.CreateCMD.Flags().StringSlice("permissions", nil, "Permissions granted to the key (e.g. posts:create,posts:edit:own)")
+	apikey.CreateCMD.MarkFlagRequired("permissions")
+	apikey.CreateCMD.Flags().Duration("expires-in", 0, "Time until the key expires, it never expires when empty")
+	apikey.CreateCMD.Flags().String("author-id", "", "Author to whom the posts created with the key are attributed")
+	apikey.APIKeyCMD.AddCommand(apikey.CreateCMD, apikey.RevokeCMD, apikey.ListCMD)
+	rootCMD.AddCommand(apikey.APIKeyCMD)
+
+	// flags for "config" commands
+	config.ConfigCMD.PersistentFlags().String("environment", "", "Define environment")
+	config.ValidateCMD.Flags().Bool("all", false, "Validate every env.<environment>.yaml in the configuration path")
+	config.ValidateCMD.Flags().Bool("structure", false,
+		"Tolerate references to missing environment variables and files, leaving their fields unchecked")
+	config.ConfigCMD.AddCommand(config.PrintCMD, config.ValidateCMD)
+	rootCMD.AddCommand(config.ConfigCMD)
+
 	err := rootCMD.ExecuteContext(ctx)
 	if err != nil {
 		panic(err)
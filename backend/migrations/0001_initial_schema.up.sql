@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS author (
+    id         uuid PRIMARY KEY,
+    first_name text NOT NULL,
+    last_name  text NOT NULL,
+    score      double precision
+);
+
+CREATE TABLE IF NOT EXISTS tag (
+    id         uuid PRIMARY KEY,
+    name       text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS post (
+    id         uuid PRIMARY KEY,
+    title      text NOT NULL,
+    content    text NOT NULL,
+    author_id  uuid REFERENCES author (id),
+    revision   integer NOT NULL DEFAULT 1,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS post_tag (
+    post_id uuid NOT NULL REFERENCES post (id) ON DELETE CASCADE,
+    tag_id  uuid NOT NULL REFERENCES tag (id) ON DELETE CASCADE,
+    PRIMARY KEY (post_id, tag_id)
+);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS post_revision (
+    id         uuid PRIMARY KEY,
+    post_id    uuid NOT NULL REFERENCES post (id) ON DELETE CASCADE,
+    number     integer NOT NULL,
+    title      text NOT NULL,
+    content    text NOT NULL,
+    editor_id  uuid REFERENCES author (id) ON DELETE SET NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (post_id, number)
+);
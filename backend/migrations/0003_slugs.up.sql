@@ -0,0 +1,14 @@
+ALTER TABLE post ADD COLUMN IF NOT EXISTS slug text UNIQUE;
+ALTER TABLE tag ADD COLUMN IF NOT EXISTS slug text UNIQUE;
+
+CREATE TABLE IF NOT EXISTS post_slug_history (
+    slug       text PRIMARY KEY,
+    post_id    uuid NOT NULL REFERENCES post (id) ON DELETE CASCADE,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS tag_slug_history (
+    slug       text PRIMARY KEY,
+    tag_id     uuid NOT NULL REFERENCES tag (id) ON DELETE CASCADE,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
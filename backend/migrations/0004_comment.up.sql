@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS comment (
+    id           uuid PRIMARY KEY,
+    post_id      uuid NOT NULL REFERENCES post (id) ON DELETE CASCADE,
+    parent_id    uuid REFERENCES comment (id) ON DELETE CASCADE,
+    root_id      uuid REFERENCES comment (id) ON DELETE CASCADE,
+    author_name  text NOT NULL,
+    author_email text,
+    content      text NOT NULL,
+    status       text NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'rejected', 'spam')),
+    created_at   timestamptz NOT NULL DEFAULT now(),
+    updated_at   timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS comment_post_status_idx ON comment (post_id, status, created_at);
+CREATE INDEX IF NOT EXISTS comment_root_idx ON comment (root_id);
+CREATE INDEX IF NOT EXISTS comment_status_idx ON comment (status, created_at);
@@ -0,0 +1,4 @@
+ALTER TABLE post ADD COLUMN IF NOT EXISTS published_at timestamptz;
+
+CREATE INDEX IF NOT EXISTS post_published_at_idx ON post (published_at DESC) WHERE published_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS post_tag_tag_idx ON post_tag (tag_id);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS user_account (
+    id            uuid PRIMARY KEY,
+    email         text NOT NULL UNIQUE,
+    password_hash text NOT NULL,
+    author_id     uuid REFERENCES author (id) ON DELETE SET NULL,
+    created_at    timestamptz NOT NULL DEFAULT now(),
+    updated_at    timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_account_author_idx ON user_account (author_id);
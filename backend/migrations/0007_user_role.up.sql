@@ -0,0 +1,5 @@
+-- new users are readers. The first admin must be promoted directly in the database:
+-- UPDATE user_account SET role = 'admin' WHERE email = '<e-mail>';
+ALTER TABLE user_account
+    ADD COLUMN IF NOT EXISTS role text NOT NULL DEFAULT 'reader'
+        CHECK (role IN ('reader', 'author', 'editor', 'admin'));
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS api_key (
+    id           uuid PRIMARY KEY,
+    name         text NOT NULL,
+    prefix       text NOT NULL,
+    key_hash     text NOT NULL UNIQUE,
+    permissions  text[] NOT NULL,
+    author_id    uuid REFERENCES author (id) ON DELETE CASCADE,
+    created_by   uuid REFERENCES user_account (id) ON DELETE SET NULL,
+    expires_at   timestamptz,
+    last_used_at timestamptz,
+    revoked_at   timestamptz,
+    created_at   timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS api_key_created_idx ON api_key (created_at);
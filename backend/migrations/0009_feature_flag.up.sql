@@ -0,0 +1,10 @@
+-- overrides of the feature flags defined in the configuration files, read when
+-- FeatureFlags.DatabaseOverrides is enabled. A row replaces the whole flag with the same name
+CREATE TABLE IF NOT EXISTS feature_flag (
+    name        text PRIMARY KEY,
+    description text,
+    enabled     boolean NOT NULL DEFAULT false,
+    percentage  integer CHECK (percentage BETWEEN 0 AND 100),
+    allowlist   text[] NOT NULL DEFAULT '{}',
+    updated_at  timestamptz NOT NULL DEFAULT now()
+);
@@ -0,0 +1,41 @@
+-- rows created before the slugs existed get a slug made of the ASCII letters and digits of their title or name,
+-- falling back to the beginning of their id like the application does. Like slug.Unique, a taken slug gets the
+-- "-2", "-3", ... suffix, checking the current and the old slugs, so a base that already ends in "-N" never
+-- collides with a suffixed one. Rows are filled oldest first, one at a time, so they see each other's slugs
+DO $$
+DECLARE
+    target    record;
+    base      text;
+    candidate text;
+    attempt   integer;
+BEGIN
+    FOR target IN SELECT id, title FROM post WHERE slug IS NULL ORDER BY created_at, id LOOP
+        base := coalesce(nullif(trim(BOTH '-' FROM left(trim(BOTH '-' FROM
+            regexp_replace(lower(target.title), '[^a-z0-9]+', '-', 'g')), 80)), ''), left(target.id::text, 8));
+        candidate := base;
+        attempt := 1;
+        WHILE EXISTS (SELECT 1 FROM post WHERE slug = candidate)
+            OR EXISTS (SELECT 1 FROM post_slug_history WHERE slug = candidate) LOOP
+            attempt := attempt + 1;
+            candidate := base || '-' || attempt;
+        END LOOP;
+        UPDATE post SET slug = candidate WHERE id = target.id;
+    END LOOP;
+
+    FOR target IN SELECT id, name FROM tag WHERE slug IS NULL ORDER BY created_at, id LOOP
+        base := coalesce(nullif(trim(BOTH '-' FROM left(trim(BOTH '-' FROM
+            regexp_replace(lower(target.name), '[^a-z0-9]+', '-', 'g')), 80)), ''), left(target.id::text, 8));
+        candidate := base;
+        attempt := 1;
+        WHILE EXISTS (SELECT 1 FROM tag WHERE slug = candidate)
+            OR EXISTS (SELECT 1 FROM tag_slug_history WHERE slug = candidate) LOOP
+            attempt := attempt + 1;
+            candidate := base || '-' || attempt;
+        END LOOP;
+        UPDATE tag SET slug = candidate WHERE id = target.id;
+    END LOOP;
+END
+$$;
+
+ALTER TABLE post ALTER COLUMN slug SET NOT NULL;
+ALTER TABLE tag ALTER COLUMN slug SET NOT NULL;
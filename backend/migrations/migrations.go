@@ -0,0 +1,28 @@
+// Package migrations contains the SQL migrations of the database. They are applied in order with
+// golang-migrate (https://github.com/golang-migrate/migrate), which records the version of the last one
+// applied in the schema_migrations table
+package migrations
+
+import (
+	"embed"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.up.sql
+var files embed.FS
+
+// Version returns the version of the last migration, the one the schema must be at for the server to run
+func Version() int64 {
+	entries, _ := files.ReadDir(".")
+
+	var latest int64
+	for _, entry := range entries {
+		prefix, _, _ := strings.Cut(entry.Name(), "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err == nil && version > latest {
+			latest = version
+		}
+	}
+	return latest
+}
@@ -0,0 +1,15 @@
+package migrations
+
+import "testing"
+
+func TestVersion(t *testing.T) {
+	entries, err := files.ReadDir(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// migrations are numbered from 1 without gaps, so the last one is also their quantity
+	if got := Version(); got != int64(len(entries)) {
+		t.Errorf("Version() = %d, want %d", got, len(entries))
+	}
+}